@@ -1,25 +1,33 @@
 package main
 
 import (
+	"github.com/bigusef/texorbit/internal/apikey"
 	"github.com/bigusef/texorbit/internal/city"
 	"github.com/bigusef/texorbit/internal/database"
+	"github.com/bigusef/texorbit/internal/translation"
 	"github.com/bigusef/texorbit/internal/user"
 	"github.com/bigusef/texorbit/pkg/config"
+	"github.com/bigusef/texorbit/pkg/i18n"
+	apimiddleware "github.com/bigusef/texorbit/pkg/middleware"
+	"github.com/bigusef/texorbit/pkg/storage"
 	"github.com/bigusef/texorbit/pkg/util"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/cors"
 	"github.com/go-playground/validator/v10"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"net/http"
 )
 
-func initHandler(conf *config.Setting, queries *database.Queries, validate *validator.Validate) http.Handler {
+func initHandler(conf *config.Setting, pool *pgxpool.Pool, queries *database.Queries, validate *validator.Validate) http.Handler {
 	router := chi.NewRouter()
 
+	router.Use(apimiddleware.RealIP(conf.TrustedProxies))
 	router.Use(middleware.Logger)
 	router.Use(middleware.Recoverer)
+	router.Use(i18n.Middleware)
 
-	router.Use(middleware.AllowContentType("application/json"))
+	router.Use(apimiddleware.AllowContentType("application/json", "multipart/form-data"))
 	router.Use(cors.Handler(cors.Options{
 		AllowedOrigins: []string{"https://*", "http://*"},
 		AllowedMethods: []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
@@ -29,16 +37,37 @@ func initHandler(conf *config.Setting, queries *database.Queries, validate *vali
 		MaxAge:           300, // Maximum value not ignored by any of major browsers
 	}))
 
+	// unknown routes and methods answer with problem details like the handlers
+	router.NotFound(func(w http.ResponseWriter, r *http.Request) {
+		util.ErrorResponseWriter(w, r, util.NotFound("route not found"))
+	})
+	router.MethodNotAllowed(func(w http.ResponseWriter, r *http.Request) {
+		util.ErrorResponseWriter(w, r, util.NewError(http.StatusMethodNotAllowed, util.CodeMethodNotAllowed, "method not allowed"))
+	})
+
 	// health check API, to make sure routers working as expected
 	router.Get("/healthz", func(w http.ResponseWriter, r *http.Request) {
 		util.JsonResponseWriter(w, http.StatusOK, map[string]string{"result": "OK - healthy"})
 	})
 
+	// public keys of access tokens, so other services can verify our tokens
+	router.Get("/.well-known/jwks.json", func(w http.ResponseWriter, r *http.Request) {
+		util.JsonResponseWriter(w, http.StatusOK, conf.AccessAuth.PublicKeys())
+	})
+
 	// mount all internal routers
 	router.Mount("/auth", user.AuthRouter(conf, queries, validate))
-	router.Mount("/staff", user.StaffRouter(conf, queries, validate))
-	router.Mount("/user", user.CustomerRouter(conf, queries, validate))
-	router.Mount("/city", city.NewRouter(conf, queries, validate))
+	router.Mount("/staff", user.StaffRouter(conf, pool, queries, validate))
+	router.Mount("/user", user.CustomerRouter(conf, pool, queries, validate))
+	router.Mount("/city", city.NewRouter(conf, pool, queries, validate))
+	router.Mount("/api-keys", apikey.NewRouter(conf, queries, validate))
+	router.Mount("/translations", translation.NewRouter(conf, queries, validate))
+	router.Mount("/avatars", user.AvatarRouter(conf, queries))
+
+	// signed download links of locally stored files
+	if local, ok := conf.BlobStore.(*storage.LocalStore); ok {
+		router.Mount("/blobs", http.StripPrefix("/blobs", local.Handler()))
+	}
 
 	return router
 }
@@ -4,12 +4,18 @@ import (
 	"context"
 	"fmt"
 	"github.com/bigusef/texorbit/internal/database"
+	"github.com/bigusef/texorbit/internal/user"
 	"github.com/bigusef/texorbit/pkg/config"
+	"github.com/bigusef/texorbit/pkg/i18n"
+	"github.com/bigusef/texorbit/pkg/middleware"
+	"github.com/bigusef/texorbit/pkg/util"
 	"github.com/go-playground/validator/v10"
 	"log"
 	"net/http"
 	"reflect"
 	"strings"
+	"time"
+	"unicode"
 )
 
 func main() {
@@ -22,13 +28,20 @@ func main() {
 	}
 
 	validate := initValidate()
+	middleware.RegisterCursorSecret(setting.CursorSecret)
+	i18n.Configure(setting.Locales, setting.LocaleFallbacks)
 
 	// Database Setup
 	conn := config.NewConnectionPool(ctx, setting.ConnString)
 	defer conn.Close()
 	queries := database.New(conn)
 
-	handler := initHandler(setting, queries, validate)
+	// anonymize accounts after their deletion grace period
+	go user.AccountDeletionJob(ctx, queries, setting.BlobStore, time.Hour)
+	// reactivate accounts when their scheduled suspension ends
+	go user.SuspensionJob(ctx, queries, time.Minute)
+
+	handler := initHandler(setting, conn, queries, validate)
 
 	// start application server
 	log.Println("[Restfull server] Start Store application")
@@ -51,5 +64,48 @@ func initValidate() *validator.Validate {
 		return name
 	})
 
+	// egyptian mobile number in any local or international format, stored normalized to E.164
+	err := validate.RegisterValidation("phone_number", func(fl validator.FieldLevel) bool {
+		_, err := util.NormalizePhone(fl.Field().String())
+		return err == nil
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// locale selected by the configured LOCALES in any case (ex: fr-ca)
+	err = validate.RegisterValidation("supported_locale", func(fl validator.FieldLevel) bool {
+		_, ok := i18n.Parameter(fl.Field().String())
+		return ok
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// arabic names (ex: names[ar]) allow arabic letters with digits, spaces and common punctuation only
+	err = validate.RegisterValidation("arabic_text", func(fl validator.FieldLevel) bool {
+		return isArabicText(fl.Field().String())
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// validation messages in the request language
+	util.RegisterTranslator(initTranslator(validate))
+
 	return validate
 }
+
+func isArabicText(text string) bool {
+	hasLetter := false
+	for _, c := range text {
+		switch {
+		case unicode.Is(unicode.Arabic, c):
+			hasLetter = hasLetter || unicode.IsLetter(c)
+		case unicode.IsDigit(c), unicode.IsSpace(c), strings.ContainsRune("-.()'/", c):
+		default:
+			return false
+		}
+	}
+	return hasLetter
+}
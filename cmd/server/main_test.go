@@ -0,0 +1,100 @@
+package main
+
+import (
+	"encoding/json"
+	"github.com/bigusef/texorbit/pkg/i18n"
+	"github.com/bigusef/texorbit/pkg/util"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestIsArabicText(t *testing.T) {
+	tests := []struct {
+		text string
+		want bool
+	}{
+		{"القاهرة", true},
+		{"القاهرة الجديدة", true},
+		{"15 مايو", true},
+		{"مدينة 6 أكتوبر", true},
+		{"٦ أكتوبر", true},
+		{"شبرا - الخيمة", true},
+		{"مصر (القاهرة)", true},
+		{"سيدي عبد الرحمن/مطروح", true},
+		{"New Cairo", false},
+		{"القاهرة Cairo", false},
+		{"القاهرة!", false},
+		{"15", false},
+		{"", false},
+		{"   ", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if got := isArabicText(tt.text); got != tt.want {
+				t.Errorf("isArabicText(%q) = %v, want %v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+// customValidated input using every validator tag registered by initValidate
+type customValidated struct {
+	PhoneNumber string `json:"phone_number" validate:"omitempty,phone_number"`
+	Name        string `json:"name" validate:"omitempty,arabic_text"`
+	Locale      string `json:"locale" validate:"omitempty,supported_locale"`
+}
+
+func TestInitValidate(t *testing.T) {
+	validate := initValidate()
+	t.Cleanup(func() { util.RegisterTranslator(nil) })
+
+	valid := customValidated{PhoneNumber: "+20 101 234 5678", Name: "القاهرة الجديدة", Locale: "AR"}
+	if err := validate.Struct(valid); err != nil {
+		t.Fatalf("validate(%+v) = %v", valid, err)
+	}
+
+	invalid := customValidated{PhoneNumber: "0201234567", Name: "New Cairo", Locale: "de"}
+	tests := []struct {
+		locale string
+		want   map[string]string
+	}{
+		{i18n.English, map[string]string{
+			"phone_number": "Phone number must be an egyptian mobile number like 01012345678",
+			"name":         "Name must be written in arabic letters",
+			"locale":       "Language is not one of the supported languages",
+		}},
+		{i18n.Arabic, map[string]string{
+			"phone_number": "رقم الموبايل يجب أن يكون رقم موبايل مصري مثل 01012345678",
+			"name":         "الاسم يجب أن يكون مكتوباً بحروف عربية",
+			"locale":       "اللغة ليست من اللغات المدعومة",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.locale, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/", nil)
+			r = r.WithContext(i18n.WithLocale(r.Context(), tt.locale))
+			w := httptest.NewRecorder()
+			util.ErrorResponseWriter(w, r, util.ValidationFailed(validate.Struct(invalid)))
+
+			var problem struct {
+				Errors   map[string]string `json:"errors"`
+				Messages map[string]string `json:"messages"`
+			}
+			if err := json.NewDecoder(w.Body).Decode(&problem); err != nil {
+				t.Fatal(err)
+			}
+
+			wantErrors := map[string]string{"phone_number": "phone_number", "name": "arabic_text", "locale": "supported_locale"}
+			if !reflect.DeepEqual(problem.Errors, wantErrors) {
+				t.Errorf("errors = %v, want %v", problem.Errors, wantErrors)
+			}
+			if !reflect.DeepEqual(problem.Messages, tt.want) {
+				t.Errorf("messages = %v, want %v", problem.Messages, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"github.com/go-playground/locales/ar"
+	"github.com/go-playground/locales/en"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	arTranslations "github.com/go-playground/validator/v10/translations/ar"
+	enTranslations "github.com/go-playground/validator/v10/translations/en"
+	"log"
+)
+
+// validatorMessages messages of our own validator tags and the built-in tags missing from the default translations
+var validatorMessages = map[string]map[string]string{
+	"en": {
+		"phone_number":       "{0} must be an egyptian mobile number like 01012345678",
+		"arabic_text":        "{0} must be written in arabic letters",
+		"bcp47_language_tag": "{0} must be a language code like en, ar or fr",
+		"supported_locale":   "{0} is not one of the supported languages",
+	},
+	"ar": {
+		"phone_number":       "{0} يجب أن يكون رقم موبايل مصري مثل 01012345678",
+		"arabic_text":        "{0} يجب أن يكون مكتوباً بحروف عربية",
+		"bcp47_language_tag": "{0} يجب أن يكون رمز لغة مثل en أو ar أو fr",
+		"supported_locale":   "{0} ليست من اللغات المدعومة",
+		"required_with":      "{0} مطلوب",
+		"required_without":   "{0} مطلوب",
+		"excluded_unless":    "{0} غير مسموح به هنا",
+	},
+}
+
+// ruleMessages messages of the field errors sent by the handlers without the validator
+var ruleMessages = map[string]map[string]string{
+	"en": {
+		"required":           "{0} is required",
+		"invalid":            "{0} is invalid",
+		"number":             "{0} must be a number",
+		"datetime":           "{0} must be a date and time like 2024-01-31T10:00:00Z",
+		"oneof":              "{0} has unknown value",
+		"latitude":           "{0} must be a latitude between -90 and 90",
+		"longitude":          "{0} must be a longitude between -180 and 180",
+		"future":             "{0} must be in the future",
+		"exists":             "{0} does not exist",
+		"unique":             "{0} is already used",
+		"geojson":            "{0} must be a GeoJSON Polygon or MultiPolygon",
+		"feature":            "{0} must be a GeoJSON Feature",
+		"active_city":        "{0} must be one of the cities we deliver to",
+		"city_zone":          "{0} is not a delivery zone of this city",
+		"otp_expired":        "{0} expired or was not requested, request a new code",
+		"unknown_permission": "{0} has unknown permission",
+		"unknown_role":       "{0} has unknown role",
+		"staff_only":         "roles can be assigned to staff members only",
+		"not_granted":        "{0} is not one of your permissions",
+		"arabic_text":        "{0} must be written in arabic letters",
+		"bcp47_language_tag": "{0} must be a language code like en, ar or fr",
+	},
+	"ar": {
+		"required":           "{0} مطلوب",
+		"invalid":            "{0} غير صحيح",
+		"number":             "{0} يجب أن يكون رقماً",
+		"datetime":           "{0} يجب أن يكون تاريخاً ووقتاً مثل 2024-01-31T10:00:00Z",
+		"oneof":              "{0} يحتوي على قيمة غير معروفة",
+		"latitude":           "{0} يجب أن يكون خط عرض بين -90 و 90",
+		"longitude":          "{0} يجب أن يكون خط طول بين -180 و 180",
+		"future":             "{0} يجب أن يكون في المستقبل",
+		"exists":             "{0} غير موجود",
+		"unique":             "{0} مستخدم بالفعل",
+		"geojson":            "{0} يجب أن يكون مضلعاً بصيغة GeoJSON",
+		"feature":            "{0} يجب أن يكون عنصراً بصيغة GeoJSON",
+		"active_city":        "{0} يجب أن تكون من المدن التي نوصل إليها",
+		"city_zone":          "{0} ليست منطقة توصيل في هذه المدينة",
+		"otp_expired":        "{0} انتهت صلاحيته أو لم يتم طلبه، اطلب رمزاً جديداً",
+		"unknown_permission": "{0} يحتوي على صلاحية غير معروفة",
+		"unknown_role":       "{0} يحتوي على دور غير معروف",
+		"staff_only":         "يمكن تعيين الأدوار لفريق العمل فقط",
+		"not_granted":        "{0} ليست من صلاحياتك",
+		"arabic_text":        "{0} يجب أن يكون مكتوباً بحروف عربية",
+		"bcp47_language_tag": "{0} يجب أن يكون رمز لغة مثل en أو ar أو fr",
+	},
+}
+
+// fieldLabels display names replacing the json field names in the messages
+var fieldLabels = map[string]map[string]string{
+	"en": {
+		"name":           "Name",
+		"names":          "Names",
+		"names[en]":      "English name",
+		"names[ar]":      "Arabic name",
+		"locale":         "Language",
+		"translations":   "Translations",
+		"entity":         "Entity",
+		"entity_id":      "Entity id",
+		"field":          "Field",
+		"value":          "Value",
+		"email":          "Email",
+		"phone_number":   "Phone number",
+		"code":           "Code",
+		"recovery_code":  "Recovery code",
+		"status":         "Status",
+		"reason":         "Reason",
+		"until":          "Suspension end",
+		"label":          "Label",
+		"city_id":        "City",
+		"street":         "Street",
+		"building":       "Building",
+		"floor":          "Floor",
+		"landmark":       "Landmark",
+		"latitude":       "Latitude",
+		"longitude":      "Longitude",
+		"lat":            "Latitude",
+		"lng":            "Longitude",
+		"governorate":    "Governorate",
+		"governorate_id": "Governorate",
+		"is_active":      "Active",
+		"boundary":       "Boundary",
+		"area":           "Area",
+		"geometry":       "Area",
+		"fee":            "Delivery fee",
+		"min_order":      "Minimum order",
+		"eta_minutes":    "Delivery time",
+		"features":       "Features",
+		"avatar":         "Avatar",
+		"roles":          "Roles",
+		"permissions":    "Permissions",
+		"scopes":         "Scopes",
+		"expires_at":     "Expiry time",
+	},
+	"ar": {
+		"name":           "الاسم",
+		"names":          "الأسماء",
+		"names[en]":      "الاسم بالإنجليزية",
+		"names[ar]":      "الاسم بالعربية",
+		"locale":         "اللغة",
+		"translations":   "الترجمات",
+		"entity":         "الكيان",
+		"entity_id":      "رقم الكيان",
+		"field":          "الحقل",
+		"value":          "القيمة",
+		"email":          "البريد الإلكتروني",
+		"phone_number":   "رقم الموبايل",
+		"code":           "الرمز",
+		"recovery_code":  "رمز الاسترداد",
+		"status":         "الحالة",
+		"reason":         "السبب",
+		"until":          "نهاية الإيقاف",
+		"label":          "الاسم المميز",
+		"city_id":        "المدينة",
+		"street":         "الشارع",
+		"building":       "المبنى",
+		"floor":          "الدور",
+		"landmark":       "علامة مميزة",
+		"latitude":       "خط العرض",
+		"longitude":      "خط الطول",
+		"lat":            "خط العرض",
+		"lng":            "خط الطول",
+		"governorate":    "المحافظة",
+		"governorate_id": "المحافظة",
+		"is_active":      "التفعيل",
+		"boundary":       "الحدود",
+		"area":           "النطاق",
+		"geometry":       "النطاق",
+		"fee":            "رسوم التوصيل",
+		"min_order":      "الحد الأدنى للطلب",
+		"eta_minutes":    "مدة التوصيل",
+		"features":       "العناصر",
+		"avatar":         "الصورة الشخصية",
+		"roles":          "الأدوار",
+		"permissions":    "الصلاحيات",
+		"scopes":         "الصلاحيات",
+		"expires_at":     "وقت الانتهاء",
+	},
+}
+
+// initTranslator register arabic and english validation messages, english is the fallback language
+func initTranslator(validate *validator.Validate) *ut.UniversalTranslator {
+	uni := ut.New(en.New(), en.New(), ar.New())
+
+	enTrans, _ := uni.GetTranslator("en")
+	if err := enTranslations.RegisterDefaultTranslations(validate, enTrans); err != nil {
+		log.Fatal(err)
+	}
+	arTrans, _ := uni.GetTranslator("ar")
+	if err := arTranslations.RegisterDefaultTranslations(validate, arTrans); err != nil {
+		log.Fatal(err)
+	}
+
+	for lang, trans := range map[string]ut.Translator{"en": enTrans, "ar": arTrans} {
+		for tag, text := range validatorMessages[lang] {
+			err := validate.RegisterTranslation(tag, trans, func(t ut.Translator) error {
+				return t.Add(tag, text, true)
+			}, func(t ut.Translator, fe validator.FieldError) string {
+				message, _ := t.T(fe.Tag(), fe.Field())
+				return message
+			})
+			if err != nil {
+				log.Fatal(err)
+			}
+		}
+
+		for tag, text := range ruleMessages[lang] {
+			if err := trans.Add("rule."+tag, text, false); err != nil {
+				log.Fatal(err)
+			}
+		}
+		for field, label := range fieldLabels[lang] {
+			if err := trans.Add("field."+field, label, false); err != nil {
+				log.Fatal(err)
+			}
+		}
+	}
+
+	return uni
+}
@@ -0,0 +1,76 @@
+package apikey
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	db "github.com/bigusef/texorbit/internal/database"
+	"github.com/bigusef/texorbit/pkg/middleware"
+	"github.com/bigusef/texorbit/pkg/oidc"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// keys look like "txk_<prefix>_<secret>", the prefix identify the key and only the secret hash is stored
+const keyPrefix = "txk_"
+
+var errInvalidKey = errors.New("invalid api key")
+
+// generate return new raw key with its prefix and secret hash
+func generate() (key, prefix, secretHash string, err error) {
+	buf := make([]byte, 5)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", "", err
+	}
+	prefix = strings.ToLower(base32.StdEncoding.EncodeToString(buf))
+
+	secret, err := oidc.RandomString(32)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	return keyPrefix + prefix + "_" + secret, prefix, hashSecret(secret), nil
+}
+
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// Lookup verify raw keys against the stored keys, revoked and expired keys are rejected
+func Lookup(queries *db.Queries) middleware.APIKeyLookup {
+	return func(ctx context.Context, key string) (*middleware.APIKey, error) {
+		prefix, secret, ok := strings.Cut(strings.TrimPrefix(key, keyPrefix), "_")
+		if !ok || !strings.HasPrefix(key, keyPrefix) {
+			return nil, errInvalidKey
+		}
+
+		stored, err := queries.GetApiKeyByPrefix(ctx, prefix)
+		if err != nil {
+			return nil, errInvalidKey
+		}
+
+		if subtle.ConstantTimeCompare([]byte(stored.SecretHash), []byte(hashSecret(secret))) != 1 {
+			return nil, errInvalidKey
+		}
+
+		if stored.RevokedAt.Valid || (stored.ExpiresAt.Valid && stored.ExpiresAt.Time.Before(time.Now())) {
+			return nil, errInvalidKey
+		}
+
+		if err := queries.TouchApiKey(ctx, stored.ID); err != nil {
+			slog.Error("failed to update api key last use", "key", stored.ID, "error", err)
+		}
+
+		return &middleware.APIKey{
+			ID:        stored.ID.String(),
+			Scopes:    stored.Scopes,
+			ExpiresAt: stored.ExpiresAt.Time,
+		}, nil
+	}
+}
@@ -0,0 +1,155 @@
+package apikey
+
+import (
+	"context"
+	"errors"
+	db "github.com/bigusef/texorbit/internal/database"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeDB answer GetApiKeyByPrefix from the stored keys and record TouchApiKey calls
+type fakeDB struct {
+	keys    map[string]db.ApiKey
+	touched []uuid.UUID
+}
+
+func (f *fakeDB) Exec(_ context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	if !strings.HasPrefix(sql, "-- name: TouchApiKey ") {
+		return pgconn.CommandTag{}, errors.New("unexpected exec")
+	}
+	f.touched = append(f.touched, args[0].(uuid.UUID))
+	return pgconn.NewCommandTag("UPDATE 1"), nil
+}
+
+func (f *fakeDB) Query(context.Context, string, ...interface{}) (pgx.Rows, error) {
+	return nil, errors.New("unexpected query")
+}
+
+func (f *fakeDB) QueryRow(_ context.Context, sql string, args ...interface{}) pgx.Row {
+	key, ok := f.keys[args[0].(string)]
+	if !strings.HasPrefix(sql, "-- name: GetApiKeyByPrefix ") || !ok {
+		return keyRow{err: pgx.ErrNoRows}
+	}
+	return keyRow{key: key}
+}
+
+type keyRow struct {
+	key db.ApiKey
+	err error
+}
+
+func (r keyRow) Scan(dest ...interface{}) error {
+	if r.err != nil {
+		return r.err
+	}
+	*dest[0].(*uuid.UUID) = r.key.ID
+	*dest[2].(*string) = r.key.Prefix
+	*dest[3].(*string) = r.key.SecretHash
+	*dest[4].(*[]string) = r.key.Scopes
+	*dest[7].(*pgtype.Timestamptz) = r.key.ExpiresAt
+	*dest[9].(*pgtype.Timestamptz) = r.key.RevokedAt
+	return nil
+}
+
+func TestGenerate(t *testing.T) {
+	key, prefix, secretHash, err := generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(key, keyPrefix+prefix+"_") {
+		t.Fatalf("key %q does not start with prefix %q", key, prefix)
+	}
+	if secret := strings.TrimPrefix(key, keyPrefix+prefix+"_"); hashSecret(secret) != secretHash {
+		t.Error("stored hash does not match the key secret")
+	}
+	if strings.Contains(secretHash, strings.TrimPrefix(key, keyPrefix+prefix+"_")) {
+		t.Error("stored hash contains the raw secret")
+	}
+
+	other, otherPrefix, _, _ := generate()
+	if other == key || otherPrefix == prefix {
+		t.Error("generate returned the same key twice")
+	}
+}
+
+func TestLookup(t *testing.T) {
+	newKey := func(expiresAt, revokedAt pgtype.Timestamptz) (string, db.ApiKey) {
+		key, prefix, secretHash, err := generate()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return key, db.ApiKey{
+			ID:         uuid.New(),
+			Prefix:     prefix,
+			SecretHash: secretHash,
+			Scopes:     []string{"city:read"},
+			ExpiresAt:  expiresAt,
+			RevokedAt:  revokedAt,
+		}
+	}
+
+	past := pgtype.Timestamptz{Time: time.Now().Add(-time.Minute), Valid: true}
+	future := pgtype.Timestamptz{Time: time.Now().Add(time.Hour), Valid: true}
+
+	validKey, valid := newKey(pgtype.Timestamptz{}, pgtype.Timestamptz{})
+	expiringKey, expiring := newKey(future, pgtype.Timestamptz{})
+	expiredKey, expired := newKey(past, pgtype.Timestamptz{})
+	revokedKey, revoked := newKey(pgtype.Timestamptz{}, past)
+
+	fake := &fakeDB{keys: map[string]db.ApiKey{}}
+	for _, key := range []db.ApiKey{valid, expiring, expired, revoked} {
+		fake.keys[key.Prefix] = key
+	}
+	lookup := Lookup(db.New(fake))
+
+	tests := []struct {
+		name   string
+		key    string
+		wantID uuid.UUID // nil when the key is rejected
+	}{
+		{"valid key", validKey, valid.ID},
+		{"key before its expiry", expiringKey, expiring.ID},
+		{"expired key", expiredKey, uuid.Nil},
+		{"revoked key", revokedKey, uuid.Nil},
+		{"tampered secret", validKey[:len(validKey)-1] + "x", uuid.Nil},
+		{"secret of other key", keyPrefix + valid.Prefix + "_" + strings.TrimPrefix(expiringKey, keyPrefix+expiring.Prefix+"_"), uuid.Nil},
+		{"unknown prefix", keyPrefix + "aaaaaaaa_" + strings.TrimPrefix(validKey, keyPrefix+valid.Prefix+"_"), uuid.Nil},
+		{"missing key prefix", strings.TrimPrefix(validKey, keyPrefix), uuid.Nil},
+		{"missing secret", keyPrefix + valid.Prefix, uuid.Nil},
+		{"empty key", "", uuid.Nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake.touched = nil
+			apiKey, err := lookup(context.Background(), tt.key)
+
+			if tt.wantID == uuid.Nil {
+				if !errors.Is(err, errInvalidKey) {
+					t.Errorf("lookup error = %v, want %v", err, errInvalidKey)
+				}
+				if len(fake.touched) != 0 {
+					t.Error("rejected key marked as used")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("lookup error = %v", err)
+			}
+			if apiKey.ID != tt.wantID.String() || len(apiKey.Scopes) != 1 || apiKey.Scopes[0] != "city:read" {
+				t.Errorf("lookup = %+v, want key %s with its scopes", apiKey, tt.wantID)
+			}
+			if len(fake.touched) != 1 || fake.touched[0] != tt.wantID {
+				t.Errorf("touched keys = %v, want %s", fake.touched, tt.wantID)
+			}
+		})
+	}
+}
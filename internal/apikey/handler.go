@@ -0,0 +1,160 @@
+package apikey
+
+import (
+	"encoding/json"
+	"fmt"
+	db "github.com/bigusef/texorbit/internal/database"
+	"github.com/bigusef/texorbit/pkg/config"
+	"github.com/bigusef/texorbit/pkg/middleware"
+	"github.com/bigusef/texorbit/pkg/util"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"net/http"
+	"time"
+)
+
+type apiKeyHandler struct {
+	conf     *config.Setting
+	queries  *db.Queries
+	validate *validator.Validate
+}
+
+func newApiKeyResponse(key db.ApiKey) apiKeyResponse {
+	response := apiKeyResponse{
+		ID:        key.ID,
+		Name:      key.Name,
+		Prefix:    key.Prefix,
+		Scopes:    key.Scopes,
+		CreatedAt: key.CreatedAt.Time,
+	}
+
+	if key.CreatedBy.Valid {
+		createdBy := uuid.UUID(key.CreatedBy.Bytes)
+		response.CreatedBy = &createdBy
+	}
+	if key.ExpiresAt.Valid {
+		response.ExpiresAt = &key.ExpiresAt.Time
+	}
+	if key.LastUsedAt.Valid {
+		response.LastUsedAt = &key.LastUsedAt.Time
+	}
+	if key.RevokedAt.Valid {
+		response.RevokedAt = &key.RevokedAt.Time
+	}
+
+	return response
+}
+
+func (h *apiKeyHandler) createKey(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	_, claims, _ := jwtauth.FromContext(ctx)
+
+	var input apiKeyInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		util.ErrorResponseWriter(w, r, util.BadRequest("invalid request body"))
+		return
+	}
+
+	if err := h.validate.Struct(input); err != nil {
+		util.ErrorResponseWriter(w, r, util.ValidationFailed(err))
+		return
+	}
+
+	if input.ExpiresAt != nil && input.ExpiresAt.Before(time.Now()) {
+		util.ErrorResponseWriter(w, r, util.Invalid(map[string]string{"expires_at": "future"}))
+		return
+	}
+
+	// staff can not give a key more access than they have
+	for i, scope := range input.Scopes {
+		if !middleware.HasPermission(claims, scope) {
+			util.ErrorResponseWriter(w, r, util.Invalid(map[string]string{fmt.Sprintf("scopes[%d]", i): "not_granted"}))
+			return
+		}
+	}
+
+	createdBy := pgtype.UUID{}
+	if sub, ok := claims["sub"].(string); ok {
+		if id, err := uuid.Parse(sub); err == nil {
+			createdBy = pgtype.UUID{Bytes: id, Valid: true}
+		}
+	}
+
+	expiresAt := pgtype.Timestamptz{}
+	if input.ExpiresAt != nil {
+		expiresAt = pgtype.Timestamptz{Time: *input.ExpiresAt, Valid: true}
+	}
+
+	key, prefix, secretHash, err := generate()
+	if err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	apiKey, err := h.queries.CreateApiKey(ctx, db.CreateApiKeyParams{
+		Name:       input.Name,
+		Prefix:     prefix,
+		SecretHash: secretHash,
+		Scopes:     input.Scopes,
+		CreatedBy:  createdBy,
+		ExpiresAt:  expiresAt,
+	})
+	if err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	util.JsonResponseWriter(w, http.StatusCreated, createdApiKeyResponse{
+		apiKeyResponse: newApiKeyResponse(apiKey),
+		Key:            key,
+	})
+}
+
+func (h *apiKeyHandler) listKeys(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	page := ctx.Value("pagination").(*middleware.Paginator)
+
+	keys, err := h.queries.AllApiKeys(ctx, db.AllApiKeysParams{Limit: page.Limit, Offset: page.Offset})
+	if err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	result := make([]apiKeyResponse, len(keys))
+	for i, key := range keys {
+		result[i] = newApiKeyResponse(key)
+	}
+
+	count, err := h.queries.AllApiKeysCount(ctx)
+	if err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	util.JsonListResponseWriter(w, http.StatusOK, result, count)
+}
+
+func (h *apiKeyHandler) revokeKey(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		util.ErrorResponseWriter(w, r, util.BadRequest("invalid api key id"))
+		return
+	}
+
+	affected, err := h.queries.RevokeApiKey(r.Context(), id)
+	if err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+	if affected == 0 {
+		util.ErrorResponseWriter(w, r, util.NotFound("api key not found"))
+		return
+	}
+
+	util.JsonResponseWriter(w, http.StatusNoContent, nil)
+}
@@ -0,0 +1,33 @@
+package apikey
+
+import (
+	"github.com/bigusef/texorbit/internal/audit"
+	db "github.com/bigusef/texorbit/internal/database"
+	"github.com/bigusef/texorbit/pkg/config"
+	"github.com/bigusef/texorbit/pkg/jwtkeys"
+	"github.com/bigusef/texorbit/pkg/middleware"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"net/http"
+)
+
+func NewRouter(conf *config.Setting, queries *db.Queries, validate *validator.Validate) http.Handler {
+	r := chi.NewRouter()
+	h := &apiKeyHandler{
+		queries:  queries,
+		conf:     conf,
+		validate: validate,
+	}
+
+	// staff tokens only, there is no api key verifier here so a key can not issue other keys
+	r.Use(jwtkeys.Verifier(conf.AccessAuth))
+	r.Use(jwtkeys.Authenticator(conf.AccessAuth))
+	r.Use(audit.Impersonation(queries))
+	r.Use(middleware.RequirePermission(middleware.ApiKeyManage))
+
+	r.Post("/", h.createKey)
+	r.With(middleware.Pagination).Get("/", h.listKeys)
+	r.Delete("/{id}", h.revokeKey)
+
+	return r
+}
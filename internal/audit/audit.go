@@ -0,0 +1,27 @@
+package audit
+
+import (
+	"context"
+	db "github.com/bigusef/texorbit/internal/database"
+	"github.com/bigusef/texorbit/pkg/middleware"
+	"github.com/google/uuid"
+	"net/http"
+)
+
+// Impersonation record every request made with impersonation token in the audit table, it should be
+// used by every router after the authenticator so no impersonated request is left out of the audit trail
+func Impersonation(queries *db.Queries) func(http.Handler) http.Handler {
+	return middleware.Impersonation(func(ctx context.Context, request middleware.ImpersonationRequest) error {
+		id, err := uuid.Parse(request.ImpersonationID)
+		if err != nil {
+			return err
+		}
+
+		return queries.CreateImpersonationRequest(ctx, db.CreateImpersonationRequestParams{
+			ImpersonationID: id,
+			Method:          request.Method,
+			Path:            request.Path,
+			Status:          int32(request.Status),
+		})
+	})
+}
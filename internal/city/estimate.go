@@ -0,0 +1,32 @@
+package city
+
+import (
+	"context"
+	"github.com/bigusef/texorbit/pkg/util"
+)
+
+// planner estimates of the count queries, the rows of CitiesCount and FilterCitiesCount
+// selected without counting them. keep the conditions in sync with sql/queries/cities.sql
+const (
+	estimateCities = `EXPLAIN (FORMAT JSON)
+SELECT 1
+FROM cities`
+
+	estimateFilterCities = `EXPLAIN (FORMAT JSON)
+SELECT 1
+FROM cities
+WHERE EXISTS (SELECT 1
+              FROM translations
+              WHERE entity = 'city'
+                AND entity_id = cities.id
+                AND field = 'name'
+                AND value ILIKE $1)`
+)
+
+func estimateCitiesCount(ctx context.Context, conn util.RowQuerier) (int64, error) {
+	return util.EstimateRows(ctx, conn, estimateCities)
+}
+
+func estimateFilterCitiesCount(ctx context.Context, conn util.RowQuerier, query string) (int64, error) {
+	return util.EstimateRows(ctx, conn, estimateFilterCities, query)
+}
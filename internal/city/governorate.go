@@ -0,0 +1,212 @@
+package city
+
+import (
+	"encoding/json"
+	db "github.com/bigusef/texorbit/internal/database"
+	"github.com/bigusef/texorbit/internal/translation"
+	"github.com/bigusef/texorbit/pkg/i18n"
+	"github.com/bigusef/texorbit/pkg/util"
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+	"net/http"
+	"strconv"
+)
+
+func (h *cityHandler) listGovernorates(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	governorates, err := h.queries.AllGovernorates(ctx)
+	if err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	names, err := translation.Load(ctx, h.queries, translation.EntityGovernorate, translation.FieldName, governorates)
+	if err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	locale := i18n.FromContext(ctx)
+	response := make([]governorateResponse, len(governorates))
+	for i, id := range governorates {
+		response[i] = newGovernorateResponse(id, names[id], locale)
+	}
+
+	util.JsonListResponseWriter(w, http.StatusOK, response, int64(len(response)))
+}
+
+func (h *cityHandler) createGovernorate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	input, ok := h.decodeGovernorate(w, r)
+	if !ok {
+		return
+	}
+
+	// the governorate is created with its names or not at all
+	var id int64
+	err := pgx.BeginFunc(ctx, h.pool, func(tx pgx.Tx) error {
+		queries := h.queries.WithTx(tx)
+
+		var err error
+		if id, err = queries.CreateGovernorate(ctx); err != nil {
+			return err
+		}
+		return translation.Save(ctx, queries, translation.EntityGovernorate, id, translation.FieldName, input.Names)
+	})
+	if err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	util.JsonResponseWriter(w, http.StatusCreated, newGovernorateResponse(id, input.Names, i18n.FromContext(ctx)))
+}
+
+func (h *cityHandler) updateGovernorate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		util.ErrorResponseWriter(w, r, util.BadRequest(err.Error()))
+		return
+	}
+
+	input, ok := h.decodeGovernorate(w, r)
+	if !ok {
+		return
+	}
+
+	// governorates have only translated fields, so updating one is saving its names, all of them or none
+	err = pgx.BeginFunc(ctx, h.pool, func(tx pgx.Tx) error {
+		queries := h.queries.WithTx(tx)
+
+		found, err := queries.ExistingEntityIds(ctx, db.ExistingEntityIdsParams{Entity: translation.EntityGovernorate, Ids: []int64{id}})
+		if err != nil {
+			return err
+		}
+		if len(found) == 0 {
+			return util.NotFound("Governorate not found")
+		}
+
+		return translation.Save(ctx, queries, translation.EntityGovernorate, id, translation.FieldName, input.Names)
+	})
+	if err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	util.JsonResponseWriter(w, http.StatusOK, newGovernorateResponse(id, input.Names, i18n.FromContext(ctx)))
+}
+
+func (h *cityHandler) deleteGovernorate(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		util.ErrorResponseWriter(w, r, util.BadRequest(err.Error()))
+		return
+	}
+
+	affected, err := h.queries.DeleteGovernorate(r.Context(), id)
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			util.ErrorResponseWriter(w, r, util.NewError(http.StatusConflict, util.CodeReferenced, "Governorate has cities, move or delete them first"))
+			return
+		}
+
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+	if affected == 0 {
+		util.ErrorResponseWriter(w, r, util.NotFound("Governorate not found"))
+		return
+	}
+
+	util.JsonResponseWriter(w, http.StatusNoContent, nil)
+}
+
+// activeCityTree list governorates with their active cities for the apps location pickers,
+// governorates without active cities are omitted and cities not assigned to a governorate yet
+// (created before governorates) are listed last under a group without id
+func (h *cityHandler) activeCityTree(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	governorates, err := h.queries.AllGovernorates(ctx)
+	if err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	cities, err := h.queries.AllActiveCities(ctx)
+	if err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	cityNames, err := translation.Load(ctx, h.queries, translation.EntityCity, translation.FieldName, cityIds(cities))
+	if err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+	governorateNames, err := translation.Load(ctx, h.queries, translation.EntityGovernorate, translation.FieldName, governorates)
+	if err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	util.JsonResponseWriter(w, http.StatusOK, cityTree(governorates, cities, governorateNames, cityNames, i18n.FromContext(ctx)))
+}
+
+// cityTree group the active cities under their governorates in the governorates order,
+// governorates without cities are omitted and cities without governorate are grouped last
+func cityTree(governorates []int64, cities []db.City, governorateNames, cityNames map[int64]translation.Names, locale string) []governorateTree {
+	byGovernorate := map[int64][]activeCityResponse{}
+	var unassigned []activeCityResponse
+	for _, city := range cities {
+		if !city.GovernorateID.Valid {
+			unassigned = append(unassigned, activeCityResponse{
+				ID:   city.ID,
+				Name: cityNames[city.ID].Resolve(locale),
+			})
+			continue
+		}
+
+		byGovernorate[city.GovernorateID.Int64] = append(byGovernorate[city.GovernorateID.Int64], activeCityResponse{
+			ID:   city.ID,
+			Name: cityNames[city.ID].Resolve(locale),
+		})
+	}
+
+	response := make([]governorateTree, 0, len(byGovernorate)+1)
+	for _, id := range governorates {
+		if len(byGovernorate[id]) == 0 {
+			continue
+		}
+
+		response = append(response, governorateTree{
+			ID:     &id,
+			Name:   governorateNames[id].Resolve(locale),
+			Cities: byGovernorate[id],
+		})
+	}
+	if len(unassigned) > 0 {
+		response = append(response, governorateTree{
+			Name:   i18n.T(locale, "city.no_governorate"),
+			Cities: unassigned,
+		})
+	}
+
+	return response
+}
+
+func (h *cityHandler) decodeGovernorate(w http.ResponseWriter, r *http.Request) (governorateInput, bool) {
+	var input governorateInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		util.ErrorResponseWriter(w, r, util.BadRequest(err.Error()))
+		return input, false
+	}
+
+	if err := h.validate.Struct(input); err != nil {
+		util.ErrorResponseWriter(w, r, util.ValidationFailed(err))
+		return input, false
+	}
+
+	return input, h.validNames(w, r, &input.Names)
+}
@@ -0,0 +1,78 @@
+package city
+
+import (
+	db "github.com/bigusef/texorbit/internal/database"
+	"github.com/bigusef/texorbit/internal/translation"
+	"github.com/jackc/pgx/v5/pgtype"
+	"reflect"
+	"testing"
+)
+
+func TestCityTree(t *testing.T) {
+	inGovernorate := func(id, governorate int64) db.City {
+		return db.City{ID: id, IsActive: true, GovernorateID: pgtype.Int8{Int64: governorate, Valid: true}}
+	}
+	governorateNames := map[int64]translation.Names{
+		1: {"en": "Cairo", "ar": "القاهرة"},
+		2: {"en": "Giza", "ar": "الجيزة"},
+		3: {"en": "Alexandria", "ar": "الإسكندرية"},
+	}
+	cityNames := map[int64]translation.Names{
+		10: {"en": "New Cairo", "ar": "القاهرة الجديدة"},
+		11: {"en": "15 May", "ar": "15 مايو"},
+		20: {"en": "Dokki", "ar": "الدقي"},
+		30: {"en": "El Alamein"},
+	}
+	id := func(id int64) *int64 { return &id }
+
+	tests := []struct {
+		name         string
+		governorates []int64
+		cities       []db.City
+		locale       string
+		want         []governorateTree
+	}{
+		{
+			name:         "cities grouped in governorates order",
+			governorates: []int64{2, 1},
+			cities:       []db.City{inGovernorate(10, 1), inGovernorate(20, 2), inGovernorate(11, 1)},
+			locale:       "en",
+			want: []governorateTree{
+				{ID: id(2), Name: "Giza", Cities: []activeCityResponse{{ID: 20, Name: "Dokki"}}},
+				{ID: id(1), Name: "Cairo", Cities: []activeCityResponse{{ID: 10, Name: "New Cairo"}, {ID: 11, Name: "15 May"}}},
+			},
+		},
+		{
+			name:         "governorate without active cities omitted",
+			governorates: []int64{1, 2, 3},
+			cities:       []db.City{inGovernorate(20, 2)},
+			locale:       "ar",
+			want:         []governorateTree{{ID: id(2), Name: "الجيزة", Cities: []activeCityResponse{{ID: 20, Name: "الدقي"}}}},
+		},
+		{
+			name:         "cities without governorate listed last",
+			governorates: []int64{1},
+			cities:       []db.City{{ID: 30, IsActive: true}, inGovernorate(10, 1)},
+			locale:       "ar",
+			want: []governorateTree{
+				{ID: id(1), Name: "القاهرة", Cities: []activeCityResponse{{ID: 10, Name: "القاهرة الجديدة"}}},
+				{Name: "مدن أخرى", Cities: []activeCityResponse{{ID: 30, Name: "El Alamein"}}},
+			},
+		},
+		{
+			name:         "no active cities",
+			governorates: []int64{1, 2},
+			locale:       "en",
+			want:         []governorateTree{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := cityTree(tt.governorates, tt.cities, governorateNames, cityNames, tt.locale)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("cityTree() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
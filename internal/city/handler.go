@@ -1,21 +1,28 @@
 package city
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	db "github.com/bigusef/texorbit/internal/database"
+	"github.com/bigusef/texorbit/internal/translation"
 	"github.com/bigusef/texorbit/pkg/config"
+	"github.com/bigusef/texorbit/pkg/i18n"
 	"github.com/bigusef/texorbit/pkg/middleware"
 	"github.com/bigusef/texorbit/pkg/util"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-playground/validator/v10"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"net/http"
 	"strconv"
 )
 
 type cityHandler struct {
 	conf     *config.Setting
+	pool     *pgxpool.Pool
 	queries  *db.Queries
 	validate *validator.Validate
 }
@@ -23,30 +30,45 @@ type cityHandler struct {
 func (h *cityHandler) createCity(w http.ResponseWriter, r *http.Request) {
 	var input cityInput
 	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		util.ErrorResponseWriter(w, r, util.BadRequest(err.Error()))
 		return
 	}
 
 	if err := h.validate.Struct(input); err != nil {
-		ts := map[string]string{}
-		for _, err := range err.(validator.ValidationErrors) {
-			ts[err.Field()] = err.Tag()
-		}
+		util.ErrorResponseWriter(w, r, util.ValidationFailed(err))
+		return
+	}
 
-		util.JsonResponseWriter(w, http.StatusBadRequest, ts)
+	if !h.validNames(w, r, &input.Names) || !cityLocation(w, r, &input) {
 		return
 	}
 
-	id, err := h.queries.CreateCity(
-		r.Context(),
-		db.CreateCityParams{
-			NameEn:   input.NameEn,
-			NameAr:   input.NameAr,
-			IsActive: *input.IsActive,
-		},
-	)
+	// the city is created with its names or not at all
+	ctx := r.Context()
+	var id int64
+	err := pgx.BeginFunc(ctx, h.pool, func(tx pgx.Tx) error {
+		queries := h.queries.WithTx(tx)
+
+		var err error
+		id, err = queries.CreateCity(
+			ctx,
+			db.CreateCityParams{
+				IsActive:      *input.IsActive,
+				GovernorateID: pgtype.Int8{Int64: input.GovernorateID, Valid: true},
+				Latitude:      floatParam(input.Latitude),
+				Longitude:     floatParam(input.Longitude),
+				Boundary:      input.Boundary,
+			},
+		)
+		if err != nil {
+			return err
+		}
+
+		return translation.Save(ctx, queries, translation.EntityCity, id, translation.FieldName, input.Names)
+	})
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		// unknown governorate is validation error of governorate_id
+		util.ErrorResponseWriter(w, r, err)
 		return
 	}
 
@@ -60,45 +82,94 @@ func (h *cityHandler) listCities(w http.ResponseWriter, r *http.Request) {
 	page := ctx.Value("pagination").(*middleware.Paginator)
 	query := r.URL.Query().Get("q")
 
-	var err error
-	var cities []db.City
-	if query != "" {
-		arg := db.FilterCitiesParams{
-			Limit:  page.Limit,
-			Offset: page.Offset,
-			Query:  query,
-		}
-		cities, err = h.queries.FilterCities(ctx, arg)
-	} else {
-		arg := db.AllCitiesParams{
-			Limit:  page.Limit,
-			Offset: page.Offset,
-		}
-		cities, err = h.queries.AllCities(ctx, arg)
+	cities, more, err := h.pageCities(ctx, page, query)
+	if err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
 	}
 
+	names, err := translation.Load(ctx, h.queries, translation.EntityCity, translation.FieldName, cityIds(cities))
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		util.ErrorResponseWriter(w, r, err)
 		return
 	}
 
+	locale := i18n.FromContext(ctx)
 	response := make([]cityResponse, len(cities))
 	for i, city := range cities {
-		response[i] = cityResponse{
-			ID:       city.ID,
-			NameEn:   city.NameEn,
-			NameAr:   city.NameAr,
-			IsActive: city.IsActive,
-		}
+		response[i] = newCityResponse(city, names[city.ID], locale)
 	}
 
-	totalCount, err := h.queries.CitiesCount(ctx)
+	if len(cities) > 0 {
+		first, last := cities[0].ID, cities[len(cities)-1].ID
+		page.SetLinks(w, []string{strconv.FormatInt(first, 10)}, []string{strconv.FormatInt(last, 10)}, more)
+	}
+
+	// get total cities count
+	count, err := h.countCities(ctx, page.Count, query)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		util.ErrorResponseWriter(w, r, err)
 		return
 	}
 
-	util.JsonListResponseWriter(w, http.StatusOK, response, totalCount)
+	util.JsonPageResponseWriter(w, http.StatusOK, response, count)
+}
+
+// pageCities query the cities of the offset or keyset page, optionally filtered by name,
+// it reports whether more cities follow the page in the direction it was read
+func (h *cityHandler) pageCities(ctx context.Context, page *middleware.Paginator, query string) ([]db.City, bool, error) {
+	var cities []db.City
+	var err error
+	if page.Cursor == nil {
+		if query != "" {
+			cities, err = h.queries.FilterCities(ctx, db.FilterCitiesParams{Limit: page.Fetch(), Offset: page.Offset, Query: query})
+		} else {
+			cities, err = h.queries.AllCities(ctx, db.AllCitiesParams{Limit: page.Fetch(), Offset: page.Offset})
+		}
+	} else {
+		if len(page.Cursor.Keys) != 1 {
+			return nil, false, util.Invalid(map[string]string{"cursor": "invalid"})
+		}
+		id, parseErr := strconv.ParseInt(page.Cursor.Keys[0], 10, 64)
+		if parseErr != nil {
+			return nil, false, util.Invalid(map[string]string{"cursor": "invalid"})
+		}
+
+		filter := pgtype.Text{String: query, Valid: query != ""}
+		if page.Backward() {
+			cities, err = h.queries.CitiesBefore(ctx, db.CitiesBeforeParams{Query: filter, BeforeID: id, PageSize: page.Fetch()})
+		} else {
+			cities, err = h.queries.CitiesAfter(ctx, db.CitiesAfterParams{Query: filter, AfterID: id, PageSize: page.Fetch()})
+		}
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	cities, more := middleware.Page(page, cities)
+	return cities, more, nil
+}
+
+// countCities total count of the cities matching the name filter in the count mode, nil when it is not counted
+func (h *cityHandler) countCities(ctx context.Context, mode, query string) (*int64, error) {
+	var count int64
+	var err error
+	switch {
+	case mode == middleware.CountNone:
+		return nil, nil
+	case mode == middleware.CountEstimated && query != "":
+		count, err = estimateFilterCitiesCount(ctx, h.pool, query)
+	case mode == middleware.CountEstimated:
+		count, err = estimateCitiesCount(ctx, h.pool)
+	case query != "":
+		count, err = h.queries.FilterCitiesCount(ctx, query)
+	default:
+		count, err = h.queries.CitiesCount(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &count, nil
 }
 
 func (h *cityHandler) listActiveCities(w http.ResponseWriter, r *http.Request) {
@@ -106,32 +177,44 @@ func (h *cityHandler) listActiveCities(w http.ResponseWriter, r *http.Request) {
 
 	//TODO: try to make ListActiveCityParams AND ListAllCitiesParams one struct
 	page := ctx.Value("pagination").(*middleware.Paginator)
-	cities, err := h.queries.ActiveCities(ctx, db.ActiveCitiesParams{page.Limit, page.Offset})
+
+	// optional governorate filter
+	var governorate pgtype.Int8
+	if raw := r.URL.Query().Get("governorate"); raw != "" {
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			util.ErrorResponseWriter(w, r, util.Invalid(map[string]string{"governorate": "number"}))
+			return
+		}
+		governorate = pgtype.Int8{Int64: id, Valid: true}
+	}
+
+	cities, err := h.queries.ActiveCities(ctx, db.ActiveCitiesParams{Limit: page.Limit, Offset: page.Offset, GovernorateID: governorate})
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		util.ErrorResponseWriter(w, r, util.BadRequest(err.Error()))
 		return
 	}
 
-	requestLang := r.Header.Get("Accept-Language")
+	names, err := translation.Load(ctx, h.queries, translation.EntityCity, translation.FieldName, cityIds(cities))
+	if err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	locale := i18n.FromContext(ctx)
 	response := make([]activeCityResponse, len(cities))
 	for i, city := range cities {
-		var name string
-		if requestLang == "ar" {
-			name = city.NameAr
-		} else {
-			name = city.NameEn
-		}
-
 		response[i] = activeCityResponse{
-			ID:   city.ID,
-			Name: name,
+			ID:            city.ID,
+			Name:          names[city.ID].Resolve(locale),
+			GovernorateID: governorateId(city.GovernorateID),
 		}
 	}
 
 	// get total cities count
-	totalCount, err := h.queries.ActiveCitiesCount(ctx)
+	totalCount, err := h.queries.ActiveCitiesCount(ctx, governorate)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		util.ErrorResponseWriter(w, r, util.BadRequest(err.Error()))
 		return
 	}
 
@@ -142,53 +225,59 @@ func (h *cityHandler) updateCity(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "id")
 	id, err := strconv.ParseInt(idStr, 10, 64)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		util.ErrorResponseWriter(w, r, util.BadRequest(err.Error()))
 		return
 	}
 
 	var input cityInput
 	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		util.ErrorResponseWriter(w, r, util.BadRequest(err.Error()))
 		return
 	}
 
 	if err := h.validate.Struct(input); err != nil {
-		ts := map[string]string{}
-		for _, err := range err.(validator.ValidationErrors) {
-			ts[err.Field()] = err.Tag()
-		}
+		util.ErrorResponseWriter(w, r, util.ValidationFailed(err))
+		return
+	}
 
-		util.JsonResponseWriter(w, http.StatusBadRequest, ts)
+	if !h.validNames(w, r, &input.Names) || !cityLocation(w, r, &input) {
 		return
 	}
 
-	city, err := h.queries.UpdateCity(
-		r.Context(),
-		db.UpdateCityParams{
-			ID:       id,
-			NameEn:   input.NameEn,
-			NameAr:   input.NameAr,
-			IsActive: *input.IsActive,
-		},
-	)
+	// the city is updated with its names or not at all
+	ctx := r.Context()
+	var city db.City
+	err = pgx.BeginFunc(ctx, h.pool, func(tx pgx.Tx) error {
+		queries := h.queries.WithTx(tx)
+
+		var err error
+		city, err = queries.UpdateCity(
+			ctx,
+			db.UpdateCityParams{
+				ID:            id,
+				IsActive:      *input.IsActive,
+				GovernorateID: pgtype.Int8{Int64: input.GovernorateID, Valid: true},
+				Latitude:      floatParam(input.Latitude),
+				Longitude:     floatParam(input.Longitude),
+				Boundary:      input.Boundary,
+			},
+		)
+		if err != nil {
+			return err
+		}
+
+		return translation.Save(ctx, queries, translation.EntityCity, city.ID, translation.FieldName, input.Names)
+	})
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			http.Error(w, "City not found", http.StatusNotFound)
+			util.ErrorResponseWriter(w, r, util.NotFound("City not found"))
 			return
 		}
-
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		util.ErrorResponseWriter(w, r, err)
 		return
 	}
 
-	response := cityResponse{
-		ID:       city.ID,
-		NameEn:   city.NameEn,
-		NameAr:   city.NameAr,
-		IsActive: city.IsActive,
-	}
-
-	util.JsonResponseWriter(w, http.StatusOK, response)
+	util.JsonResponseWriter(w, http.StatusOK, newCityResponse(city, input.Names, i18n.FromContext(ctx)))
 }
 
 func (h *cityHandler) deleteCity(w http.ResponseWriter, r *http.Request) {
@@ -197,14 +286,45 @@ func (h *cityHandler) deleteCity(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "id")
 	id, err := strconv.ParseInt(idStr, 10, 64)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		util.ErrorResponseWriter(w, r, err)
 		return
 	}
 
 	if err = h.queries.DeleteCity(ctx, id); err != nil {
-		http.Error(w, "Failed to delete city", http.StatusInternalServerError)
+		// city used by customer addresses can be deactivated instead
+		if isForeignKeyViolation(err) {
+			util.ErrorResponseWriter(w, r, util.NewError(http.StatusConflict, util.CodeReferenced, "City is used by customer addresses, deactivate it instead"))
+			return
+		}
+
+		util.ErrorResponseWriter(w, r, err)
 		return
 	}
 
 	util.JsonResponseWriter(w, http.StatusNoContent, nil)
 }
+
+// validNames canonicalize the locales of the names, and write validation error when they are invalid
+func (h *cityHandler) validNames(w http.ResponseWriter, r *http.Request, names *translation.Names) bool {
+	*names = names.Canonical()
+	if errs := translation.Check(h.validate, "names", *names); errs != nil {
+		util.ErrorResponseWriter(w, r, util.Invalid(errs))
+		return false
+	}
+
+	return true
+}
+
+func cityIds(cities []db.City) []int64 {
+	ids := make([]int64, len(cities))
+	for i, city := range cities {
+		ids[i] = city.ID
+	}
+	return ids
+}
+
+// isForeignKeyViolation report whether the error is postgres foreign key violation
+func isForeignKeyViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23503"
+}
@@ -0,0 +1,128 @@
+package city
+
+import (
+	"context"
+	db "github.com/bigusef/texorbit/internal/database"
+	"github.com/bigusef/texorbit/internal/translation"
+	"github.com/bigusef/texorbit/pkg/geo"
+	"github.com/bigusef/texorbit/pkg/i18n"
+	"github.com/bigusef/texorbit/pkg/util"
+	"net/http"
+	"strconv"
+)
+
+const (
+	// nearestCandidates number of the nearest cities checked for a point
+	nearestCandidates = 10
+	// maxCityDistance how far from the center of a city without boundary a point still belongs to it, in meters
+	maxCityDistance = 30_000
+)
+
+// Locate find the active city of the point, the bool result is false when the point is outside all active cities
+func Locate(ctx context.Context, queries *db.Queries, point geo.Point) (db.City, float64, bool, error) {
+	candidates, err := queries.NearestActiveCities(ctx, db.NearestActiveCitiesParams{
+		Lat:        point.Lat,
+		Lng:        point.Lng,
+		MaxResults: nearestCandidates,
+	})
+	if err != nil {
+		return db.City{}, 0, false, err
+	}
+
+	i, distance, ok := matchCity(point, candidates)
+	if !ok {
+		return db.City{}, 0, false, nil
+	}
+
+	return candidates[i], distance, true, nil
+}
+
+// matchCity select the city containing the point, the nearest city with boundary containing the point wins,
+// otherwise the nearest city without boundary within maxCityDistance. it returns the city index and its center distance.
+func matchCity(point geo.Point, cities []db.City) (int, float64, bool) {
+	best, bestDistance := -1, 0.0
+	for i, city := range cities {
+		if !city.Latitude.Valid || !city.Longitude.Valid {
+			continue
+		}
+		distance := geo.Distance(point, geo.Point{Lat: city.Latitude.Float64, Lng: city.Longitude.Float64})
+
+		if len(city.Boundary) > 0 {
+			boundary, err := geo.ParseGeoJSON(city.Boundary)
+			if err == nil {
+				if boundary.Contains(point) {
+					return i, distance, true
+				}
+				continue
+			}
+		}
+
+		if distance <= maxCityDistance && (best < 0 || distance < bestDistance) {
+			best, bestDistance = i, distance
+		}
+	}
+
+	if best < 0 {
+		return 0, 0, false
+	}
+	return best, bestDistance, true
+}
+
+// cityLocation validate the city boundary, the boundary center is used when the city has no coordinates
+func cityLocation(w http.ResponseWriter, r *http.Request, input *cityInput) bool {
+	if len(input.Boundary) == 0 || string(input.Boundary) == "null" {
+		input.Boundary = nil
+		return true
+	}
+
+	boundary, err := geo.ParseGeoJSON(input.Boundary)
+	if err != nil {
+		util.ErrorResponseWriter(w, r, util.Invalid(map[string]string{"boundary": "geojson"}))
+		return false
+	}
+
+	if input.Latitude == nil {
+		center := boundary.Center()
+		input.Latitude, input.Longitude = &center.Lat, &center.Lng
+	}
+
+	return true
+}
+
+func (h *cityHandler) nearestCity(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	lat, err := strconv.ParseFloat(query.Get("lat"), 64)
+	if err != nil || lat < -90 || lat > 90 {
+		util.ErrorResponseWriter(w, r, util.Invalid(map[string]string{"lat": "latitude"}))
+		return
+	}
+	lng, err := strconv.ParseFloat(query.Get("lng"), 64)
+	if err != nil || lng < -180 || lng > 180 {
+		util.ErrorResponseWriter(w, r, util.Invalid(map[string]string{"lng": "longitude"}))
+		return
+	}
+
+	city, distance, ok, err := Locate(r.Context(), h.queries, geo.Point{Lat: lat, Lng: lng})
+	if err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+	if !ok {
+		util.ErrorResponseWriter(w, r, util.NotFound("No active city at this location"))
+		return
+	}
+
+	names, err := translation.Load(r.Context(), h.queries, translation.EntityCity, translation.FieldName, []int64{city.ID})
+	if err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	util.JsonResponseWriter(w, http.StatusOK, nearestCityResponse{
+		ID:            city.ID,
+		Name:          names[city.ID].Resolve(i18n.FromContext(r.Context())),
+		GovernorateID: governorateId(city.GovernorateID),
+		Distance:      distance,
+	})
+}
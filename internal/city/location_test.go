@@ -0,0 +1,172 @@
+package city
+
+import (
+	"context"
+	"errors"
+	db "github.com/bigusef/texorbit/internal/database"
+	"github.com/bigusef/texorbit/pkg/geo"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"testing"
+)
+
+// testCity active city centered at the point with optional GeoJSON boundary
+func testCity(id int64, lat, lng float64, boundary string) db.City {
+	city := db.City{
+		ID:        id,
+		IsActive:  true,
+		Latitude:  pgtype.Float8{Float64: lat, Valid: true},
+		Longitude: pgtype.Float8{Float64: lng, Valid: true},
+	}
+	if boundary != "" {
+		city.Boundary = []byte(boundary)
+	}
+	return city
+}
+
+// square around cairo downtown (31.2-31.3 E, 30.0-30.1 N) with a hole (31.24-31.26 E, 30.04-30.06 N)
+const cairoBoundary = `{"type":"Polygon","coordinates":[
+	[[31.2,30.0],[31.3,30.0],[31.3,30.1],[31.2,30.1],[31.2,30.0]],
+	[[31.24,30.04],[31.26,30.04],[31.26,30.06],[31.24,30.06],[31.24,30.04]]]}`
+
+// giza boundary sharing cairo west edge
+const gizaBoundary = `{"type":"Polygon","coordinates":[[[31.1,30.0],[31.2,30.0],[31.2,30.1],[31.1,30.1],[31.1,30.0]]]}`
+
+// taveuni island crossing the antimeridian, split as GeoJSON requires
+const taveuniBoundary = `{"type":"MultiPolygon","coordinates":[
+	[[[179.8,-17.0],[180,-17.0],[180,-16.6],[179.8,-16.6],[179.8,-17.0]]],
+	[[[-180,-17.0],[-179.8,-17.0],[-179.8,-16.6],[-180,-16.6],[-180,-17.0]]]]}`
+
+func TestMatchCity(t *testing.T) {
+	cairo := testCity(1, 30.05, 31.25, cairoBoundary)
+	giza := testCity(2, 30.05, 31.15, gizaBoundary)
+	// city without boundary matched by distance to its center
+	tanta := testCity(3, 30.7865, 31.0004, "")
+	banha := testCity(4, 30.4660, 31.1848, "")
+	taveuni := testCity(5, -16.8, 180, taveuniBoundary)
+	noLocation := db.City{ID: 6, IsActive: true}
+	invalidBoundary := testCity(7, 30.7865, 31.0004, `{"type":"Point","coordinates":[31,30.7]}`)
+
+	tests := []struct {
+		name   string
+		point  geo.Point
+		cities []db.City
+		want   int64 // city id, 0 when no city matches
+	}{
+		{"inside boundary", geo.Point{Lat: 30.02, Lng: 31.22}, []db.City{cairo, giza}, 1},
+		{"nearest center outside its boundary", geo.Point{Lat: 30.02, Lng: 31.19}, []db.City{cairo, giza}, 2},
+		{"inside boundary hole", geo.Point{Lat: 30.05, Lng: 31.25}, []db.City{cairo, giza}, 0},
+		{"on hole edge", geo.Point{Lat: 30.05, Lng: 31.24}, []db.City{cairo}, 1},
+		{"on shared edge first candidate wins", geo.Point{Lat: 30.05, Lng: 31.2}, []db.City{giza, cairo}, 2},
+		{"on boundary vertex", geo.Point{Lat: 30.1, Lng: 31.3}, []db.City{cairo}, 1},
+		{"boundary wins over nearer center", geo.Point{Lat: 30.09, Lng: 31.21}, []db.City{tanta, giza, cairo}, 1},
+		{"outside boundary not matched by distance", geo.Point{Lat: 30.11, Lng: 31.25}, []db.City{cairo}, 0},
+		{"within distance of center", geo.Point{Lat: 30.8, Lng: 31.1}, []db.City{tanta}, 3},
+		{"nearest center without boundary", geo.Point{Lat: 30.6, Lng: 31.1}, []db.City{tanta, banha}, 4},
+		{"too far from center", geo.Point{Lat: 31.2, Lng: 29.9}, []db.City{tanta, banha}, 0},
+		{"invalid boundary falls back to distance", geo.Point{Lat: 30.8, Lng: 31.1}, []db.City{invalidBoundary}, 7},
+		{"city without location skipped", geo.Point{Lat: 30.8, Lng: 31.1}, []db.City{noLocation, tanta}, 3},
+		{"east of antimeridian", geo.Point{Lat: -16.8, Lng: 179.9}, []db.City{taveuni}, 5},
+		{"west of antimeridian", geo.Point{Lat: -16.8, Lng: -179.9}, []db.City{taveuni}, 5},
+		{"beyond antimeridian boundary", geo.Point{Lat: -16.8, Lng: -179.5}, []db.City{taveuni}, 0},
+		{"no candidates", geo.Point{Lat: 30.05, Lng: 31.25}, nil, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i, distance, ok := matchCity(tt.point, tt.cities)
+			if tt.want == 0 {
+				if ok {
+					t.Fatalf("matched city %d, want no city", tt.cities[i].ID)
+				}
+				return
+			}
+			if !ok {
+				t.Fatalf("no city matched, want city %d", tt.want)
+			}
+			if got := tt.cities[i].ID; got != tt.want {
+				t.Errorf("matched city %d, want %d", got, tt.want)
+			}
+
+			center := geo.Point{Lat: tt.cities[i].Latitude.Float64, Lng: tt.cities[i].Longitude.Float64}
+			if want := geo.Distance(tt.point, center); distance != want {
+				t.Errorf("distance = %f, want %f", distance, want)
+			}
+		})
+	}
+}
+
+// fakeDB answer every query with the cities rows, or the error
+type fakeDB struct {
+	cities []db.City
+	err    error
+	args   []interface{}
+}
+
+func (f *fakeDB) Exec(context.Context, string, ...interface{}) (pgconn.CommandTag, error) {
+	return pgconn.CommandTag{}, errors.New("unexpected exec")
+}
+
+func (f *fakeDB) Query(_ context.Context, _ string, args ...interface{}) (pgx.Rows, error) {
+	f.args = args
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &cityRows{cities: f.cities, index: -1}, nil
+}
+
+func (f *fakeDB) QueryRow(context.Context, string, ...interface{}) pgx.Row {
+	return nil
+}
+
+type cityRows struct {
+	pgx.Rows
+	cities []db.City
+	index  int
+}
+
+func (r *cityRows) Next() bool {
+	r.index++
+	return r.index < len(r.cities)
+}
+
+func (r *cityRows) Scan(dest ...interface{}) error {
+	city := r.cities[r.index]
+	*dest[0].(*int64) = city.ID
+	*dest[1].(*bool) = city.IsActive
+	*dest[2].(*pgtype.Int8) = city.GovernorateID
+	*dest[3].(*pgtype.Float8) = city.Latitude
+	*dest[4].(*pgtype.Float8) = city.Longitude
+	*dest[5].(*[]byte) = city.Boundary
+	return nil
+}
+
+func (r *cityRows) Close()     {}
+func (r *cityRows) Err() error { return nil }
+
+func TestLocate(t *testing.T) {
+	ctx := context.Background()
+	fake := &fakeDB{cities: []db.City{testCity(2, 30.05, 31.15, gizaBoundary), testCity(1, 30.05, 31.25, cairoBoundary)}}
+	queries := db.New(fake)
+
+	city, distance, ok, err := Locate(ctx, queries, geo.Point{Lat: 30.02, Lng: 31.22})
+	if err != nil || !ok {
+		t.Fatalf("Locate = %v, %v, want city", ok, err)
+	}
+	if city.ID != 1 || distance <= 0 {
+		t.Errorf("Locate = city %d at %f m, want city 1", city.ID, distance)
+	}
+	if len(fake.args) != 3 || fake.args[0] != 30.02 || fake.args[1] != 31.22 || fake.args[2] != int64(nearestCandidates) {
+		t.Errorf("candidates query args = %v", fake.args)
+	}
+
+	if _, _, ok, err := Locate(ctx, queries, geo.Point{Lat: 25, Lng: 33}); ok || err != nil {
+		t.Errorf("Locate outside all cities = %v, %v, want no city", ok, err)
+	}
+
+	fake.err = errors.New("connection refused")
+	if _, _, _, err := Locate(ctx, queries, geo.Point{Lat: 30.02, Lng: 31.22}); !errors.Is(err, fake.err) {
+		t.Errorf("Locate error = %v, want %v", err, fake.err)
+	}
+}
@@ -1,18 +1,22 @@
 package city
 
 import (
+	"github.com/bigusef/texorbit/internal/apikey"
+	"github.com/bigusef/texorbit/internal/audit"
 	db "github.com/bigusef/texorbit/internal/database"
 	"github.com/bigusef/texorbit/pkg/config"
+	"github.com/bigusef/texorbit/pkg/jwtkeys"
 	"github.com/bigusef/texorbit/pkg/middleware"
 	"github.com/go-chi/chi/v5"
-	"github.com/go-chi/jwtauth/v5"
 	"github.com/go-playground/validator/v10"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"net/http"
 )
 
-func NewRouter(conf *config.Setting, queries *db.Queries, validate *validator.Validate) http.Handler {
+func NewRouter(conf *config.Setting, pool *pgxpool.Pool, queries *db.Queries, validate *validator.Validate) http.Handler {
 	r := chi.NewRouter()
 	h := &cityHandler{
+		pool:     pool,
 		queries:  queries,
 		conf:     conf,
 		validate: validate,
@@ -20,18 +24,36 @@ func NewRouter(conf *config.Setting, queries *db.Queries, validate *validator.Va
 
 	//only staff
 	r.Group(func(r chi.Router) {
-		r.Use(jwtauth.Verifier(conf.AccessAuth))
-		r.Use(jwtauth.Authenticator(conf.AccessAuth))
-		r.Use(middleware.StaffPermission)
-
-		r.Post("/", h.createCity)
-		r.With(middleware.Pagination).Get("/", h.listCities)
-		r.Put("/{id}", h.updateCity)
-		r.Delete("/{id}", h.deleteCity)
+		r.Use(jwtkeys.Verifier(conf.AccessAuth))
+		r.Use(middleware.APIKeyVerifier(apikey.Lookup(queries)))
+		r.Use(jwtkeys.Authenticator(conf.AccessAuth))
+		r.Use(middleware.PreferredLocale)
+		r.Use(audit.Impersonation(queries))
+
+		r.With(middleware.RequirePermission(middleware.CityWrite)).Post("/", h.createCity)
+		r.With(middleware.RequirePermission(middleware.CityRead), middleware.CursorPagination).Get("/", h.listCities)
+		r.With(middleware.RequirePermission(middleware.CityWrite)).Put("/{id}", h.updateCity)
+		r.With(middleware.RequirePermission(middleware.CityDelete)).Delete("/{id}", h.deleteCity)
+
+		r.With(middleware.RequirePermission(middleware.CityRead)).Get("/governorates", h.listGovernorates)
+		r.With(middleware.RequirePermission(middleware.CityWrite)).Post("/governorates", h.createGovernorate)
+		r.With(middleware.RequirePermission(middleware.CityWrite)).Put("/governorates/{id}", h.updateGovernorate)
+		r.With(middleware.RequirePermission(middleware.CityDelete)).Delete("/governorates/{id}", h.deleteGovernorate)
+
+		r.With(middleware.RequirePermission(middleware.CityRead)).Get("/{id}/zones", h.listZones)
+		r.With(middleware.RequirePermission(middleware.CityWrite)).Post("/{id}/zones", h.createZone)
+		r.With(middleware.RequirePermission(middleware.CityRead)).Get("/{id}/zones/export", h.exportZones)
+		r.With(middleware.RequirePermission(middleware.CityWrite)).Post("/{id}/zones/import", h.importZones)
+		r.With(middleware.RequirePermission(middleware.CityRead)).Get("/zones/{zoneId}", h.getZone)
+		r.With(middleware.RequirePermission(middleware.CityWrite)).Put("/zones/{zoneId}", h.updateZone)
+		r.With(middleware.RequirePermission(middleware.CityDelete)).Delete("/zones/{zoneId}", h.deleteZone)
 	})
 
 	//public
 	r.With(middleware.Pagination).Get("/active", h.listActiveCities)
+	r.Get("/tree", h.activeCityTree)
+	r.Get("/nearest", h.nearestCity)
+	r.Get("/delivery", h.deliveryAt)
 
 	return r
 }
@@ -1,19 +1,197 @@
 package city
 
+import (
+	"encoding/json"
+	db "github.com/bigusef/texorbit/internal/database"
+	"github.com/bigusef/texorbit/internal/translation"
+	"github.com/jackc/pgx/v5/pgtype"
+	"time"
+)
+
 type cityInput struct {
-	NameEn   string `json:"name_en" validate:"required"`
-	NameAr   string `json:"name_ar" validate:"required"`
-	IsActive *bool  `json:"is_active" validate:"required"`
+	// Names city name by locale, english is required (ex: {"en": "Cairo", "ar": "القاهرة", "fr": "Le Caire"})
+	Names         translation.Names `json:"names" validate:"required,dive,keys,bcp47_language_tag,endkeys,required,max=75"`
+	IsActive      *bool             `json:"is_active" validate:"required"`
+	GovernorateID int64             `json:"governorate_id" validate:"required"`
+
+	// optional city center and GeoJSON Polygon or MultiPolygon boundary
+	Latitude  *float64        `json:"latitude" validate:"required_with=Longitude,omitempty,latitude"`
+	Longitude *float64        `json:"longitude" validate:"required_with=Latitude,omitempty,longitude"`
+	Boundary  json.RawMessage `json:"boundary"`
 }
 
 type cityResponse struct {
-	ID       int64  `json:"id"`
-	NameEn   string `json:"name_en"`
-	NameAr   string `json:"name_ar"`
-	IsActive bool   `json:"is_active"`
+	ID            int64             `json:"id"`
+	Name          string            `json:"name"`
+	Names         translation.Names `json:"names"`
+	IsActive      bool              `json:"is_active"`
+	GovernorateID *int64            `json:"governorate_id"`
+	Latitude      *float64          `json:"latitude"`
+	Longitude     *float64          `json:"longitude"`
+	Boundary      json.RawMessage   `json:"boundary,omitempty"`
 }
 
 type activeCityResponse struct {
-	ID   int64  `json:"id"`
-	Name string `json:"name"`
+	ID            int64  `json:"id"`
+	Name          string `json:"name"`
+	GovernorateID *int64 `json:"governorate_id,omitempty"`
+}
+
+type nearestCityResponse struct {
+	ID            int64   `json:"id"`
+	Name          string  `json:"name"`
+	GovernorateID *int64  `json:"governorate_id,omitempty"`
+	Distance      float64 `json:"distance"`
+}
+
+type governorateInput struct {
+	Names translation.Names `json:"names" validate:"required,dive,keys,bcp47_language_tag,endkeys,required,max=75"`
+}
+
+type governorateResponse struct {
+	ID    int64             `json:"id"`
+	Name  string            `json:"name"`
+	Names translation.Names `json:"names"`
+}
+
+type governorateTree struct {
+	ID     *int64               `json:"id"`
+	Name   string               `json:"name"`
+	Cities []activeCityResponse `json:"cities"`
+}
+
+// newCityResponse city with its names and the name in the request locale
+func newCityResponse(city db.City, names translation.Names, locale string) cityResponse {
+	return cityResponse{
+		ID:            city.ID,
+		Name:          names.Resolve(locale),
+		Names:         names,
+		IsActive:      city.IsActive,
+		GovernorateID: governorateId(city.GovernorateID),
+		Latitude:      optionalFloat(city.Latitude),
+		Longitude:     optionalFloat(city.Longitude),
+		Boundary:      city.Boundary,
+	}
+}
+
+func newGovernorateResponse(id int64, names translation.Names, locale string) governorateResponse {
+	return governorateResponse{ID: id, Name: names.Resolve(locale), Names: names}
+}
+
+func governorateId(id pgtype.Int8) *int64 {
+	if !id.Valid {
+		return nil
+	}
+	return &id.Int64
+}
+
+func optionalFloat(value pgtype.Float8) *float64 {
+	if !value.Valid {
+		return nil
+	}
+	return &value.Float64
+}
+
+func floatParam(value *float64) pgtype.Float8 {
+	if value == nil {
+		return pgtype.Float8{}
+	}
+	return pgtype.Float8{Float64: *value, Valid: true}
+}
+
+type zoneInput struct {
+	Name       string          `json:"name" validate:"required,max=75"`
+	Area       json.RawMessage `json:"area" validate:"required"`
+	Fee        *int32          `json:"fee" validate:"required,min=0"`
+	MinOrder   int32           `json:"min_order" validate:"min=0"`
+	EtaMinutes int32           `json:"eta_minutes" validate:"required,min=1"`
+	IsActive   *bool           `json:"is_active" validate:"required"`
+}
+
+type zoneResponse struct {
+	ID         int64           `json:"id"`
+	CityID     int64           `json:"city_id"`
+	Name       string          `json:"name"`
+	Area       json.RawMessage `json:"area"`
+	Fee        int32           `json:"fee"`
+	MinOrder   int32           `json:"min_order"`
+	EtaMinutes int32           `json:"eta_minutes"`
+	IsActive   bool            `json:"is_active"`
+	UpdatedAt  time.Time       `json:"updated_at"`
+}
+
+type deliveryResponse struct {
+	ZoneID     int64              `json:"zone_id"`
+	ZoneName   string             `json:"zone_name"`
+	City       activeCityResponse `json:"city"`
+	Fee        int32              `json:"fee"`
+	MinOrder   int32              `json:"min_order"`
+	EtaMinutes int32              `json:"eta_minutes"`
+}
+
+// zoneCollection GeoJSON FeatureCollection of delivery zones, used for import and export
+type zoneCollection struct {
+	Type     string        `json:"type"`
+	Features []zoneFeature `json:"features"`
+}
+
+type zoneFeature struct {
+	Type       string          `json:"type"`
+	ID         int64           `json:"id,omitempty"`
+	Geometry   json.RawMessage `json:"geometry"`
+	Properties zoneProperties  `json:"properties"`
+}
+
+type zoneProperties struct {
+	Name       string `json:"name"`
+	Fee        *int32 `json:"fee"`
+	MinOrder   int32  `json:"min_order"`
+	EtaMinutes int32  `json:"eta_minutes"`
+	IsActive   *bool  `json:"is_active,omitempty"`
+}
+
+func newZoneResponse(zone db.DeliveryZone) zoneResponse {
+	return zoneResponse{
+		ID:         zone.ID,
+		CityID:     zone.CityID,
+		Name:       zone.Name,
+		Area:       zone.Area,
+		Fee:        zone.Fee,
+		MinOrder:   zone.MinOrder,
+		EtaMinutes: zone.EtaMinutes,
+		IsActive:   zone.IsActive,
+		UpdatedAt:  zone.UpdatedAt.Time,
+	}
+}
+
+func newZoneFeature(zone db.DeliveryZone) zoneFeature {
+	return zoneFeature{
+		Type:     "Feature",
+		ID:       zone.ID,
+		Geometry: zone.Area,
+		Properties: zoneProperties{
+			Name:       zone.Name,
+			Fee:        &zone.Fee,
+			MinOrder:   zone.MinOrder,
+			EtaMinutes: zone.EtaMinutes,
+			IsActive:   &zone.IsActive,
+		},
+	}
+}
+
+// input convert the feature to zone input, imported zones are active unless stated otherwise
+func (f zoneFeature) input() zoneInput {
+	isActive := true
+	if f.Properties.IsActive != nil {
+		isActive = *f.Properties.IsActive
+	}
+
+	return zoneInput{
+		Name:       f.Properties.Name,
+		Area:       f.Geometry,
+		Fee:        f.Properties.Fee,
+		MinOrder:   f.Properties.MinOrder,
+		EtaMinutes: f.Properties.EtaMinutes,
+		IsActive:   &isActive,
+	}
 }
@@ -0,0 +1,408 @@
+package city
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	db "github.com/bigusef/texorbit/internal/database"
+	"github.com/bigusef/texorbit/internal/translation"
+	"github.com/bigusef/texorbit/pkg/geo"
+	"github.com/bigusef/texorbit/pkg/i18n"
+	"github.com/bigusef/texorbit/pkg/util"
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+	"net/http"
+	"strconv"
+)
+
+// maxImportZones number of features accepted in one import
+const maxImportZones = 500
+
+// CoveringZone return the zone of the city covering the point, zones are expected
+// cheapest first so the cheapest zone wins where zones overlap
+func CoveringZone(zones []db.DeliveryZone, cityId int64, point geo.Point) (db.DeliveryZone, bool) {
+	for _, zone := range zones {
+		if zone.CityID != cityId {
+			continue
+		}
+
+		area, err := geo.ParseGeoJSON(zone.Area)
+		if err == nil && area.Contains(point) {
+			return zone, true
+		}
+	}
+
+	return db.DeliveryZone{}, false
+}
+
+// DeliveryZoneAt return the active delivery zone of the city covering the point
+func DeliveryZoneAt(ctx context.Context, queries *db.Queries, cityId int64, point geo.Point) (db.DeliveryZone, bool, error) {
+	zones, err := queries.ActiveDeliveryZonesByCities(ctx, []int64{cityId})
+	if err != nil {
+		return db.DeliveryZone{}, false, err
+	}
+
+	zone, ok := CoveringZone(zones, cityId, point)
+	return zone, ok, nil
+}
+
+// validateZone return the validation error of the zone input, nil when it is valid
+func (h *cityHandler) validateZone(input zoneInput) *util.Error {
+	if err := h.validate.Struct(input); err != nil {
+		return util.ValidationFailed(err)
+	}
+
+	if _, err := geo.ParseGeoJSON(input.Area); err != nil {
+		return util.Invalid(map[string]string{"area": "geojson"})
+	}
+
+	return nil
+}
+
+// featureField name the zone input fields as sent in the feature i of imported collection,
+// the area is the feature geometry
+func featureField(i int) func(string) string {
+	return func(field string) string {
+		if field == "area" {
+			field = "geometry"
+		}
+		return fmt.Sprintf("features.%d.%s", i, field)
+	}
+}
+
+// cityFromURL return the city of the "id" url parameter
+func (h *cityHandler) cityFromURL(w http.ResponseWriter, r *http.Request) (db.City, bool) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		util.ErrorResponseWriter(w, r, util.BadRequest(err.Error()))
+		return db.City{}, false
+	}
+
+	city, err := h.queries.GetCity(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			util.ErrorResponseWriter(w, r, util.NotFound("City not found"))
+			return city, false
+		}
+
+		util.ErrorResponseWriter(w, r, err)
+		return city, false
+	}
+
+	return city, true
+}
+
+// zoneFromURL return id of the "zoneId" url parameter
+func zoneFromURL(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "zoneId"), 10, 64)
+	if err != nil {
+		util.ErrorResponseWriter(w, r, util.BadRequest("invalid zone id"))
+		return 0, false
+	}
+
+	return id, true
+}
+
+func (h *cityHandler) listZones(w http.ResponseWriter, r *http.Request) {
+	city, ok := h.cityFromURL(w, r)
+	if !ok {
+		return
+	}
+
+	zones, err := h.queries.CityDeliveryZones(r.Context(), city.ID)
+	if err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	response := make([]zoneResponse, len(zones))
+	for i, zone := range zones {
+		response[i] = newZoneResponse(zone)
+	}
+
+	util.JsonListResponseWriter(w, http.StatusOK, response, int64(len(response)))
+}
+
+func (h *cityHandler) createZone(w http.ResponseWriter, r *http.Request) {
+	city, ok := h.cityFromURL(w, r)
+	if !ok {
+		return
+	}
+
+	var input zoneInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		util.ErrorResponseWriter(w, r, util.BadRequest(err.Error()))
+		return
+	}
+	if err := h.validateZone(input); err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	zone, err := h.queries.CreateDeliveryZone(r.Context(), db.CreateDeliveryZoneParams{
+		CityID:     city.ID,
+		Name:       input.Name,
+		Area:       input.Area,
+		Fee:        *input.Fee,
+		MinOrder:   input.MinOrder,
+		EtaMinutes: input.EtaMinutes,
+		IsActive:   *input.IsActive,
+	})
+	if err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	util.JsonResponseWriter(w, http.StatusCreated, newZoneResponse(zone))
+}
+
+func (h *cityHandler) getZone(w http.ResponseWriter, r *http.Request) {
+	id, ok := zoneFromURL(w, r)
+	if !ok {
+		return
+	}
+
+	zone, err := h.queries.GetDeliveryZone(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			util.ErrorResponseWriter(w, r, util.NotFound("Zone not found"))
+			return
+		}
+
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	util.JsonResponseWriter(w, http.StatusOK, newZoneResponse(zone))
+}
+
+func (h *cityHandler) updateZone(w http.ResponseWriter, r *http.Request) {
+	id, ok := zoneFromURL(w, r)
+	if !ok {
+		return
+	}
+
+	var input zoneInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		util.ErrorResponseWriter(w, r, util.BadRequest(err.Error()))
+		return
+	}
+	if err := h.validateZone(input); err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	zone, err := h.queries.UpdateDeliveryZone(r.Context(), db.UpdateDeliveryZoneParams{
+		Name:       input.Name,
+		Area:       input.Area,
+		Fee:        *input.Fee,
+		MinOrder:   input.MinOrder,
+		EtaMinutes: input.EtaMinutes,
+		IsActive:   *input.IsActive,
+		ID:         id,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			util.ErrorResponseWriter(w, r, util.NotFound("Zone not found"))
+			return
+		}
+
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	util.JsonResponseWriter(w, http.StatusOK, newZoneResponse(zone))
+}
+
+func (h *cityHandler) deleteZone(w http.ResponseWriter, r *http.Request) {
+	id, ok := zoneFromURL(w, r)
+	if !ok {
+		return
+	}
+
+	deleted, err := h.queries.DeleteDeliveryZone(r.Context(), id)
+	if err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+	if deleted == 0 {
+		util.ErrorResponseWriter(w, r, util.NotFound("Zone not found"))
+		return
+	}
+
+	util.JsonResponseWriter(w, http.StatusNoContent, nil)
+}
+
+// exportZones download the city zones as GeoJSON FeatureCollection
+func (h *cityHandler) exportZones(w http.ResponseWriter, r *http.Request) {
+	city, ok := h.cityFromURL(w, r)
+	if !ok {
+		return
+	}
+
+	zones, err := h.queries.CityDeliveryZones(r.Context(), city.ID)
+	if err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	collection := zoneCollection{Type: "FeatureCollection", Features: make([]zoneFeature, len(zones))}
+	for i, zone := range zones {
+		collection.Features[i] = newZoneFeature(zone)
+	}
+
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="city-%d-zones.geojson"`, city.ID))
+	util.JsonResponseWriter(w, http.StatusOK, collection)
+}
+
+// importZones create zones from GeoJSON FeatureCollection, features with id of an existing
+// city zone update that zone so exported files can be edited and imported again
+func (h *cityHandler) importZones(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	city, ok := h.cityFromURL(w, r)
+	if !ok {
+		return
+	}
+
+	var collection zoneCollection
+	if err := json.NewDecoder(r.Body).Decode(&collection); err != nil {
+		util.ErrorResponseWriter(w, r, util.BadRequest(err.Error()))
+		return
+	}
+	if collection.Type != "FeatureCollection" || len(collection.Features) == 0 || len(collection.Features) > maxImportZones {
+		util.ErrorResponseWriter(w, r, util.Invalid(map[string]string{"features": "geojson"}))
+		return
+	}
+
+	existing, err := h.queries.CityDeliveryZones(ctx, city.ID)
+	if err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+	cityZones := make(map[int64]bool, len(existing))
+	for _, zone := range existing {
+		cityZones[zone.ID] = true
+	}
+
+	// validate all features before changing any zone
+	invalid := util.Invalid(map[string]string{})
+	for i, feature := range collection.Features {
+		if feature.Type != "Feature" {
+			invalid.Fields[fmt.Sprintf("features.%d.type", i)] = "feature"
+			continue
+		}
+		if feature.ID != 0 && !cityZones[feature.ID] {
+			invalid.Fields[fmt.Sprintf("features.%d.id", i)] = "city_zone"
+		}
+		if err := h.validateZone(feature.input()); err != nil {
+			invalid.Merge(err, featureField(i))
+		}
+	}
+	if len(invalid.Fields) > 0 {
+		util.ErrorResponseWriter(w, r, invalid)
+		return
+	}
+
+	// the features are imported all together or not at all
+	response := make([]zoneResponse, len(collection.Features))
+	err = pgx.BeginFunc(ctx, h.pool, func(tx pgx.Tx) error {
+		queries := h.queries.WithTx(tx)
+		for i, feature := range collection.Features {
+			input := feature.input()
+
+			var zone db.DeliveryZone
+			var err error
+			if feature.ID != 0 {
+				zone, err = queries.UpdateDeliveryZone(ctx, db.UpdateDeliveryZoneParams{
+					Name:       input.Name,
+					Area:       input.Area,
+					Fee:        *input.Fee,
+					MinOrder:   input.MinOrder,
+					EtaMinutes: input.EtaMinutes,
+					IsActive:   *input.IsActive,
+					ID:         feature.ID,
+				})
+			} else {
+				zone, err = queries.CreateDeliveryZone(ctx, db.CreateDeliveryZoneParams{
+					CityID:     city.ID,
+					Name:       input.Name,
+					Area:       input.Area,
+					Fee:        *input.Fee,
+					MinOrder:   input.MinOrder,
+					EtaMinutes: input.EtaMinutes,
+					IsActive:   *input.IsActive,
+				})
+			}
+			if err != nil {
+				return err
+			}
+
+			response[i] = newZoneResponse(zone)
+		}
+		return nil
+	})
+	if err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	util.JsonListResponseWriter(w, http.StatusOK, response, int64(len(response)))
+}
+
+// deliveryAt return the delivery zone serving the location
+func (h *cityHandler) deliveryAt(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	query := r.URL.Query()
+
+	lat, err := strconv.ParseFloat(query.Get("lat"), 64)
+	if err != nil || lat < -90 || lat > 90 {
+		util.ErrorResponseWriter(w, r, util.Invalid(map[string]string{"lat": "latitude"}))
+		return
+	}
+	lng, err := strconv.ParseFloat(query.Get("lng"), 64)
+	if err != nil || lng < -180 || lng > 180 {
+		util.ErrorResponseWriter(w, r, util.Invalid(map[string]string{"lng": "longitude"}))
+		return
+	}
+	point := geo.Point{Lat: lat, Lng: lng}
+
+	city, _, ok, err := Locate(ctx, h.queries, point)
+	if err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+	if !ok {
+		util.ErrorResponseWriter(w, r, util.NotFound("No delivery to this location"))
+		return
+	}
+
+	zone, ok, err := DeliveryZoneAt(ctx, h.queries, city.ID, point)
+	if err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+	if !ok {
+		util.ErrorResponseWriter(w, r, util.NotFound("No delivery to this location"))
+		return
+	}
+
+	names, err := translation.Load(ctx, h.queries, translation.EntityCity, translation.FieldName, []int64{city.ID})
+	if err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	util.JsonResponseWriter(w, http.StatusOK, deliveryResponse{
+		ZoneID:   zone.ID,
+		ZoneName: zone.Name,
+		City: activeCityResponse{
+			ID:            city.ID,
+			Name:          names[city.ID].Resolve(i18n.FromContext(ctx)),
+			GovernorateID: governorateId(city.GovernorateID),
+		},
+		Fee:        zone.Fee,
+		MinOrder:   zone.MinOrder,
+		EtaMinutes: zone.EtaMinutes,
+	})
+}
@@ -0,0 +1,151 @@
+package city
+
+import (
+	"encoding/json"
+	"github.com/bigusef/texorbit/pkg/util"
+	"github.com/go-playground/locales/en"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	enTranslations "github.com/go-playground/validator/v10/translations/en"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const squareArea = `{"type":"Polygon","coordinates":[[[31.2,30.0],[31.3,30.0],[31.3,30.1],[31.2,30.1],[31.2,30.0]]]}`
+
+// newZoneValidator validator with json field names and english messages of the validator tags,
+// the zone rules and the labels of the zone fields
+func newZoneValidator(t *testing.T) *validator.Validate {
+	validate := validator.New(validator.WithRequiredStructEnabled())
+	validate.RegisterTagNameFunc(func(fld reflect.StructField) string {
+		return strings.SplitN(fld.Tag.Get("json"), ",", 2)[0]
+	})
+
+	uni := ut.New(en.New(), en.New())
+	trans, _ := uni.GetTranslator("en")
+	if err := enTranslations.RegisterDefaultTranslations(validate, trans); err != nil {
+		t.Fatal(err)
+	}
+	for key, text := range map[string]string{
+		"rule.geojson":   "{0} must be a GeoJSON Polygon or MultiPolygon",
+		"field.fee":      "Delivery fee",
+		"field.geometry": "Area",
+	} {
+		if err := trans.Add(key, text, false); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	util.RegisterTranslator(uni)
+	t.Cleanup(func() { util.RegisterTranslator(nil) })
+	return validate
+}
+
+// problemOf the errors and messages written for the error
+func problemOf(t *testing.T, err error) (errors, messages map[string]string) {
+	w := httptest.NewRecorder()
+	util.ErrorResponseWriter(w, httptest.NewRequest(http.MethodPost, "/", nil), err)
+
+	var problem struct {
+		Errors   map[string]string `json:"errors"`
+		Messages map[string]string `json:"messages"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&problem); err != nil {
+		t.Fatal(err)
+	}
+	return problem.Errors, problem.Messages
+}
+
+func TestValidateZone(t *testing.T) {
+	h := &cityHandler{validate: newZoneValidator(t)}
+	fee, negative, active := int32(1500), int32(-1), true
+
+	tests := []struct {
+		name         string
+		input        zoneInput
+		wantErrors   map[string]string
+		wantMessages map[string]string
+	}{
+		{
+			name:  "valid zone",
+			input: zoneInput{Name: "Downtown", Area: json.RawMessage(squareArea), Fee: &fee, EtaMinutes: 30, IsActive: &active},
+		},
+		{
+			name:         "negative fee",
+			input:        zoneInput{Name: "Downtown", Area: json.RawMessage(squareArea), Fee: &negative, EtaMinutes: 30, IsActive: &active},
+			wantErrors:   map[string]string{"fee": "min"},
+			wantMessages: map[string]string{"fee": "Delivery fee must be 0 or greater"},
+		},
+		{
+			name:         "area not a polygon",
+			input:        zoneInput{Name: "Downtown", Area: json.RawMessage(`{"type":"Point","coordinates":[31.2,30.0]}`), Fee: &fee, EtaMinutes: 30, IsActive: &active},
+			wantErrors:   map[string]string{"area": "geojson"},
+			wantMessages: map[string]string{"area": "area must be a GeoJSON Polygon or MultiPolygon"},
+		},
+		{
+			name:       "struct errors before area check",
+			input:      zoneInput{Area: json.RawMessage(`{}`), Fee: &fee, IsActive: &active},
+			wantErrors: map[string]string{"name": "required", "eta_minutes": "required"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := h.validateZone(tt.input)
+			if tt.wantErrors == nil {
+				if err != nil {
+					t.Fatalf("validateZone() = %v, want valid", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("validateZone() = nil, want validation error")
+			}
+
+			errors, messages := problemOf(t, err)
+			if !reflect.DeepEqual(errors, tt.wantErrors) {
+				t.Errorf("errors = %v, want %v", errors, tt.wantErrors)
+			}
+			for field, message := range tt.wantMessages {
+				if messages[field] != message {
+					t.Errorf("message of %s = %q, want %q", field, messages[field], message)
+				}
+			}
+			if len(messages) != len(errors) {
+				t.Errorf("messages = %v, want message of every error", messages)
+			}
+		})
+	}
+}
+
+func TestValidateZoneImportFields(t *testing.T) {
+	h := &cityHandler{validate: newZoneValidator(t)}
+	fee, negative, active := int32(1500), int32(-1), true
+
+	features := []zoneInput{
+		{Name: "Downtown", Area: json.RawMessage(squareArea), Fee: &negative, EtaMinutes: 30, IsActive: &active},
+		{Name: "Zamalek", Area: json.RawMessage(`{"type":"Point","coordinates":[31.2,30.0]}`), Fee: &fee, EtaMinutes: 30, IsActive: &active},
+	}
+
+	invalid := util.Invalid(map[string]string{"features.0.id": "city_zone"})
+	for i, feature := range features {
+		if err := h.validateZone(feature); err != nil {
+			invalid.Merge(err, featureField(i))
+		}
+	}
+
+	errors, messages := problemOf(t, invalid)
+	want := map[string]string{"features.0.id": "city_zone", "features.0.fee": "min", "features.1.geometry": "geojson"}
+	if !reflect.DeepEqual(errors, want) {
+		t.Errorf("errors = %v, want %v", errors, want)
+	}
+	if messages["features.0.fee"] != "Delivery fee must be 0 or greater" {
+		t.Errorf("fee message = %q, want the validator message with the field label", messages["features.0.fee"])
+	}
+	if messages["features.1.geometry"] != "Area must be a GeoJSON Polygon or MultiPolygon" {
+		t.Errorf("geometry message = %q, want the rule message with the field label", messages["features.1.geometry"])
+	}
+}
@@ -7,23 +7,27 @@ package database
 
 import (
 	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 const activeCities = `-- name: ActiveCities :many
-SELECT id, name_en, name_ar, is_active
+SELECT id, is_active, governorate_id, latitude, longitude, boundary
 FROM cities
 WHERE is_active = TRUE
+  AND ($3::bigint IS NULL OR governorate_id = $3)
 ORDER BY id
 LIMIT $1 OFFSET $2
 `
 
 type ActiveCitiesParams struct {
-	Limit  int64
-	Offset int64
+	Limit         int64
+	Offset        int64
+	GovernorateID pgtype.Int8
 }
 
 func (q *Queries) ActiveCities(ctx context.Context, arg ActiveCitiesParams) ([]City, error) {
-	rows, err := q.db.Query(ctx, activeCities, arg.Limit, arg.Offset)
+	rows, err := q.db.Query(ctx, activeCities, arg.Limit, arg.Offset, arg.GovernorateID)
 	if err != nil {
 		return nil, err
 	}
@@ -33,9 +37,11 @@ func (q *Queries) ActiveCities(ctx context.Context, arg ActiveCitiesParams) ([]C
 		var i City
 		if err := rows.Scan(
 			&i.ID,
-			&i.NameEn,
-			&i.NameAr,
 			&i.IsActive,
+			&i.GovernorateID,
+			&i.Latitude,
+			&i.Longitude,
+			&i.Boundary,
 		); err != nil {
 			return nil, err
 		}
@@ -51,17 +57,52 @@ const activeCitiesCount = `-- name: ActiveCitiesCount :one
 SELECT COUNT(*)
 FROM cities
 WHERE is_active = TRUE
+  AND ($1::bigint IS NULL OR governorate_id = $1)
 `
 
-func (q *Queries) ActiveCitiesCount(ctx context.Context) (int64, error) {
-	row := q.db.QueryRow(ctx, activeCitiesCount)
+func (q *Queries) ActiveCitiesCount(ctx context.Context, governorateID pgtype.Int8) (int64, error) {
+	row := q.db.QueryRow(ctx, activeCitiesCount, governorateID)
 	var count int64
 	err := row.Scan(&count)
 	return count, err
 }
 
+const allActiveCities = `-- name: AllActiveCities :many
+SELECT id, is_active, governorate_id, latitude, longitude, boundary
+FROM cities
+WHERE is_active = TRUE
+ORDER BY governorate_id, id
+`
+
+func (q *Queries) AllActiveCities(ctx context.Context) ([]City, error) {
+	rows, err := q.db.Query(ctx, allActiveCities)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []City
+	for rows.Next() {
+		var i City
+		if err := rows.Scan(
+			&i.ID,
+			&i.IsActive,
+			&i.GovernorateID,
+			&i.Latitude,
+			&i.Longitude,
+			&i.Boundary,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const allCities = `-- name: AllCities :many
-SELECT id, name_en, name_ar, is_active
+SELECT id, is_active, governorate_id, latitude, longitude, boundary
 FROM cities
 ORDER BY id
 LIMIT $1 OFFSET $2
@@ -83,9 +124,142 @@ func (q *Queries) AllCities(ctx context.Context, arg AllCitiesParams) ([]City, e
 		var i City
 		if err := rows.Scan(
 			&i.ID,
-			&i.NameEn,
-			&i.NameAr,
 			&i.IsActive,
+			&i.GovernorateID,
+			&i.Latitude,
+			&i.Longitude,
+			&i.Boundary,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const citiesAfter = `-- name: CitiesAfter :many
+-- keyset page of cities, optionally filtered by name, following the given id
+SELECT id, is_active, governorate_id, latitude, longitude, boundary
+FROM cities
+WHERE ($1::text IS NULL
+    OR EXISTS (SELECT 1
+               FROM translations
+               WHERE entity = 'city'
+                 AND entity_id = cities.id
+                 AND field = 'name'
+                 AND value ILIKE $1))
+  AND id > $2
+ORDER BY id
+LIMIT $3
+`
+
+type CitiesAfterParams struct {
+	Query    pgtype.Text
+	AfterID  int64
+	PageSize int64
+}
+
+func (q *Queries) CitiesAfter(ctx context.Context, arg CitiesAfterParams) ([]City, error) {
+	rows, err := q.db.Query(ctx, citiesAfter, arg.Query, arg.AfterID, arg.PageSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []City
+	for rows.Next() {
+		var i City
+		if err := rows.Scan(
+			&i.ID,
+			&i.IsActive,
+			&i.GovernorateID,
+			&i.Latitude,
+			&i.Longitude,
+			&i.Boundary,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const citiesBefore = `-- name: CitiesBefore :many
+-- keyset page of cities, optionally filtered by name, preceding the given id in reverse order
+SELECT id, is_active, governorate_id, latitude, longitude, boundary
+FROM cities
+WHERE ($1::text IS NULL
+    OR EXISTS (SELECT 1
+               FROM translations
+               WHERE entity = 'city'
+                 AND entity_id = cities.id
+                 AND field = 'name'
+                 AND value ILIKE $1))
+  AND id < $2
+ORDER BY id DESC
+LIMIT $3
+`
+
+type CitiesBeforeParams struct {
+	Query    pgtype.Text
+	BeforeID int64
+	PageSize int64
+}
+
+func (q *Queries) CitiesBefore(ctx context.Context, arg CitiesBeforeParams) ([]City, error) {
+	rows, err := q.db.Query(ctx, citiesBefore, arg.Query, arg.BeforeID, arg.PageSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []City
+	for rows.Next() {
+		var i City
+		if err := rows.Scan(
+			&i.ID,
+			&i.IsActive,
+			&i.GovernorateID,
+			&i.Latitude,
+			&i.Longitude,
+			&i.Boundary,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const citiesByIds = `-- name: CitiesByIds :many
+SELECT id, is_active, governorate_id, latitude, longitude, boundary
+FROM cities
+WHERE id = ANY ($1::bigint[])
+`
+
+func (q *Queries) CitiesByIds(ctx context.Context, ids []int64) ([]City, error) {
+	rows, err := q.db.Query(ctx, citiesByIds, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []City
+	for rows.Next() {
+		var i City
+		if err := rows.Scan(
+			&i.ID,
+			&i.IsActive,
+			&i.GovernorateID,
+			&i.Latitude,
+			&i.Longitude,
+			&i.Boundary,
 		); err != nil {
 			return nil, err
 		}
@@ -110,25 +284,35 @@ func (q *Queries) CitiesCount(ctx context.Context) (int64, error) {
 }
 
 const createCity = `-- name: CreateCity :one
-INSERT INTO cities(name_en, name_ar, is_active)
-VALUES ($1, $2, $3)
+INSERT INTO cities(is_active, governorate_id, latitude, longitude, boundary)
+VALUES ($1, $2, $3, $4, $5)
 RETURNING id
 `
 
 type CreateCityParams struct {
-	NameEn   string
-	NameAr   string
-	IsActive bool
+	IsActive      bool
+	GovernorateID pgtype.Int8
+	Latitude      pgtype.Float8
+	Longitude     pgtype.Float8
+	Boundary      []byte
 }
 
 func (q *Queries) CreateCity(ctx context.Context, arg CreateCityParams) (int64, error) {
-	row := q.db.QueryRow(ctx, createCity, arg.NameEn, arg.NameAr, arg.IsActive)
+	row := q.db.QueryRow(ctx, createCity,
+		arg.IsActive,
+		arg.GovernorateID,
+		arg.Latitude,
+		arg.Longitude,
+		arg.Boundary,
+	)
 	var id int64
 	err := row.Scan(&id)
 	return id, err
 }
 
 const deleteCity = `-- name: DeleteCity :exec
+WITH deleted_translations AS (
+    DELETE FROM translations WHERE entity = 'city' AND entity_id = $1)
 DELETE
 FROM cities
 WHERE id = $1
@@ -140,10 +324,14 @@ func (q *Queries) DeleteCity(ctx context.Context, id int64) error {
 }
 
 const filterCities = `-- name: FilterCities :many
-SELECT id, name_en, name_ar, is_active
+SELECT id, is_active, governorate_id, latitude, longitude, boundary
 FROM cities
-WHERE name_en ILIKE $3
-   or name_ar ILIKE $3
+WHERE EXISTS (SELECT 1
+              FROM translations
+              WHERE entity = 'city'
+                AND entity_id = cities.id
+                AND field = 'name'
+                AND value ILIKE $3)
 ORDER BY id
 LIMIT $1 OFFSET $2
 `
@@ -165,9 +353,116 @@ func (q *Queries) FilterCities(ctx context.Context, arg FilterCitiesParams) ([]C
 		var i City
 		if err := rows.Scan(
 			&i.ID,
-			&i.NameEn,
-			&i.NameAr,
 			&i.IsActive,
+			&i.GovernorateID,
+			&i.Latitude,
+			&i.Longitude,
+			&i.Boundary,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const filterCitiesCount = `-- name: FilterCitiesCount :one
+SELECT COUNT(*)
+FROM cities
+WHERE EXISTS (SELECT 1
+              FROM translations
+              WHERE entity = 'city'
+                AND entity_id = cities.id
+                AND field = 'name'
+                AND value ILIKE $1)
+`
+
+func (q *Queries) FilterCitiesCount(ctx context.Context, query string) (int64, error) {
+	row := q.db.QueryRow(ctx, filterCitiesCount, query)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const getActiveCity = `-- name: GetActiveCity :one
+SELECT id, is_active, governorate_id, latitude, longitude, boundary
+FROM cities
+WHERE id = $1
+  AND is_active = TRUE
+`
+
+func (q *Queries) GetActiveCity(ctx context.Context, id int64) (City, error) {
+	row := q.db.QueryRow(ctx, getActiveCity, id)
+	var i City
+	err := row.Scan(
+		&i.ID,
+		&i.IsActive,
+		&i.GovernorateID,
+		&i.Latitude,
+		&i.Longitude,
+		&i.Boundary,
+	)
+	return i, err
+}
+
+const getCity = `-- name: GetCity :one
+SELECT id, is_active, governorate_id, latitude, longitude, boundary
+FROM cities
+WHERE id = $1
+`
+
+func (q *Queries) GetCity(ctx context.Context, id int64) (City, error) {
+	row := q.db.QueryRow(ctx, getCity, id)
+	var i City
+	err := row.Scan(
+		&i.ID,
+		&i.IsActive,
+		&i.GovernorateID,
+		&i.Latitude,
+		&i.Longitude,
+		&i.Boundary,
+	)
+	return i, err
+}
+
+const nearestActiveCities = `-- name: NearestActiveCities :many
+-- active cities with location ordered by distance from the point (haversine formula, in meters)
+SELECT id, is_active, governorate_id, latitude, longitude, boundary
+FROM cities
+WHERE is_active = TRUE
+  AND latitude IS NOT NULL
+  AND longitude IS NOT NULL
+ORDER BY 6371008.8 * 2 * asin(least(1, sqrt(
+        power(sin(radians(latitude - $1::float8) / 2), 2) +
+        cos(radians($1::float8)) * cos(radians(latitude)) * power(sin(radians(longitude - $2::float8) / 2), 2))))
+LIMIT $3
+`
+
+type NearestActiveCitiesParams struct {
+	Lat        float64
+	Lng        float64
+	MaxResults int64
+}
+
+func (q *Queries) NearestActiveCities(ctx context.Context, arg NearestActiveCitiesParams) ([]City, error) {
+	rows, err := q.db.Query(ctx, nearestActiveCities, arg.Lat, arg.Lng, arg.MaxResults)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []City
+	for rows.Next() {
+		var i City
+		if err := rows.Scan(
+			&i.ID,
+			&i.IsActive,
+			&i.GovernorateID,
+			&i.Latitude,
+			&i.Longitude,
+			&i.Boundary,
 		); err != nil {
 			return nil, err
 		}
@@ -181,33 +476,41 @@ func (q *Queries) FilterCities(ctx context.Context, arg FilterCitiesParams) ([]C
 
 const updateCity = `-- name: UpdateCity :one
 UPDATE cities
-SET name_en=$1,
-    name_ar=$2,
-    is_active=$3
-WHERE id = $4
-RETURNING id, name_en, name_ar, is_active
+SET is_active=$1,
+    governorate_id=$2,
+    latitude=$3,
+    longitude=$4,
+    boundary=$5
+WHERE id = $6
+RETURNING id, is_active, governorate_id, latitude, longitude, boundary
 `
 
 type UpdateCityParams struct {
-	NameEn   string
-	NameAr   string
-	IsActive bool
-	ID       int64
+	IsActive      bool
+	GovernorateID pgtype.Int8
+	Latitude      pgtype.Float8
+	Longitude     pgtype.Float8
+	Boundary      []byte
+	ID            int64
 }
 
 func (q *Queries) UpdateCity(ctx context.Context, arg UpdateCityParams) (City, error) {
 	row := q.db.QueryRow(ctx, updateCity,
-		arg.NameEn,
-		arg.NameAr,
 		arg.IsActive,
+		arg.GovernorateID,
+		arg.Latitude,
+		arg.Longitude,
+		arg.Boundary,
 		arg.ID,
 	)
 	var i City
 	err := row.Scan(
 		&i.ID,
-		&i.NameEn,
-		&i.NameAr,
 		&i.IsActive,
+		&i.GovernorateID,
+		&i.Latitude,
+		&i.Longitude,
+		&i.Boundary,
 	)
 	return i, err
 }
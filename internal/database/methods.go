@@ -1,9 +1,6 @@
 package database
 
 func (u *User) IsActive() bool {
-	if u.Status == AccountStatusDeleted || u.Status == AccountStatusSuspended {
-		return false
-	}
-
-	return true
+	// pending verification accounts can login to get verified
+	return u.Status == AccountStatusActive || u.Status == AccountStatusPendingVerification
 }
@@ -15,9 +15,10 @@ import (
 type AccountStatus string
 
 const (
-	AccountStatusActive    AccountStatus = "active"
-	AccountStatusSuspended AccountStatus = "suspended"
-	AccountStatusDeleted   AccountStatus = "deleted"
+	AccountStatusActive              AccountStatus = "active"
+	AccountStatusSuspended           AccountStatus = "suspended"
+	AccountStatusDeleted             AccountStatus = "deleted"
+	AccountStatusPendingVerification AccountStatus = "pending_verification"
 )
 
 func (e *AccountStatus) Scan(src interface{}) error {
@@ -55,21 +56,222 @@ func (ns NullAccountStatus) Value() (driver.Value, error) {
 	return string(ns.AccountStatus), nil
 }
 
+type ApiKey struct {
+	ID         uuid.UUID
+	Name       string
+	Prefix     string
+	SecretHash string
+	Scopes     []string
+	CreatedBy  pgtype.UUID
+	CreatedAt  pgtype.Timestamptz
+	ExpiresAt  pgtype.Timestamptz
+	LastUsedAt pgtype.Timestamptz
+	RevokedAt  pgtype.Timestamptz
+}
+
+type AuthRequest struct {
+	State        string
+	Provider     string
+	Nonce        string
+	CodeVerifier string
+	ExpiresAt    pgtype.Timestamptz
+}
+
 type City struct {
+	ID            int64
+	IsActive      bool
+	GovernorateID pgtype.Int8
+	Latitude      pgtype.Float8
+	Longitude     pgtype.Float8
+	Boundary      []byte
+}
+
+type DeliveryZone struct {
+	ID         int64
+	CityID     int64
+	Name       string
+	Area       []byte
+	Fee        int32
+	MinOrder   int32
+	EtaMinutes int32
+	IsActive   bool
+	CreatedAt  pgtype.Timestamptz
+	UpdatedAt  pgtype.Timestamptz
+}
+
+type Governorate struct {
+	ID int64
+}
+
+type Impersonation struct {
+	ID        uuid.UUID
+	StaffID   uuid.UUID
+	UserID    uuid.UUID
+	Reason    string
+	CreatedAt pgtype.Timestamptz
+	ExpiresAt pgtype.Timestamptz
+}
+
+type ImpersonationRequest struct {
+	ID              int64
+	ImpersonationID uuid.UUID
+	Method          string
+	Path            string
+	Status          int32
+	CreatedAt       pgtype.Timestamptz
+}
+
+type LoginEvent struct {
+	ID            int64
+	UserID        pgtype.UUID
+	Email         string
+	Method        string
+	Success       bool
+	FailureReason string
+	IpAddress     string
+	UserAgent     string
+	CreatedAt     pgtype.Timestamptz
+}
+
+type LoginThrottle struct {
+	Key           string
+	Failures      int32
+	LastFailureAt pgtype.Timestamptz
+	LockedUntil   pgtype.Timestamptz
+}
+
+type MagicLink struct {
+	TokenHash  string
+	Email      string
+	CreatedAt  pgtype.Timestamptz
+	ExpiresAt  pgtype.Timestamptz
+	ConsumedAt pgtype.Timestamptz
+}
+
+type MfaRecoveryCode struct {
 	ID       int64
-	NameEn   string
-	NameAr   string
-	IsActive bool
+	UserID   uuid.UUID
+	CodeHash string
+	UsedAt   pgtype.Timestamptz
 }
 
-type User struct {
-	ID          uuid.UUID
+type Permission struct {
+	Code        string
+	Description string
+}
+
+type PhoneOtp struct {
+	ID          int64
+	UserID      uuid.UUID
+	PhoneNumber string
+	CodeHash    string
+	Attempts    int32
+	CreatedAt   pgtype.Timestamptz
+	ExpiresAt   pgtype.Timestamptz
+	ConsumedAt  pgtype.Timestamptz
+}
+
+type RefreshToken struct {
+	TokenHash string
+	SessionID uuid.UUID
+	CreatedAt pgtype.Timestamptz
+	UsedAt    pgtype.Timestamptz
+}
+
+type Role struct {
+	ID          int64
 	Name        string
-	Email       string
-	PhoneNumber pgtype.Text
-	Avatar      pgtype.Text
-	Status      AccountStatus
-	IsStaff     bool
-	JoinDate    pgtype.Timestamptz
-	LastLogin   pgtype.Timestamptz
+	Description string
+}
+
+type RolePermission struct {
+	RoleID     int64
+	Permission string
+}
+
+type SecurityPolicy struct {
+	Key       string
+	Enabled   bool
+	UpdatedAt pgtype.Timestamptz
+}
+
+type Session struct {
+	ID          uuid.UUID
+	UserID      uuid.UUID
+	DeviceID    string
+	CreatedAt   pgtype.Timestamptz
+	LastUsedAt  pgtype.Timestamptz
+	ExpiresAt   pgtype.Timestamptz
+	RevokedAt   pgtype.Timestamptz
+	DeviceLabel string
+	UserAgent   string
+	IpAddress   string
+}
+
+type Translation struct {
+	Entity    string
+	EntityID  int64
+	Field     string
+	Locale    string
+	Value     string
+	UpdatedAt pgtype.Timestamptz
+}
+
+type User struct {
+	ID                  uuid.UUID
+	Name                string
+	Email               string
+	PhoneNumber         pgtype.Text
+	Avatar              pgtype.Text
+	Status              AccountStatus
+	IsStaff             bool
+	JoinDate            pgtype.Timestamptz
+	LastLogin           pgtype.Timestamptz
+	StatusReason        pgtype.Text
+	PhoneVerifiedAt     pgtype.Timestamptz
+	DeletionScheduledAt pgtype.Timestamptz
+	SuspendedUntil      pgtype.Timestamptz
+	AvatarKey           pgtype.Text
+	PreferredLanguage   pgtype.Text
+}
+
+type UserAddress struct {
+	ID        int64
+	UserID    uuid.UUID
+	CityID    int64
+	Label     string
+	Street    string
+	Building  string
+	Floor     string
+	Landmark  string
+	Latitude  pgtype.Float8
+	Longitude pgtype.Float8
+	IsDefault bool
+	CreatedAt pgtype.Timestamptz
+	UpdatedAt pgtype.Timestamptz
+}
+
+type UserMfa struct {
+	UserID         uuid.UUID
+	Secret         string
+	EnabledAt      pgtype.Timestamptz
+	LastCounter    int64
+	FailedAttempts int32
+	CreatedAt      pgtype.Timestamptz
+	FailedAt       pgtype.Timestamptz
+}
+
+type UserRole struct {
+	UserID uuid.UUID
+	RoleID int64
+}
+
+type UserStatusHistory struct {
+	ID         int64
+	UserID     uuid.UUID
+	FromStatus AccountStatus
+	ToStatus   AccountStatus
+	Reason     string
+	ChangedBy  pgtype.UUID
+	CreatedAt  pgtype.Timestamptz
 }
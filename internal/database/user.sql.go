@@ -13,10 +13,10 @@ import (
 )
 
 const allStaff = `-- name: AllStaff :many
-SELECT id, name, email, phone_number, avatar, status, is_staff, join_date, last_login
+SELECT id, name, email, phone_number, avatar, status, is_staff, join_date, last_login, status_reason, phone_verified_at, deletion_scheduled_at, suspended_until, avatar_key, preferred_language
 FROM users
 WHERE is_staff = TRUE
-ORDER BY join_date DESC
+ORDER BY join_date DESC, id DESC
 LIMIT $1 OFFSET $2
 `
 
@@ -44,6 +44,12 @@ func (q *Queries) AllStaff(ctx context.Context, arg AllStaffParams) ([]User, err
 			&i.IsStaff,
 			&i.JoinDate,
 			&i.LastLogin,
+			&i.StatusReason,
+			&i.PhoneVerifiedAt,
+			&i.DeletionScheduledAt,
+			&i.SuspendedUntil,
+			&i.AvatarKey,
+			&i.PreferredLanguage,
 		); err != nil {
 			return nil, err
 		}
@@ -68,10 +74,71 @@ func (q *Queries) AllStaffCount(ctx context.Context) (int64, error) {
 	return count, err
 }
 
+const anonymizeDueUsers = `-- name: AnonymizeDueUsers :many
+WITH anonymized AS (
+    UPDATE users
+        SET name = 'Deleted user',
+            email = 'deleted+' || users.id || '@texorbit.invalid',
+            phone_number = NULL,
+            phone_verified_at = NULL,
+            avatar = NULL,
+            avatar_key = NULL,
+            status = 'deleted',
+            status_reason = 'deleted by account owner',
+            deletion_scheduled_at = NULL,
+            suspended_until = NULL
+        FROM users previous
+        WHERE previous.id = users.id
+          AND users.deletion_scheduled_at <= NOW()
+        RETURNING users.id, previous.status, previous.email, previous.avatar_key),
+     status_history AS (
+         INSERT INTO user_status_history (user_id, from_status, to_status, reason)
+             SELECT id, status, 'deleted', 'deleted by account owner'
+             FROM anonymized
+             WHERE status <> 'deleted'),
+     deleted_sessions AS (
+         DELETE FROM sessions WHERE user_id IN (SELECT id FROM anonymized)),
+     deleted_otps AS (
+         DELETE FROM phone_otps WHERE user_id IN (SELECT id FROM anonymized)),
+     deleted_login_events AS (
+         DELETE FROM login_events WHERE user_id IN (SELECT id FROM anonymized)),
+     deleted_addresses AS (
+         DELETE FROM user_addresses WHERE user_id IN (SELECT id FROM anonymized)),
+     deleted_throttles AS (
+         DELETE FROM login_throttles WHERE key IN (SELECT 'account:' || LOWER(email) FROM anonymized))
+SELECT id, avatar_key
+FROM anonymized
+`
+
+type AnonymizeDueUsersRow struct {
+	ID        uuid.UUID
+	AvatarKey pgtype.Text
+}
+
+func (q *Queries) AnonymizeDueUsers(ctx context.Context) ([]AnonymizeDueUsersRow, error) {
+	rows, err := q.db.Query(ctx, anonymizeDueUsers)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AnonymizeDueUsersRow
+	for rows.Next() {
+		var i AnonymizeDueUsersRow
+		if err := rows.Scan(&i.ID, &i.AvatarKey); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const createUser = `-- name: CreateUser :one
-INSERT INTO users(name, email, phone_number, avatar, is_staff, join_date, last_login)
-VALUES ($1, $2, $3, $4, $5, NOW(), NOW())
-RETURNING id, name, email, phone_number, avatar, status, is_staff, join_date, last_login
+INSERT INTO users(name, email, phone_number, avatar, is_staff, status, join_date, last_login)
+VALUES ($1, $2, $3, $4, $5, $6, NOW(), NOW())
+RETURNING id, name, email, phone_number, avatar, status, is_staff, join_date, last_login, status_reason, phone_verified_at, deletion_scheduled_at, suspended_until, avatar_key, preferred_language
 `
 
 type CreateUserParams struct {
@@ -80,6 +147,7 @@ type CreateUserParams struct {
 	PhoneNumber pgtype.Text
 	Avatar      pgtype.Text
 	IsStaff     bool
+	Status      AccountStatus
 }
 
 func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
@@ -89,6 +157,7 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		arg.PhoneNumber,
 		arg.Avatar,
 		arg.IsStaff,
+		arg.Status,
 	)
 	var i User
 	err := row.Scan(
@@ -101,12 +170,289 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.IsStaff,
 		&i.JoinDate,
 		&i.LastLogin,
+		&i.StatusReason,
+		&i.PhoneVerifiedAt,
+		&i.DeletionScheduledAt,
+		&i.SuspendedUntil,
+		&i.AvatarKey,
+		&i.PreferredLanguage,
+	)
+	return i, err
+}
+
+const filterCustomers = `-- name: FilterCustomers :many
+SELECT id, name, email, phone_number, avatar, status, is_staff, join_date, last_login, status_reason, phone_verified_at, deletion_scheduled_at, suspended_until, avatar_key, preferred_language
+FROM users
+WHERE is_staff = FALSE
+  AND ($3::account_status IS NULL OR status = $3)
+  AND ($4::timestamptz IS NULL OR join_date >= $4)
+  AND ($5::timestamptz IS NULL OR join_date < $5)
+  AND ($6::text IS NULL
+    OR name ILIKE $6
+    OR email ILIKE $6
+    OR phone_number ILIKE $6)
+ORDER BY join_date DESC, id DESC
+LIMIT $1 OFFSET $2
+`
+
+type FilterCustomersParams struct {
+	Limit      int64
+	Offset     int64
+	Status     NullAccountStatus
+	JoinedFrom pgtype.Timestamptz
+	JoinedTo   pgtype.Timestamptz
+	Query      pgtype.Text
+}
+
+func (q *Queries) FilterCustomers(ctx context.Context, arg FilterCustomersParams) ([]User, error) {
+	rows, err := q.db.Query(ctx, filterCustomers,
+		arg.Limit,
+		arg.Offset,
+		arg.Status,
+		arg.JoinedFrom,
+		arg.JoinedTo,
+		arg.Query,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []User
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Email,
+			&i.PhoneNumber,
+			&i.Avatar,
+			&i.Status,
+			&i.IsStaff,
+			&i.JoinDate,
+			&i.LastLogin,
+			&i.StatusReason,
+			&i.PhoneVerifiedAt,
+			&i.DeletionScheduledAt,
+			&i.SuspendedUntil,
+			&i.AvatarKey,
+			&i.PreferredLanguage,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const filterCustomersAfter = `-- name: FilterCustomersAfter :many
+-- keyset page of customers following the row with the given join date and id
+SELECT id, name, email, phone_number, avatar, status, is_staff, join_date, last_login, status_reason, phone_verified_at, deletion_scheduled_at, suspended_until, avatar_key, preferred_language
+FROM users
+WHERE is_staff = FALSE
+  AND ($1::account_status IS NULL OR status = $1)
+  AND ($2::timestamptz IS NULL OR join_date >= $2)
+  AND ($3::timestamptz IS NULL OR join_date < $3)
+  AND ($4::text IS NULL
+    OR name ILIKE $4
+    OR email ILIKE $4
+    OR phone_number ILIKE $4)
+  AND (join_date, id) < ($5::timestamptz, $6::uuid)
+ORDER BY join_date DESC, id DESC
+LIMIT $7
+`
+
+type FilterCustomersAfterParams struct {
+	Status        NullAccountStatus
+	JoinedFrom    pgtype.Timestamptz
+	JoinedTo      pgtype.Timestamptz
+	Query         pgtype.Text
+	AfterJoinDate pgtype.Timestamptz
+	AfterID       uuid.UUID
+	PageSize      int64
+}
+
+func (q *Queries) FilterCustomersAfter(ctx context.Context, arg FilterCustomersAfterParams) ([]User, error) {
+	rows, err := q.db.Query(ctx, filterCustomersAfter,
+		arg.Status,
+		arg.JoinedFrom,
+		arg.JoinedTo,
+		arg.Query,
+		arg.AfterJoinDate,
+		arg.AfterID,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []User
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Email,
+			&i.PhoneNumber,
+			&i.Avatar,
+			&i.Status,
+			&i.IsStaff,
+			&i.JoinDate,
+			&i.LastLogin,
+			&i.StatusReason,
+			&i.PhoneVerifiedAt,
+			&i.DeletionScheduledAt,
+			&i.SuspendedUntil,
+			&i.AvatarKey,
+			&i.PreferredLanguage,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const filterCustomersBefore = `-- name: FilterCustomersBefore :many
+-- keyset page of customers preceding the row with the given join date and id, in reverse order
+SELECT id, name, email, phone_number, avatar, status, is_staff, join_date, last_login, status_reason, phone_verified_at, deletion_scheduled_at, suspended_until, avatar_key, preferred_language
+FROM users
+WHERE is_staff = FALSE
+  AND ($1::account_status IS NULL OR status = $1)
+  AND ($2::timestamptz IS NULL OR join_date >= $2)
+  AND ($3::timestamptz IS NULL OR join_date < $3)
+  AND ($4::text IS NULL
+    OR name ILIKE $4
+    OR email ILIKE $4
+    OR phone_number ILIKE $4)
+  AND (join_date, id) > ($5::timestamptz, $6::uuid)
+ORDER BY join_date, id
+LIMIT $7
+`
+
+type FilterCustomersBeforeParams struct {
+	Status         NullAccountStatus
+	JoinedFrom     pgtype.Timestamptz
+	JoinedTo       pgtype.Timestamptz
+	Query          pgtype.Text
+	BeforeJoinDate pgtype.Timestamptz
+	BeforeID       uuid.UUID
+	PageSize       int64
+}
+
+func (q *Queries) FilterCustomersBefore(ctx context.Context, arg FilterCustomersBeforeParams) ([]User, error) {
+	rows, err := q.db.Query(ctx, filterCustomersBefore,
+		arg.Status,
+		arg.JoinedFrom,
+		arg.JoinedTo,
+		arg.Query,
+		arg.BeforeJoinDate,
+		arg.BeforeID,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []User
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Email,
+			&i.PhoneNumber,
+			&i.Avatar,
+			&i.Status,
+			&i.IsStaff,
+			&i.JoinDate,
+			&i.LastLogin,
+			&i.StatusReason,
+			&i.PhoneVerifiedAt,
+			&i.DeletionScheduledAt,
+			&i.SuspendedUntil,
+			&i.AvatarKey,
+			&i.PreferredLanguage,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const filterCustomersCount = `-- name: FilterCustomersCount :one
+SELECT COUNT(*)
+FROM users
+WHERE is_staff = FALSE
+  AND ($1::account_status IS NULL OR status = $1)
+  AND ($2::timestamptz IS NULL OR join_date >= $2)
+  AND ($3::timestamptz IS NULL OR join_date < $3)
+  AND ($4::text IS NULL
+    OR name ILIKE $4
+    OR email ILIKE $4
+    OR phone_number ILIKE $4)
+`
+
+type FilterCustomersCountParams struct {
+	Status     NullAccountStatus
+	JoinedFrom pgtype.Timestamptz
+	JoinedTo   pgtype.Timestamptz
+	Query      pgtype.Text
+}
+
+func (q *Queries) FilterCustomersCount(ctx context.Context, arg FilterCustomersCountParams) (int64, error) {
+	row := q.db.QueryRow(ctx, filterCustomersCount,
+		arg.Status,
+		arg.JoinedFrom,
+		arg.JoinedTo,
+		arg.Query,
+	)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const getCustomerById = `-- name: GetCustomerById :one
+SELECT id, name, email, phone_number, avatar, status, is_staff, join_date, last_login, status_reason, phone_verified_at, deletion_scheduled_at, suspended_until, avatar_key, preferred_language
+FROM users
+WHERE id = $1
+  AND is_staff = FALSE
+`
+
+func (q *Queries) GetCustomerById(ctx context.Context, id uuid.UUID) (User, error) {
+	row := q.db.QueryRow(ctx, getCustomerById, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Email,
+		&i.PhoneNumber,
+		&i.Avatar,
+		&i.Status,
+		&i.IsStaff,
+		&i.JoinDate,
+		&i.LastLogin,
+		&i.StatusReason,
+		&i.PhoneVerifiedAt,
+		&i.DeletionScheduledAt,
+		&i.SuspendedUntil,
+		&i.AvatarKey,
+		&i.PreferredLanguage,
 	)
 	return i, err
 }
 
 const getUSerById = `-- name: GetUSerById :one
-SELECT id, name, email, phone_number, avatar, status, is_staff, join_date, last_login
+SELECT id, name, email, phone_number, avatar, status, is_staff, join_date, last_login, status_reason, phone_verified_at, deletion_scheduled_at, suspended_until, avatar_key, preferred_language
 FROM users
 WHERE id = $1
 `
@@ -124,14 +470,23 @@ func (q *Queries) GetUSerById(ctx context.Context, id uuid.UUID) (User, error) {
 		&i.IsStaff,
 		&i.JoinDate,
 		&i.LastLogin,
+		&i.StatusReason,
+		&i.PhoneVerifiedAt,
+		&i.DeletionScheduledAt,
+		&i.SuspendedUntil,
+		&i.AvatarKey,
+		&i.PreferredLanguage,
 	)
 	return i, err
 }
 
 const getUserByEmail = `-- name: GetUserByEmail :one
-Select id, name, email, phone_number, avatar, status, is_staff, join_date, last_login
+-- emails are compared case-insensitively, accounts created before they were stored lowercase included
+Select id, name, email, phone_number, avatar, status, is_staff, join_date, last_login, status_reason, phone_verified_at, deletion_scheduled_at, suspended_until, avatar_key, preferred_language
 FROM users
-WHERE email = $1
+WHERE lower(email) = lower($1)
+ORDER BY join_date
+LIMIT 1
 `
 
 func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error) {
@@ -147,18 +502,364 @@ func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error
 		&i.IsStaff,
 		&i.JoinDate,
 		&i.LastLogin,
+		&i.StatusReason,
+		&i.PhoneVerifiedAt,
+		&i.DeletionScheduledAt,
+		&i.SuspendedUntil,
+		&i.AvatarKey,
+		&i.PreferredLanguage,
+	)
+	return i, err
+}
+
+const lockUser = `-- name: LockUser :exec
+-- serialize the writes limited per user, held until the transaction ends
+SELECT id
+FROM users
+WHERE id = $1
+    FOR UPDATE
+`
+
+func (q *Queries) LockUser(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.Exec(ctx, lockUser, id)
+	return err
+}
+
+const recordUserLogin = `-- name: RecordUserLogin :one
+UPDATE users
+SET last_login            = NOW(),
+    name                  = COALESCE($1, name),
+    -- uploaded avatar takes precedence over the identity provider picture
+    avatar                = CASE WHEN avatar_key IS NULL THEN COALESCE($2, avatar) ELSE avatar END,
+    -- login within the grace period cancel the requested deletion
+    deletion_scheduled_at = NULL
+WHERE id = $3
+RETURNING id, name, email, phone_number, avatar, status, is_staff, join_date, last_login, status_reason, phone_verified_at, deletion_scheduled_at, suspended_until, avatar_key, preferred_language
+`
+
+type RecordUserLoginParams struct {
+	Name   pgtype.Text
+	Avatar pgtype.Text
+	ID     uuid.UUID
+}
+
+func (q *Queries) RecordUserLogin(ctx context.Context, arg RecordUserLoginParams) (User, error) {
+	row := q.db.QueryRow(ctx, recordUserLogin, arg.Name, arg.Avatar, arg.ID)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Email,
+		&i.PhoneNumber,
+		&i.Avatar,
+		&i.Status,
+		&i.IsStaff,
+		&i.JoinDate,
+		&i.LastLogin,
+		&i.StatusReason,
+		&i.PhoneVerifiedAt,
+		&i.DeletionScheduledAt,
+		&i.SuspendedUntil,
+		&i.AvatarKey,
+		&i.PreferredLanguage,
+	)
+	return i, err
+}
+
+const scheduleUserDeletion = `-- name: ScheduleUserDeletion :one
+UPDATE users
+SET deletion_scheduled_at = $1
+WHERE id = $2
+RETURNING id, name, email, phone_number, avatar, status, is_staff, join_date, last_login, status_reason, phone_verified_at, deletion_scheduled_at, suspended_until, avatar_key, preferred_language
+`
+
+type ScheduleUserDeletionParams struct {
+	DeletionScheduledAt pgtype.Timestamptz
+	ID                  uuid.UUID
+}
+
+func (q *Queries) ScheduleUserDeletion(ctx context.Context, arg ScheduleUserDeletionParams) (User, error) {
+	row := q.db.QueryRow(ctx, scheduleUserDeletion, arg.DeletionScheduledAt, arg.ID)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Email,
+		&i.PhoneNumber,
+		&i.Avatar,
+		&i.Status,
+		&i.IsStaff,
+		&i.JoinDate,
+		&i.LastLogin,
+		&i.StatusReason,
+		&i.PhoneVerifiedAt,
+		&i.DeletionScheduledAt,
+		&i.SuspendedUntil,
+		&i.AvatarKey,
+		&i.PreferredLanguage,
+	)
+	return i, err
+}
+
+const setUserAvatar = `-- name: SetUserAvatar :one
+UPDATE users
+SET avatar     = $1,
+    avatar_key = $2
+WHERE id = $3
+RETURNING id, name, email, phone_number, avatar, status, is_staff, join_date, last_login, status_reason, phone_verified_at, deletion_scheduled_at, suspended_until, avatar_key, preferred_language
+`
+
+type SetUserAvatarParams struct {
+	Avatar    pgtype.Text
+	AvatarKey pgtype.Text
+	ID        uuid.UUID
+}
+
+func (q *Queries) SetUserAvatar(ctx context.Context, arg SetUserAvatarParams) (User, error) {
+	row := q.db.QueryRow(ctx, setUserAvatar, arg.Avatar, arg.AvatarKey, arg.ID)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Email,
+		&i.PhoneNumber,
+		&i.Avatar,
+		&i.Status,
+		&i.IsStaff,
+		&i.JoinDate,
+		&i.LastLogin,
+		&i.StatusReason,
+		&i.PhoneVerifiedAt,
+		&i.DeletionScheduledAt,
+		&i.SuspendedUntil,
+		&i.AvatarKey,
+		&i.PreferredLanguage,
+	)
+	return i, err
+}
+
+const setUserPhoneVerified = `-- name: SetUserPhoneVerified :one
+UPDATE users
+SET phone_number      = $1,
+    phone_verified_at = NOW()
+WHERE id = $2
+RETURNING id, name, email, phone_number, avatar, status, is_staff, join_date, last_login, status_reason, phone_verified_at, deletion_scheduled_at, suspended_until, avatar_key, preferred_language
+`
+
+type SetUserPhoneVerifiedParams struct {
+	PhoneNumber pgtype.Text
+	ID          uuid.UUID
+}
+
+func (q *Queries) SetUserPhoneVerified(ctx context.Context, arg SetUserPhoneVerifiedParams) (User, error) {
+	row := q.db.QueryRow(ctx, setUserPhoneVerified, arg.PhoneNumber, arg.ID)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Email,
+		&i.PhoneNumber,
+		&i.Avatar,
+		&i.Status,
+		&i.IsStaff,
+		&i.JoinDate,
+		&i.LastLogin,
+		&i.StatusReason,
+		&i.PhoneVerifiedAt,
+		&i.DeletionScheduledAt,
+		&i.SuspendedUntil,
+		&i.AvatarKey,
+		&i.PreferredLanguage,
+	)
+	return i, err
+}
+
+const setUserPreferredLanguage = `-- name: SetUserPreferredLanguage :one
+UPDATE users
+SET preferred_language = $1
+WHERE id = $2
+RETURNING id, name, email, phone_number, avatar, status, is_staff, join_date, last_login, status_reason, phone_verified_at, deletion_scheduled_at, suspended_until, avatar_key, preferred_language
+`
+
+type SetUserPreferredLanguageParams struct {
+	PreferredLanguage pgtype.Text
+	ID                uuid.UUID
+}
+
+func (q *Queries) SetUserPreferredLanguage(ctx context.Context, arg SetUserPreferredLanguageParams) (User, error) {
+	row := q.db.QueryRow(ctx, setUserPreferredLanguage, arg.PreferredLanguage, arg.ID)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Email,
+		&i.PhoneNumber,
+		&i.Avatar,
+		&i.Status,
+		&i.IsStaff,
+		&i.JoinDate,
+		&i.LastLogin,
+		&i.StatusReason,
+		&i.PhoneVerifiedAt,
+		&i.DeletionScheduledAt,
+		&i.SuspendedUntil,
+		&i.AvatarKey,
+		&i.PreferredLanguage,
+	)
+	return i, err
+}
+
+const staffAfter = `-- name: StaffAfter :many
+-- keyset page of staff following the row with the given join date and id
+SELECT id, name, email, phone_number, avatar, status, is_staff, join_date, last_login, status_reason, phone_verified_at, deletion_scheduled_at, suspended_until, avatar_key, preferred_language
+FROM users
+WHERE is_staff = TRUE
+  AND (join_date, id) < ($1::timestamptz, $2::uuid)
+ORDER BY join_date DESC, id DESC
+LIMIT $3
+`
+
+type StaffAfterParams struct {
+	AfterJoinDate pgtype.Timestamptz
+	AfterID       uuid.UUID
+	PageSize      int64
+}
+
+func (q *Queries) StaffAfter(ctx context.Context, arg StaffAfterParams) ([]User, error) {
+	rows, err := q.db.Query(ctx, staffAfter, arg.AfterJoinDate, arg.AfterID, arg.PageSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []User
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Email,
+			&i.PhoneNumber,
+			&i.Avatar,
+			&i.Status,
+			&i.IsStaff,
+			&i.JoinDate,
+			&i.LastLogin,
+			&i.StatusReason,
+			&i.PhoneVerifiedAt,
+			&i.DeletionScheduledAt,
+			&i.SuspendedUntil,
+			&i.AvatarKey,
+			&i.PreferredLanguage,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const staffBefore = `-- name: StaffBefore :many
+-- keyset page of staff preceding the row with the given join date and id, in reverse order
+SELECT id, name, email, phone_number, avatar, status, is_staff, join_date, last_login, status_reason, phone_verified_at, deletion_scheduled_at, suspended_until, avatar_key, preferred_language
+FROM users
+WHERE is_staff = TRUE
+  AND (join_date, id) > ($1::timestamptz, $2::uuid)
+ORDER BY join_date, id
+LIMIT $3
+`
+
+type StaffBeforeParams struct {
+	BeforeJoinDate pgtype.Timestamptz
+	BeforeID       uuid.UUID
+	PageSize       int64
+}
+
+func (q *Queries) StaffBefore(ctx context.Context, arg StaffBeforeParams) ([]User, error) {
+	rows, err := q.db.Query(ctx, staffBefore, arg.BeforeJoinDate, arg.BeforeID, arg.PageSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []User
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Email,
+			&i.PhoneNumber,
+			&i.Avatar,
+			&i.Status,
+			&i.IsStaff,
+			&i.JoinDate,
+			&i.LastLogin,
+			&i.StatusReason,
+			&i.PhoneVerifiedAt,
+			&i.DeletionScheduledAt,
+			&i.SuspendedUntil,
+			&i.AvatarKey,
+			&i.PreferredLanguage,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateCustomer = `-- name: UpdateCustomer :one
+UPDATE users
+SET name              = $2,
+    phone_number      = $3,
+    phone_verified_at = CASE WHEN phone_number IS DISTINCT FROM $3 THEN NULL ELSE phone_verified_at END
+WHERE id = $1
+  AND is_staff = FALSE
+RETURNING id, name, email, phone_number, avatar, status, is_staff, join_date, last_login, status_reason, phone_verified_at, deletion_scheduled_at, suspended_until, avatar_key, preferred_language
+`
+
+type UpdateCustomerParams struct {
+	ID          uuid.UUID
+	Name        string
+	PhoneNumber pgtype.Text
+}
+
+func (q *Queries) UpdateCustomer(ctx context.Context, arg UpdateCustomerParams) (User, error) {
+	row := q.db.QueryRow(ctx, updateCustomer, arg.ID, arg.Name, arg.PhoneNumber)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Email,
+		&i.PhoneNumber,
+		&i.Avatar,
+		&i.Status,
+		&i.IsStaff,
+		&i.JoinDate,
+		&i.LastLogin,
+		&i.StatusReason,
+		&i.PhoneVerifiedAt,
+		&i.DeletionScheduledAt,
+		&i.SuspendedUntil,
+		&i.AvatarKey,
+		&i.PreferredLanguage,
 	)
 	return i, err
 }
 
 const updateUser = `-- name: UpdateUser :one
 UPDATE users
-SET name         = $2,
-    email        = $3,
-    phone_number = $4,
-    status       = $5
+SET name              = $2,
+    email             = $3,
+    phone_number      = $4,
+    phone_verified_at = CASE WHEN phone_number IS DISTINCT FROM $4 THEN NULL ELSE phone_verified_at END
 WHERE id = $1
-RETURNING id, name, email, phone_number, avatar, status, is_staff, join_date, last_login
+RETURNING id, name, email, phone_number, avatar, status, is_staff, join_date, last_login, status_reason, phone_verified_at, deletion_scheduled_at, suspended_until, avatar_key, preferred_language
 `
 
 type UpdateUserParams struct {
@@ -166,7 +867,6 @@ type UpdateUserParams struct {
 	Name        string
 	Email       string
 	PhoneNumber pgtype.Text
-	Status      AccountStatus
 }
 
 func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error) {
@@ -175,7 +875,6 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, e
 		arg.Name,
 		arg.Email,
 		arg.PhoneNumber,
-		arg.Status,
 	)
 	var i User
 	err := row.Scan(
@@ -188,6 +887,50 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, e
 		&i.IsStaff,
 		&i.JoinDate,
 		&i.LastLogin,
+		&i.StatusReason,
+		&i.PhoneVerifiedAt,
+		&i.DeletionScheduledAt,
+		&i.SuspendedUntil,
+		&i.AvatarKey,
+		&i.PreferredLanguage,
+	)
+	return i, err
+}
+
+const updateUserProfile = `-- name: UpdateUserProfile :one
+UPDATE users
+SET name              = $2,
+    phone_number      = $3,
+    phone_verified_at = CASE WHEN phone_number IS DISTINCT FROM $3 THEN NULL ELSE phone_verified_at END
+WHERE id = $1
+RETURNING id, name, email, phone_number, avatar, status, is_staff, join_date, last_login, status_reason, phone_verified_at, deletion_scheduled_at, suspended_until, avatar_key, preferred_language
+`
+
+type UpdateUserProfileParams struct {
+	ID          uuid.UUID
+	Name        string
+	PhoneNumber pgtype.Text
+}
+
+func (q *Queries) UpdateUserProfile(ctx context.Context, arg UpdateUserProfileParams) (User, error) {
+	row := q.db.QueryRow(ctx, updateUserProfile, arg.ID, arg.Name, arg.PhoneNumber)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Email,
+		&i.PhoneNumber,
+		&i.Avatar,
+		&i.Status,
+		&i.IsStaff,
+		&i.JoinDate,
+		&i.LastLogin,
+		&i.StatusReason,
+		&i.PhoneVerifiedAt,
+		&i.DeletionScheduledAt,
+		&i.SuspendedUntil,
+		&i.AvatarKey,
+		&i.PreferredLanguage,
 	)
 	return i, err
 }
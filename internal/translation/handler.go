@@ -0,0 +1,153 @@
+package translation
+
+import (
+	"encoding/json"
+	"fmt"
+	db "github.com/bigusef/texorbit/internal/database"
+	"github.com/bigusef/texorbit/pkg/config"
+	"github.com/bigusef/texorbit/pkg/i18n"
+	"github.com/bigusef/texorbit/pkg/util"
+	"github.com/go-playground/validator/v10"
+	"github.com/jackc/pgx/v5/pgtype"
+	"net/http"
+)
+
+// maxImportRows number of translations accepted in one import
+const maxImportRows = 5000
+
+type translationHandler struct {
+	conf     *config.Setting
+	queries  *db.Queries
+	validate *validator.Validate
+}
+
+// exportTranslations download translations, optionally of one entity or one locale
+func (h *translationHandler) exportTranslations(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	filter := db.FilterTranslationsParams{}
+	if entity := query.Get("entity"); entity != "" {
+		if _, ok := fields[entity]; !ok {
+			util.ErrorResponseWriter(w, r, util.Invalid(map[string]string{"entity": "oneof"}))
+			return
+		}
+		filter.Entity = pgtype.Text{String: entity, Valid: true}
+	}
+	if locale := query.Get("locale"); locale != "" {
+		canonical, ok := i18n.Canonical(locale)
+		if !ok {
+			util.ErrorResponseWriter(w, r, util.Invalid(map[string]string{"locale": "bcp47_language_tag"}))
+			return
+		}
+		filter.Locale = pgtype.Text{String: canonical, Valid: true}
+	}
+
+	translations, err := h.queries.FilterTranslations(r.Context(), filter)
+	if err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	file := translationFile{Translations: make([]translationRow, len(translations))}
+	for i, translation := range translations {
+		file.Translations[i] = newTranslationRow(translation)
+	}
+
+	w.Header().Set("Content-Disposition", `attachment; filename="translations.json"`)
+	util.JsonResponseWriter(w, http.StatusOK, file)
+}
+
+// rowField name the fields of the row i of imported file (ex: value is translations.0.value)
+func rowField(i int) func(string) string {
+	return func(field string) string {
+		return fmt.Sprintf("translations.%d.%s", i, field)
+	}
+}
+
+// importTranslations add or replace translations of existing entities, all rows are validated
+// before any of them is written and they are written by single statement
+func (h *translationHandler) importTranslations(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var file translationFile
+	if err := json.NewDecoder(r.Body).Decode(&file); err != nil {
+		util.ErrorResponseWriter(w, r, util.BadRequest(err.Error()))
+		return
+	}
+	if len(file.Translations) == 0 || len(file.Translations) > maxImportRows {
+		util.ErrorResponseWriter(w, r, util.Invalid(map[string]string{"translations": "invalid"}))
+		return
+	}
+
+	invalid := util.Invalid(map[string]string{})
+	ids := map[string][]int64{}
+	valid := make([]bool, len(file.Translations))
+	for i, row := range file.Translations {
+		if err := h.validate.Struct(row); err != nil {
+			invalid.Merge(util.ValidationFailed(err), rowField(i))
+			continue
+		}
+		if !IsTranslatable(row.Entity, row.Field) {
+			invalid.Fields[rowField(i)("field")] = "invalid"
+			continue
+		}
+
+		row.Locale, _ = i18n.Canonical(row.Locale)
+		if row.Locale == i18n.Arabic && h.validate.Var(row.Value, "arabic_text") != nil {
+			invalid.Fields[rowField(i)("value")] = "arabic_text"
+		}
+		file.Translations[i], valid[i] = row, true
+		ids[row.Entity] = append(ids[row.Entity], row.EntityID)
+	}
+
+	// translations have no foreign keys, so the entities are checked here
+	existing := map[string]map[int64]bool{}
+	for entity, entityIds := range ids {
+		found, err := h.queries.ExistingEntityIds(ctx, db.ExistingEntityIdsParams{Entity: entity, Ids: entityIds})
+		if err != nil {
+			util.ErrorResponseWriter(w, r, err)
+			return
+		}
+
+		existing[entity] = make(map[int64]bool, len(found))
+		for _, id := range found {
+			existing[entity][id] = true
+		}
+	}
+
+	// rows repeating the same translation keep the last value, upsert can not change a row twice
+	type rowKey struct {
+		entity, field, locale string
+		id                    int64
+	}
+	var rows batch
+	index := map[rowKey]int{}
+	for i, row := range file.Translations {
+		if !valid[i] {
+			continue
+		}
+		if !existing[row.Entity][row.EntityID] {
+			invalid.Fields[rowField(i)("entity_id")] = "exists"
+			continue
+		}
+
+		key := rowKey{entity: row.Entity, field: row.Field, locale: row.Locale, id: row.EntityID}
+		if at, ok := index[key]; ok {
+			rows.Vals[at] = row.Value
+			continue
+		}
+		index[key] = len(rows.Vals)
+		rows.add(row.Entity, row.EntityID, row.Field, row.Locale, row.Value)
+	}
+	if len(invalid.Fields) > 0 {
+		util.ErrorResponseWriter(w, r, invalid)
+		return
+	}
+
+	if err := h.queries.UpsertTranslations(ctx, db.UpsertTranslationsParams(rows)); err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	util.JsonResponseWriter(w, http.StatusOK, importResponse{Imported: len(rows.Vals)})
+}
@@ -0,0 +1,234 @@
+package translation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	db "github.com/bigusef/texorbit/internal/database"
+	"github.com/bigusef/texorbit/pkg/i18n"
+	"github.com/bigusef/texorbit/pkg/util"
+	"github.com/go-playground/locales/ar"
+	"github.com/go-playground/locales/en"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	arTranslations "github.com/go-playground/validator/v10/translations/ar"
+	enTranslations "github.com/go-playground/validator/v10/translations/en"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"unicode"
+)
+
+// fakeDB answer ExistingEntityIds from the existing ids of every entity and record the upserted rows
+type fakeDB struct {
+	existing map[string][]int64
+	upserted [][]interface{}
+}
+
+func (f *fakeDB) Exec(_ context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	if !strings.HasPrefix(sql, "-- name: UpsertTranslations ") {
+		return pgconn.CommandTag{}, errors.New("unexpected exec")
+	}
+	f.upserted = append(f.upserted, args)
+	return pgconn.NewCommandTag("INSERT 0 1"), nil
+}
+
+func (f *fakeDB) Query(_ context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	if !strings.HasPrefix(sql, "-- name: ExistingEntityIds ") {
+		return nil, errors.New("unexpected query")
+	}
+
+	var found []int64
+	for _, id := range args[1].([]int64) {
+		for _, existing := range f.existing[args[0].(string)] {
+			if id == existing {
+				found = append(found, id)
+			}
+		}
+	}
+	return &idRows{ids: found, index: -1}, nil
+}
+
+func (f *fakeDB) QueryRow(context.Context, string, ...interface{}) pgx.Row {
+	return nil
+}
+
+// idRows rows of single id column
+type idRows struct {
+	pgx.Rows
+	ids   []int64
+	index int
+}
+
+func (r *idRows) Next() bool {
+	r.index++
+	return r.index < len(r.ids)
+}
+
+func (r *idRows) Scan(dest ...interface{}) error {
+	*dest[0].(*int64) = r.ids[r.index]
+	return nil
+}
+
+func (r *idRows) Close()     {}
+func (r *idRows) Err() error { return nil }
+
+// newTestValidator validator with json field names, the arabic_text tag and english and arabic messages
+// of the validator tags, the import rules and the row field labels
+func newTestValidator(t *testing.T) *validator.Validate {
+	validate := validator.New(validator.WithRequiredStructEnabled())
+	validate.RegisterTagNameFunc(func(fld reflect.StructField) string {
+		return strings.SplitN(fld.Tag.Get("json"), ",", 2)[0]
+	})
+	err := validate.RegisterValidation("arabic_text", func(fl validator.FieldLevel) bool {
+		for _, c := range fl.Field().String() {
+			if !unicode.Is(unicode.Arabic, c) && !unicode.IsSpace(c) {
+				return false
+			}
+		}
+		return true
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	uni := ut.New(en.New(), en.New(), ar.New())
+	enTrans, _ := uni.GetTranslator("en")
+	arTrans, _ := uni.GetTranslator("ar")
+	if err := enTranslations.RegisterDefaultTranslations(validate, enTrans); err != nil {
+		t.Fatal(err)
+	}
+	if err := arTranslations.RegisterDefaultTranslations(validate, arTrans); err != nil {
+		t.Fatal(err)
+	}
+	messages := map[ut.Translator]map[string]string{
+		enTrans: {"rule.exists": "{0} does not exist", "rule.arabic_text": "{0} must be written in arabic letters", "field.value": "Value", "field.entity_id": "Entity id"},
+		arTrans: {"rule.exists": "{0} غير موجود", "rule.arabic_text": "{0} يجب أن يكون مكتوباً بحروف عربية", "field.value": "القيمة", "field.entity_id": "رقم الكيان"},
+	}
+	for trans, texts := range messages {
+		for key, text := range texts {
+			if err := trans.Add(key, text, false); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	util.RegisterTranslator(uni)
+	t.Cleanup(func() { util.RegisterTranslator(nil) })
+	return validate
+}
+
+func TestImportTranslations(t *testing.T) {
+	validate := newTestValidator(t)
+	long := strings.Repeat("a", 256)
+
+	tests := []struct {
+		name         string
+		locale       string
+		rows         string
+		wantStatus   int
+		wantErrors   map[string]string
+		wantMessages map[string]string
+		wantImported int
+		wantValues   []string
+	}{
+		{
+			name:         "valid rows",
+			locale:       i18n.English,
+			rows:         `{"entity":"city","entity_id":1,"field":"name","locale":"AR","value":"القاهرة"},{"entity":"governorate","entity_id":2,"field":"name","locale":"en","value":"Giza"}`,
+			wantStatus:   http.StatusOK,
+			wantImported: 2,
+			wantValues:   []string{"القاهرة", "Giza"},
+		},
+		{
+			name:         "repeated translation keep the last value",
+			locale:       i18n.English,
+			rows:         `{"entity":"city","entity_id":1,"field":"name","locale":"en","value":"Cairo"},{"entity":"city","entity_id":1,"field":"name","locale":"EN","value":"Cairo City"}`,
+			wantStatus:   http.StatusOK,
+			wantImported: 1,
+			wantValues:   []string{"Cairo City"},
+		},
+		{
+			name:       "validator errors with labels",
+			locale:     i18n.English,
+			rows:       `{"entity":"city","entity_id":1,"field":"name","locale":"en","value":"` + long + `"},{"entity":"city","field":"name","locale":"en","value":"Cairo"}`,
+			wantStatus: http.StatusBadRequest,
+			wantErrors: map[string]string{"translations.0.value": "max", "translations.1.entity_id": "required"},
+			wantMessages: map[string]string{
+				"translations.0.value":     "Value must be a maximum of 255 characters in length",
+				"translations.1.entity_id": "Entity id is a required field",
+			},
+		},
+		{
+			name:       "arabic messages",
+			locale:     i18n.Arabic,
+			rows:       `{"entity":"city","entity_id":1,"field":"name","locale":"ar","value":"Cairo"},{"entity":"city","entity_id":99,"field":"name","locale":"en","value":"Nowhere"}`,
+			wantStatus: http.StatusBadRequest,
+			wantErrors: map[string]string{"translations.0.value": "arabic_text", "translations.1.entity_id": "exists"},
+			wantMessages: map[string]string{
+				"translations.0.value":     "القيمة يجب أن يكون مكتوباً بحروف عربية",
+				"translations.1.entity_id": "رقم الكيان غير موجود",
+			},
+		},
+		{
+			name:       "field not translatable",
+			locale:     i18n.English,
+			rows:       `{"entity":"city","entity_id":1,"field":"boundary","locale":"en","value":"Cairo"}`,
+			wantStatus: http.StatusBadRequest,
+			wantErrors: map[string]string{"translations.0.field": "invalid"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeDB{existing: map[string][]int64{EntityCity: {1}, EntityGovernorate: {2}}}
+			h := &translationHandler{queries: db.New(fake), validate: validate}
+
+			r := httptest.NewRequest(http.MethodPost, "/translations/import", strings.NewReader(`{"translations":[`+tt.rows+`]}`))
+			r = r.WithContext(i18n.WithLocale(r.Context(), tt.locale))
+			w := httptest.NewRecorder()
+			h.importTranslations(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+
+			if tt.wantStatus == http.StatusOK {
+				var got importResponse
+				if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+					t.Fatal(err)
+				}
+				if got.Imported != tt.wantImported || len(fake.upserted) != 1 {
+					t.Fatalf("imported = %d with %d upserts, want %d in one upsert", got.Imported, len(fake.upserted), tt.wantImported)
+				}
+				if values := fake.upserted[0][4]; !reflect.DeepEqual(values, tt.wantValues) {
+					t.Errorf("upserted values = %v, want %v", values, tt.wantValues)
+				}
+				return
+			}
+
+			if len(fake.upserted) != 0 {
+				t.Error("invalid import wrote translations")
+			}
+			var problem struct {
+				Errors   map[string]string `json:"errors"`
+				Messages map[string]string `json:"messages"`
+			}
+			if err := json.NewDecoder(w.Body).Decode(&problem); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(problem.Errors, tt.wantErrors) {
+				t.Errorf("errors = %v, want %v", problem.Errors, tt.wantErrors)
+			}
+			for field, message := range tt.wantMessages {
+				if problem.Messages[field] != message {
+					t.Errorf("message of %s = %q, want %q", field, problem.Messages[field], message)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package translation
+
+import (
+	"github.com/bigusef/texorbit/internal/apikey"
+	"github.com/bigusef/texorbit/internal/audit"
+	db "github.com/bigusef/texorbit/internal/database"
+	"github.com/bigusef/texorbit/pkg/config"
+	"github.com/bigusef/texorbit/pkg/jwtkeys"
+	"github.com/bigusef/texorbit/pkg/middleware"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"net/http"
+)
+
+func NewRouter(conf *config.Setting, queries *db.Queries, validate *validator.Validate) http.Handler {
+	r := chi.NewRouter()
+	h := &translationHandler{
+		queries:  queries,
+		conf:     conf,
+		validate: validate,
+	}
+
+	r.Use(jwtkeys.Verifier(conf.AccessAuth))
+	r.Use(middleware.APIKeyVerifier(apikey.Lookup(queries)))
+	r.Use(jwtkeys.Authenticator(conf.AccessAuth))
+	r.Use(middleware.PreferredLocale)
+	r.Use(audit.Impersonation(queries))
+	r.Use(middleware.RequirePermission(middleware.TranslationManage))
+
+	r.Get("/export", h.exportTranslations)
+	r.Post("/import", h.importTranslations)
+
+	return r
+}
@@ -0,0 +1,35 @@
+package translation
+
+import (
+	db "github.com/bigusef/texorbit/internal/database"
+	"time"
+)
+
+// translationFile translations of bulk import and export, exported files can be edited and imported again
+type translationFile struct {
+	Translations []translationRow `json:"translations"`
+}
+
+type translationRow struct {
+	Entity    string     `json:"entity" validate:"required"`
+	EntityID  int64      `json:"entity_id" validate:"required"`
+	Field     string     `json:"field" validate:"required"`
+	Locale    string     `json:"locale" validate:"required,bcp47_language_tag"`
+	Value     string     `json:"value" validate:"required,max=255"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+type importResponse struct {
+	Imported int `json:"imported"`
+}
+
+func newTranslationRow(translation db.Translation) translationRow {
+	return translationRow{
+		Entity:    translation.Entity,
+		EntityID:  translation.EntityID,
+		Field:     translation.Field,
+		Locale:    translation.Locale,
+		Value:     translation.Value,
+		UpdatedAt: &translation.UpdatedAt.Time,
+	}
+}
@@ -0,0 +1,139 @@
+package translation
+
+import (
+	"context"
+	db "github.com/bigusef/texorbit/internal/database"
+	"github.com/bigusef/texorbit/pkg/i18n"
+	"github.com/go-playground/validator/v10"
+	"sort"
+)
+
+// translated entities and fields, rows of the translations table are keyed by them
+const (
+	EntityCity        = "city"
+	EntityGovernorate = "governorate"
+
+	FieldName = "name"
+)
+
+// fields translated fields of every entity, translations of other fields are rejected
+var fields = map[string][]string{
+	EntityCity:        {FieldName},
+	EntityGovernorate: {FieldName},
+}
+
+// Names values of translated field by locale (ex: {"en": "Cairo", "ar": "القاهرة"})
+type Names map[string]string
+
+// Resolve return the value in the first locale of the locale fallback chain, entities missing
+// all of them (ex: translated to french only) get the value of the first locale in alphabetic order
+func (n Names) Resolve(locale string) string {
+	for _, candidate := range i18n.Chain(locale) {
+		if value, ok := n[candidate]; ok {
+			return value
+		}
+	}
+
+	locales := make([]string, 0, len(n))
+	for candidate := range n {
+		locales = append(locales, candidate)
+	}
+	if len(locales) == 0 {
+		return ""
+	}
+	sort.Strings(locales)
+	return n[locales[0]]
+}
+
+// IsTranslatable report whether the field of the entity is translated
+func IsTranslatable(entity, field string) bool {
+	for _, translated := range fields[entity] {
+		if translated == field {
+			return true
+		}
+	}
+	return false
+}
+
+// Load return the values of the field of the entities by entity id, entities without
+// translations are missing from the result
+func Load(ctx context.Context, queries *db.Queries, entity, field string, ids []int64) (map[int64]Names, error) {
+	translations, err := queries.EntityTranslations(ctx, db.EntityTranslationsParams{
+		Entity:    entity,
+		Field:     field,
+		EntityIds: ids,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[int64]Names, len(ids))
+	for _, translation := range translations {
+		if result[translation.EntityID] == nil {
+			result[translation.EntityID] = Names{}
+		}
+		result[translation.EntityID][translation.Locale] = translation.Value
+	}
+
+	return result, nil
+}
+
+// Save replace the values of the field of the entity, stored locales missing from names are removed
+func Save(ctx context.Context, queries *db.Queries, entity string, id int64, field string, names Names) error {
+	var rows batch
+	for locale, value := range names {
+		rows.add(entity, id, field, locale, value)
+	}
+
+	if err := queries.UpsertTranslations(ctx, db.UpsertTranslationsParams(rows)); err != nil {
+		return err
+	}
+
+	return queries.DeleteOtherTranslations(ctx, db.DeleteOtherTranslationsParams{
+		Entity:   entity,
+		EntityID: id,
+		Field:    field,
+		Locales:  rows.Locales,
+	})
+}
+
+// Check return validation errors of the names sent in the input field (ex: names), validator tags
+// check the locales and lengths, so it checks the english value every fallback chain ends with is sent
+// and arabic values are written in arabic letters
+func Check(validate *validator.Validate, input string, names Names) map[string]string {
+	errs := map[string]string{}
+	if names[i18n.English] == "" {
+		errs[input+"["+i18n.English+"]"] = "required"
+	}
+	if value, ok := names[i18n.Arabic]; ok && validate.Var(value, "arabic_text") != nil {
+		errs[input+"["+i18n.Arabic+"]"] = "arabic_text"
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// Canonical return the names with canonical locales (ex: "EN" is "en")
+func (n Names) Canonical() Names {
+	result := make(Names, len(n))
+	for locale, value := range n {
+		if canonical, ok := i18n.Canonical(locale); ok {
+			locale = canonical
+		}
+		result[locale] = value
+	}
+	return result
+}
+
+// batch translation rows as the parallel arrays of UpsertTranslations
+type batch db.UpsertTranslationsParams
+
+func (b *batch) add(entity string, id int64, field, locale, value string) {
+	b.Entities = append(b.Entities, entity)
+	b.EntityIds = append(b.EntityIds, id)
+	b.Fields = append(b.Fields, field)
+	b.Locales = append(b.Locales, locale)
+	b.Vals = append(b.Vals, value)
+}
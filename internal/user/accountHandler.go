@@ -0,0 +1,206 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	db "github.com/bigusef/texorbit/internal/database"
+	"github.com/bigusef/texorbit/pkg/i18n"
+	"github.com/bigusef/texorbit/pkg/storage"
+	"github.com/bigusef/texorbit/pkg/util"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// accountDeletionGrace time between deletion request and anonymizing the account, login within it cancel the deletion
+const accountDeletionGrace = time.Hour * 24 * 30
+
+type accountExport struct {
+	ExportedAt     time.Time           `json:"exported_at"`
+	Profile        customerInfo        `json:"profile"`
+	Security       securityInfo        `json:"security"`
+	StatusHistory  []statusChangeInfo  `json:"status_history"`
+	Sessions       []sessionInfo       `json:"sessions"`
+	LoginEvents    []loginEventInfo    `json:"login_events"`
+	Addresses      []addressInfo       `json:"addresses"`
+	Impersonations []impersonationInfo `json:"support_impersonations"`
+}
+
+// securityInfo verification state of the account, secrets and code hashes are never exported
+type securityInfo struct {
+	PhoneVerifiedAt    *time.Time `json:"phone_verified_at,omitempty"`
+	TwoFactorEnabled   bool       `json:"two_factor_enabled"`
+	TwoFactorEnabledAt *time.Time `json:"two_factor_enabled_at,omitempty"`
+}
+
+// deleteMyAccount schedule the current customer account deletion and sign out all its devices
+func (h *customerHandler) deleteMyAccount(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userId, err := currentUserId(r)
+	if err != nil {
+		util.ErrorResponseWriter(w, r, util.BadRequest("invalid user id"))
+		return
+	}
+
+	user, err := h.queries.GetUSerById(ctx, userId)
+	if err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	// staff accounts are managed by admins
+	if user.IsStaff {
+		util.ErrorResponseWriter(w, r, util.Forbidden("staff accounts can not be deleted by their owners"))
+		return
+	}
+
+	user, err = h.queries.ScheduleUserDeletion(ctx, db.ScheduleUserDeletionParams{
+		DeletionScheduledAt: pgtype.Timestamptz{Time: time.Now().Add(accountDeletionGrace), Valid: true},
+		ID:                  userId,
+	})
+	if err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	if err := h.queries.RevokeUserSessions(ctx, userId); err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	util.JsonResponseWriter(w, http.StatusAccepted, map[string]time.Time{
+		"deletion_scheduled_at": user.DeletionScheduledAt.Time,
+	})
+}
+
+// exportMyData download everything stored about the current user as JSON archive
+func (h *customerHandler) exportMyData(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userId, err := currentUserId(r)
+	if err != nil {
+		util.ErrorResponseWriter(w, r, util.BadRequest("invalid user id"))
+		return
+	}
+
+	user, err := h.queries.GetUSerById(ctx, userId)
+	if err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	sessions, err := h.queries.AllUserSessions(ctx, userId)
+	if err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	events, err := h.queries.AllUserLoginEvents(ctx, pgtype.UUID{Bytes: userId, Valid: true})
+	if err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	addresses, err := h.queries.UserAddresses(ctx, userId)
+	if err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	impersonations, err := h.queries.UserImpersonations(ctx, userId)
+	if err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	statusChanges, err := h.queries.AllUserStatusChanges(ctx, userId)
+	if err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	security, err := accountSecurity(ctx, h.queries, user)
+	if err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	export := accountExport{
+		ExportedAt:     time.Now(),
+		Profile:        newCustomerInfo(user),
+		Security:       security,
+		StatusHistory:  make([]statusChangeInfo, len(statusChanges)),
+		Sessions:       newSessionsInfo(sessions, uuid.Nil),
+		LoginEvents:    make([]loginEventInfo, len(events)),
+		Impersonations: make([]impersonationInfo, len(impersonations)),
+	}
+	for i, c := range statusChanges {
+		export.StatusHistory[i] = newStatusChangeInfo(c)
+	}
+	for i, e := range events {
+		export.LoginEvents[i] = newLoginEventInfo(e)
+	}
+	if export.Addresses, err = addressesInfo(ctx, h.queries, addresses, i18n.FromContext(ctx)); err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+	for i, v := range impersonations {
+		export.Impersonations[i] = newImpersonationInfo(v)
+	}
+
+	filename := fmt.Sprintf("texorbit-export-%s.json", time.Now().Format("2006-01-02"))
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	util.JsonResponseWriter(w, http.StatusOK, export)
+}
+
+// accountSecurity phone and two factor verification state of the user
+func accountSecurity(ctx context.Context, queries *db.Queries, user db.User) (securityInfo, error) {
+	var security securityInfo
+	if user.PhoneVerifiedAt.Valid {
+		security.PhoneVerifiedAt = &user.PhoneVerifiedAt.Time
+	}
+
+	mfa, err := queries.GetUserMfa(ctx, user.ID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return security, nil
+		}
+		return security, err
+	}
+
+	if mfa.EnabledAt.Valid {
+		security.TwoFactorEnabled = true
+		security.TwoFactorEnabledAt = &mfa.EnabledAt.Time
+	}
+	return security, nil
+}
+
+// AccountDeletionJob anonymize accounts whose deletion grace period ended, every interval until the context is done.
+// the user rows are kept with anonymized data so records referencing them stay valid
+func AccountDeletionJob(ctx context.Context, queries *db.Queries, store storage.BlobStore, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		users, err := queries.AnonymizeDueUsers(ctx)
+		if err != nil {
+			slog.Error("failed to anonymize deleted accounts", "error", err)
+		} else if len(users) > 0 {
+			for _, user := range users {
+				if user.AvatarKey.Valid {
+					deleteAvatarBlobs(ctx, store, user.AvatarKey.String)
+				}
+			}
+			slog.Info("deleted accounts anonymized", "count", len(users))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
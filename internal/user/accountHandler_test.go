@@ -0,0 +1,97 @@
+package user
+
+import (
+	"context"
+	"errors"
+	db "github.com/bigusef/texorbit/internal/database"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"testing"
+	"time"
+)
+
+func TestAccountSecurity(t *testing.T) {
+	verifiedAt := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	enabledAt := time.Date(2024, 4, 1, 10, 0, 0, 0, time.UTC)
+	mfaRow := func(enabled pgtype.Timestamptz) func([]interface{}) pgx.Row {
+		return func([]interface{}) pgx.Row {
+			return fakeRow{scan: func(dest ...interface{}) {
+				*dest[2].(*pgtype.Timestamptz) = enabled
+			}}
+		}
+	}
+	failed := errors.New("connection refused")
+
+	tests := []struct {
+		name          string
+		phoneVerified pgtype.Timestamptz
+		mfa           func([]interface{}) pgx.Row // nil when the user never enrolled
+		want          securityInfo
+		wantErr       error
+	}{
+		{"nothing verified", pgtype.Timestamptz{}, nil, securityInfo{}, nil},
+		{"phone verified", pgtype.Timestamptz{Time: verifiedAt, Valid: true}, nil, securityInfo{PhoneVerifiedAt: &verifiedAt}, nil},
+		{"two factor enrolled not confirmed", pgtype.Timestamptz{}, mfaRow(pgtype.Timestamptz{}), securityInfo{}, nil},
+		{
+			"two factor enabled", pgtype.Timestamptz{Time: verifiedAt, Valid: true}, mfaRow(pgtype.Timestamptz{Time: enabledAt, Valid: true}),
+			securityInfo{PhoneVerifiedAt: &verifiedAt, TwoFactorEnabled: true, TwoFactorEnabledAt: &enabledAt}, nil,
+		},
+		{"database error", pgtype.Timestamptz{}, func([]interface{}) pgx.Row { return fakeRow{err: failed} }, securityInfo{}, failed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &recordingDB{rows: map[string]func([]interface{}) pgx.Row{}}
+			if tt.mfa != nil {
+				fake.rows["GetUserMfa"] = tt.mfa
+			}
+			user := db.User{ID: uuid.New(), PhoneVerifiedAt: tt.phoneVerified}
+
+			got, err := accountSecurity(context.Background(), db.New(fake), user)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("accountSecurity() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			if !equalTime(got.PhoneVerifiedAt, tt.want.PhoneVerifiedAt) || got.TwoFactorEnabled != tt.want.TwoFactorEnabled ||
+				!equalTime(got.TwoFactorEnabledAt, tt.want.TwoFactorEnabledAt) {
+				t.Errorf("accountSecurity() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func equalTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+func TestNewStatusChangeInfo(t *testing.T) {
+	staff := uuid.New()
+
+	tests := []struct {
+		name   string
+		change db.UserStatusHistory
+		want   *uuid.UUID
+	}{
+		{"changed by staff", db.UserStatusHistory{FromStatus: db.AccountStatusActive, ToStatus: db.AccountStatusSuspended, Reason: "fraud check", ChangedBy: pgtype.UUID{Bytes: staff, Valid: true}}, &staff},
+		{"changed by the system", db.UserStatusHistory{FromStatus: db.AccountStatusSuspended, ToStatus: db.AccountStatusActive, Reason: "suspension ended"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := newStatusChangeInfo(tt.change)
+			if info.FromStatus != string(tt.change.FromStatus) || info.ToStatus != string(tt.change.ToStatus) || info.Reason != tt.change.Reason {
+				t.Errorf("newStatusChangeInfo() = %+v", info)
+			}
+			if (info.ChangedBy == nil) != (tt.want == nil) || (tt.want != nil && *info.ChangedBy != *tt.want) {
+				t.Errorf("changed by = %v, want %v", info.ChangedBy, tt.want)
+			}
+		})
+	}
+}
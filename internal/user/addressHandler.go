@@ -0,0 +1,358 @@
+package user
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/bigusef/texorbit/internal/city"
+	db "github.com/bigusef/texorbit/internal/database"
+	"github.com/bigusef/texorbit/internal/translation"
+	"github.com/bigusef/texorbit/pkg/geo"
+	"github.com/bigusef/texorbit/pkg/i18n"
+	"github.com/bigusef/texorbit/pkg/util"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"net/http"
+	"strconv"
+)
+
+// maxAddresses a customer can keep in the address book
+const maxAddresses = 20
+
+// addressesInfo attach the city name and the delivery zone of every address
+func addressesInfo(ctx context.Context, queries *db.Queries, addresses []db.UserAddress, locale string) ([]addressInfo, error) {
+	ids := make([]int64, len(addresses))
+	for i, address := range addresses {
+		ids[i] = address.CityID
+	}
+
+	names, err := translation.Load(ctx, queries, translation.EntityCity, translation.FieldName, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	zones, err := queries.ActiveDeliveryZonesByCities(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]addressInfo, len(addresses))
+	for i, address := range addresses {
+		result[i] = newAddressInfo(address, names[address.CityID].Resolve(locale))
+
+		// addresses with coordinates get the delivery zone covering them
+		if !address.Latitude.Valid || !address.Longitude.Valid {
+			continue
+		}
+		point := geo.Point{Lat: address.Latitude.Float64, Lng: address.Longitude.Float64}
+		if zone, ok := city.CoveringZone(zones, address.CityID, point); ok {
+			result[i].Delivery = &addressDelivery{
+				ZoneID:     zone.ID,
+				Fee:        zone.Fee,
+				MinOrder:   zone.MinOrder,
+				EtaMinutes: zone.EtaMinutes,
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// writeAddresses write all addresses of the user, the default address first
+func writeAddresses(w http.ResponseWriter, r *http.Request, queries *db.Queries, userId uuid.UUID) {
+	ctx := r.Context()
+
+	addresses, err := queries.UserAddresses(ctx, userId)
+	if err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	result, err := addressesInfo(ctx, queries, addresses, i18n.FromContext(ctx))
+	if err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	util.JsonListResponseWriter(w, http.StatusOK, result, int64(len(result)))
+}
+
+// writeAddress write single address of the user with its city
+func writeAddress(w http.ResponseWriter, r *http.Request, queries *db.Queries, status int, address db.UserAddress) {
+	result, err := addressesInfo(r.Context(), queries, []db.UserAddress{address}, i18n.FromContext(r.Context()))
+	if err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	util.JsonResponseWriter(w, status, result[0])
+}
+
+// decodeAddress read and validate address input, the city must be one of the active cities
+// and it is looked up by the coordinates when not sent
+func (h *customerHandler) decodeAddress(w http.ResponseWriter, r *http.Request) (addressInput, bool) {
+	var input addressInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		util.ErrorResponseWriter(w, r, util.BadRequest("invalid request body"))
+		return input, false
+	}
+
+	if err := h.validate.Struct(input); err != nil {
+		util.ErrorResponseWriter(w, r, util.ValidationFailed(err))
+		return input, false
+	}
+
+	// without city, the city is found from the address coordinates
+	if input.CityID == 0 {
+		found, _, ok, err := city.Locate(r.Context(), h.queries, geo.Point{Lat: *input.Latitude, Lng: *input.Longitude})
+		if err != nil {
+			util.ErrorResponseWriter(w, r, err)
+			return input, false
+		}
+		if !ok {
+			util.ErrorResponseWriter(w, r, util.Invalid(map[string]string{"city_id": "active_city"}))
+			return input, false
+		}
+
+		input.CityID = found.ID
+		return input, true
+	}
+
+	if _, err := h.queries.GetActiveCity(r.Context(), input.CityID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			util.ErrorResponseWriter(w, r, util.Invalid(map[string]string{"city_id": "active_city"}))
+			return input, false
+		}
+
+		util.ErrorResponseWriter(w, r, err)
+		return input, false
+	}
+
+	return input, true
+}
+
+// addressFromURL return id of the "addressId" url parameter
+func addressFromURL(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "addressId"), 10, 64)
+	if err != nil {
+		util.ErrorResponseWriter(w, r, util.BadRequest("invalid address id"))
+		return 0, false
+	}
+
+	return id, true
+}
+
+func (h *customerHandler) listMyAddresses(w http.ResponseWriter, r *http.Request) {
+	userId, err := currentUserId(r)
+	if err != nil {
+		util.ErrorResponseWriter(w, r, util.BadRequest("invalid user id"))
+		return
+	}
+
+	writeAddresses(w, r, h.queries, userId)
+}
+
+func (h *customerHandler) createMyAddress(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userId, err := currentUserId(r)
+	if err != nil {
+		util.ErrorResponseWriter(w, r, util.BadRequest("invalid user id"))
+		return
+	}
+
+	input, ok := h.decodeAddress(w, r)
+	if !ok {
+		return
+	}
+
+	// the user row lock serializes concurrent creations, so the limit and the first default address hold
+	var address db.UserAddress
+	err = pgx.BeginFunc(ctx, h.pool, func(tx pgx.Tx) error {
+		queries := h.queries.WithTx(tx)
+		if err := queries.LockUser(ctx, userId); err != nil {
+			return err
+		}
+
+		count, err := queries.UserAddressesCount(ctx, userId)
+		if err != nil {
+			return err
+		}
+		if count >= maxAddresses {
+			return util.Conflict("addresses limit reached, please remove unused addresses")
+		}
+
+		address, err = queries.CreateUserAddress(ctx, db.CreateUserAddressParams{
+			UserID:    userId,
+			CityID:    input.CityID,
+			Label:     input.Label,
+			Street:    input.Street,
+			Building:  input.Building,
+			Floor:     input.Floor,
+			Landmark:  input.Landmark,
+			Latitude:  coordinate(input.Latitude),
+			Longitude: coordinate(input.Longitude),
+		})
+		if err != nil {
+			return err
+		}
+
+		if input.IsDefault && !address.IsDefault {
+			if _, err := queries.SetDefaultUserAddress(ctx, db.SetDefaultUserAddressParams{ID: address.ID, UserID: userId}); err != nil {
+				return err
+			}
+			address.IsDefault = true
+		}
+		return nil
+	})
+	if err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	writeAddress(w, r, h.queries, http.StatusCreated, address)
+}
+
+func (h *customerHandler) getMyAddress(w http.ResponseWriter, r *http.Request) {
+	userId, err := currentUserId(r)
+	if err != nil {
+		util.ErrorResponseWriter(w, r, util.BadRequest("invalid user id"))
+		return
+	}
+
+	id, ok := addressFromURL(w, r)
+	if !ok {
+		return
+	}
+
+	address, err := h.queries.GetUserAddress(r.Context(), db.GetUserAddressParams{ID: id, UserID: userId})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			util.ErrorResponseWriter(w, r, util.NotFound("address not found"))
+			return
+		}
+
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	writeAddress(w, r, h.queries, http.StatusOK, address)
+}
+
+func (h *customerHandler) updateMyAddress(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userId, err := currentUserId(r)
+	if err != nil {
+		util.ErrorResponseWriter(w, r, util.BadRequest("invalid user id"))
+		return
+	}
+
+	id, ok := addressFromURL(w, r)
+	if !ok {
+		return
+	}
+
+	input, ok := h.decodeAddress(w, r)
+	if !ok {
+		return
+	}
+
+	address, err := h.queries.UpdateUserAddress(ctx, db.UpdateUserAddressParams{
+		CityID:    input.CityID,
+		Label:     input.Label,
+		Street:    input.Street,
+		Building:  input.Building,
+		Floor:     input.Floor,
+		Landmark:  input.Landmark,
+		Latitude:  coordinate(input.Latitude),
+		Longitude: coordinate(input.Longitude),
+		ID:        id,
+		UserID:    userId,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			util.ErrorResponseWriter(w, r, util.NotFound("address not found"))
+			return
+		}
+
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	// the default address can only be replaced by another one, not unset
+	if input.IsDefault && !address.IsDefault {
+		if _, err := h.queries.SetDefaultUserAddress(ctx, db.SetDefaultUserAddressParams{ID: address.ID, UserID: userId}); err != nil {
+			util.ErrorResponseWriter(w, r, err)
+			return
+		}
+		address.IsDefault = true
+	}
+
+	writeAddress(w, r, h.queries, http.StatusOK, address)
+}
+
+func (h *customerHandler) setMyDefaultAddress(w http.ResponseWriter, r *http.Request) {
+	userId, err := currentUserId(r)
+	if err != nil {
+		util.ErrorResponseWriter(w, r, util.BadRequest("invalid user id"))
+		return
+	}
+
+	id, ok := addressFromURL(w, r)
+	if !ok {
+		return
+	}
+
+	affected, err := h.queries.SetDefaultUserAddress(r.Context(), db.SetDefaultUserAddressParams{ID: id, UserID: userId})
+	if err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+	if affected == 0 {
+		util.ErrorResponseWriter(w, r, util.NotFound("address not found"))
+		return
+	}
+
+	writeAddresses(w, r, h.queries, userId)
+}
+
+func (h *customerHandler) deleteMyAddress(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userId, err := currentUserId(r)
+	if err != nil {
+		util.ErrorResponseWriter(w, r, util.BadRequest("invalid user id"))
+		return
+	}
+
+	id, ok := addressFromURL(w, r)
+	if !ok {
+		return
+	}
+
+	affected, err := h.queries.DeleteUserAddress(ctx, db.DeleteUserAddressParams{ID: id, UserID: userId})
+	if err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+	if affected == 0 {
+		util.ErrorResponseWriter(w, r, util.NotFound("address not found"))
+		return
+	}
+
+	// the oldest remaining address replaces deleted default address
+	if err := h.queries.EnsureDefaultUserAddress(ctx, userId); err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	util.JsonResponseWriter(w, http.StatusNoContent, nil)
+}
+
+func (h *customerHandler) listCustomerAddresses(w http.ResponseWriter, r *http.Request) {
+	customer, ok := h.customerFromURL(w, r)
+	if !ok {
+		return
+	}
+
+	writeAddresses(w, r, h.queries, customer.ID)
+}
@@ -0,0 +1,128 @@
+package user
+
+import (
+	"encoding/json"
+	db "github.com/bigusef/texorbit/internal/database"
+	"github.com/go-playground/validator/v10"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDecodeAddress(t *testing.T) {
+	activeCity := func([]interface{}) pgx.Row {
+		return fakeRow{scan: func(dest ...interface{}) {
+			*dest[0].(*int64) = 7
+			*dest[1].(*bool) = true
+		}}
+	}
+
+	tests := []struct {
+		name       string
+		body       string
+		cityActive bool
+		wantStatus int // 0 when the input is accepted
+		wantFields map[string]string
+	}{
+		{"active city", `{"label":"Home","city_id":7,"street":"Tahrir"}`, true, 0, nil},
+		{"active city with coordinates", `{"label":"Work","city_id":7,"street":"Tahrir","latitude":30.04,"longitude":31.23}`, true, 0, nil},
+		{"inactive or unknown city", `{"label":"Home","city_id":7,"street":"Tahrir"}`, false, http.StatusBadRequest, map[string]string{"city_id": "active_city"}},
+		{"missing city and coordinates", `{"label":"Home","street":"Tahrir"}`, true, http.StatusBadRequest, map[string]string{"CityID": "required_without"}},
+		{"latitude without longitude", `{"label":"Home","city_id":7,"street":"Tahrir","latitude":30.04}`, true, http.StatusBadRequest, map[string]string{"Longitude": "required_with"}},
+		{"latitude out of range", `{"label":"Home","city_id":7,"street":"Tahrir","latitude":91,"longitude":31.23}`, true, http.StatusBadRequest, map[string]string{"Latitude": "latitude"}},
+		{"long label", `{"label":"` + strings.Repeat("a", 51) + `","city_id":7,"street":"Tahrir"}`, true, http.StatusBadRequest, map[string]string{"Label": "max"}},
+		{"invalid body", `{"label":`, true, http.StatusBadRequest, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &recordingDB{rows: map[string]func([]interface{}) pgx.Row{}}
+			if tt.cityActive {
+				fake.rows["GetActiveCity"] = activeCity
+			}
+			h := &customerHandler{queries: db.New(fake), validate: validator.New()}
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/user/me/addresses", strings.NewReader(tt.body))
+			input, ok := h.decodeAddress(w, r)
+
+			if tt.wantStatus == 0 {
+				if !ok {
+					t.Fatalf("decodeAddress rejected input: %s", w.Body)
+				}
+				if input.CityID != 7 {
+					t.Errorf("city id = %d, want 7", input.CityID)
+				}
+				if checks := fake.called("GetActiveCity"); len(checks) != 1 || checks[0][0] != int64(7) {
+					t.Errorf("GetActiveCity calls = %v, want city 7", checks)
+				}
+				return
+			}
+
+			if ok {
+				t.Fatalf("decodeAddress accepted %s", tt.body)
+			}
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if tt.wantFields == nil {
+				return
+			}
+
+			var problem struct {
+				Errors map[string]string `json:"errors"`
+			}
+			if err := json.NewDecoder(w.Body).Decode(&problem); err != nil {
+				t.Fatal(err)
+			}
+			for field, rule := range tt.wantFields {
+				if problem.Errors[field] != rule {
+					t.Errorf("errors = %v, want %s: %s", problem.Errors, field, rule)
+				}
+			}
+		})
+	}
+}
+
+func TestNewAddressInfo(t *testing.T) {
+	tests := []struct {
+		name      string
+		latitude  pgtype.Float8
+		longitude pgtype.Float8
+		wantPoint bool
+	}{
+		{"with coordinates", pgtype.Float8{Float64: 30.04, Valid: true}, pgtype.Float8{Float64: 31.23, Valid: true}, true},
+		{"without coordinates", pgtype.Float8{}, pgtype.Float8{}, false},
+		{"partial coordinates", pgtype.Float8{Float64: 30.04, Valid: true}, pgtype.Float8{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			address := db.UserAddress{ID: 3, CityID: 7, Label: "Home", Street: "Tahrir", IsDefault: true, Latitude: tt.latitude, Longitude: tt.longitude}
+			info := newAddressInfo(address, "Cairo")
+
+			if info.ID != 3 || info.City.ID != 7 || info.City.Name != "Cairo" || !info.IsDefault || info.Delivery != nil {
+				t.Errorf("newAddressInfo() = %+v", info)
+			}
+			if (info.Latitude != nil) != tt.wantPoint || (info.Longitude != nil) != tt.wantPoint {
+				t.Fatalf("coordinates = %v, %v, want present %v", info.Latitude, info.Longitude, tt.wantPoint)
+			}
+			if tt.wantPoint && (*info.Latitude != 30.04 || *info.Longitude != 31.23) {
+				t.Errorf("coordinates = %v, %v", *info.Latitude, *info.Longitude)
+			}
+		})
+	}
+}
+
+func TestCoordinate(t *testing.T) {
+	value := 30.04
+	if got := coordinate(&value); !got.Valid || got.Float64 != value {
+		t.Errorf("coordinate(%v) = %+v", value, got)
+	}
+	if got := coordinate(nil); got.Valid {
+		t.Errorf("coordinate(nil) = %+v, want null", got)
+	}
+}
@@ -0,0 +1,77 @@
+package user
+
+import (
+	"github.com/bigusef/texorbit/internal/database"
+	"github.com/jackc/pgx/v5/pgtype"
+	"time"
+)
+
+type addressInput struct {
+	Label     string   `json:"label" validate:"required,max=50"`
+	CityID    int64    `json:"city_id" validate:"required_without=Latitude"`
+	Street    string   `json:"street" validate:"required,max=255"`
+	Building  string   `json:"building" validate:"max=50"`
+	Floor     string   `json:"floor" validate:"max=20"`
+	Landmark  string   `json:"landmark" validate:"max=255"`
+	Latitude  *float64 `json:"latitude" validate:"required_with=Longitude,omitempty,latitude"`
+	Longitude *float64 `json:"longitude" validate:"required_with=Latitude,omitempty,longitude"`
+	IsDefault bool     `json:"is_default"`
+}
+
+type addressCity struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+// addressDelivery the delivery zone covering the address
+type addressDelivery struct {
+	ZoneID     int64 `json:"zone_id"`
+	Fee        int32 `json:"fee"`
+	MinOrder   int32 `json:"min_order"`
+	EtaMinutes int32 `json:"eta_minutes"`
+}
+
+type addressInfo struct {
+	ID        int64            `json:"id"`
+	Label     string           `json:"label"`
+	City      addressCity      `json:"city"`
+	Street    string           `json:"street"`
+	Building  string           `json:"building"`
+	Floor     string           `json:"floor"`
+	Landmark  string           `json:"landmark"`
+	Latitude  *float64         `json:"latitude"`
+	Longitude *float64         `json:"longitude"`
+	IsDefault bool             `json:"is_default"`
+	Delivery  *addressDelivery `json:"delivery"`
+	CreatedAt time.Time        `json:"created_at"`
+}
+
+func newAddressInfo(address database.UserAddress, cityName string) addressInfo {
+	info := addressInfo{
+		ID:        address.ID,
+		Label:     address.Label,
+		City:      addressCity{ID: address.CityID, Name: cityName},
+		Street:    address.Street,
+		Building:  address.Building,
+		Floor:     address.Floor,
+		Landmark:  address.Landmark,
+		IsDefault: address.IsDefault,
+		CreatedAt: address.CreatedAt.Time,
+	}
+
+	if address.Latitude.Valid && address.Longitude.Valid {
+		info.Latitude = &address.Latitude.Float64
+		info.Longitude = &address.Longitude.Float64
+	}
+
+	return info
+}
+
+// coordinate convert optional input coordinate to its stored form
+func coordinate(value *float64) pgtype.Float8 {
+	if value == nil {
+		return pgtype.Float8{}
+	}
+
+	return pgtype.Float8{Float64: *value, Valid: true}
+}
@@ -1,24 +1,52 @@
 package user
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	db "github.com/bigusef/texorbit/internal/database"
 	"github.com/bigusef/texorbit/pkg/config"
+	"github.com/bigusef/texorbit/pkg/oidc"
 	"github.com/bigusef/texorbit/pkg/util"
+	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/jwtauth/v5"
 	"github.com/go-playground/validator/v10"
-	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
+	"log/slog"
 	"net/http"
+	"strings"
 	"time"
 )
 
-type userInputData struct {
-	Name   string `json:"name" validate:"required"`
-	Email  string `json:"email" validate:"required,email"`
-	Avatar string `json:"avatar" validate:"required,url"`
+var (
+	errInvalidLoginRequest = errors.New("login request is invalid or expired, please start again")
+	errUnverifiedEmail     = errors.New("email address is not verified by the identity provider")
+)
+
+type loginInputData struct {
+	State string `json:"state" validate:"required"`
+	Code  string `json:"code" validate:"required"`
+}
+
+type loginResponse struct {
+	Name         string
+	Email        string      `json:"email"`
+	PhoneNumber  pgtype.Text `json:"phone_number"`
+	Avatar       pgtype.Text `json:"avatar"`
+	AccessToken  string      `json:"access_token"`
+	RefreshToken string      `json:"refresh_token"`
+}
+
+func newLoginResponse(user db.User, tokens *tokenPair) loginResponse {
+	return loginResponse{
+		Name:         user.Name,
+		Email:        user.Email,
+		PhoneNumber:  user.PhoneNumber,
+		Avatar:       user.Avatar,
+		AccessToken:  tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+	}
 }
 
 type authHandler struct {
@@ -27,211 +55,331 @@ type authHandler struct {
 	validate *validator.Validate
 }
 
+func (h *authHandler) authorize(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	provider, ok := h.conf.OIDCProviders[chi.URLParam(r, "provider")]
+	if !ok {
+		util.ErrorResponseWriter(w, r, util.NotFound("unsupported login provider"))
+		return
+	}
+
+	// state, nonce and code verifier kept on our side, only the challenge goes to the provider
+	state, stateErr := oidc.RandomString(32)
+	nonce, nonceErr := oidc.RandomString(32)
+	verifier, verifierErr := oidc.RandomString(64)
+	if stateErr != nil || nonceErr != nil || verifierErr != nil {
+		util.ErrorResponseWriter(w, r, util.Internal(errors.Join(stateErr, nonceErr, verifierErr)))
+		return
+	}
+
+	if err := h.queries.DeleteExpiredAuthRequests(ctx); err != nil {
+		slog.Error("failed to delete expired auth requests", "error", err)
+	}
+	if err := h.queries.DeleteStaleLoginThrottles(ctx); err != nil {
+		slog.Error("failed to delete stale login throttles", "error", err)
+	}
+
+	err := h.queries.CreateAuthRequest(ctx, db.CreateAuthRequestParams{
+		State:        state,
+		Provider:     provider.Name(),
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		ExpiresAt:    pgtype.Timestamptz{Time: time.Now().Add(time.Minute * 10), Valid: true},
+	})
+	if err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	authURL, err := provider.AuthCodeURL(ctx, state, nonce, verifier)
+	if err != nil {
+		util.ErrorResponseWriter(w, r, util.NewError(http.StatusBadGateway, util.CodeUnavailable, "login provider is not available"))
+		return
+	}
+
+	util.JsonResponseWriter(w, http.StatusOK, map[string]string{
+		"authorization_url": authURL,
+		"state":             state,
+	})
+}
+
+// verifyIdentity consume the pending login request and exchange the authorization code
+// with the provider for a verified identity
+func (h *authHandler) verifyIdentity(ctx context.Context, payload loginInputData) (*oidc.Identity, error) {
+	authRequest, err := h.queries.ConsumeAuthRequest(ctx, payload.State)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errInvalidLoginRequest
+		}
+		return nil, err
+	}
+
+	provider, ok := h.conf.OIDCProviders[authRequest.Provider]
+	if !ok {
+		return nil, errInvalidLoginRequest
+	}
+
+	identity, err := provider.Exchange(ctx, payload.Code, authRequest.CodeVerifier, authRequest.Nonce)
+	if err != nil {
+		slog.Warn("oidc code exchange failed", "provider", authRequest.Provider, "error", err)
+		return nil, errInvalidLoginRequest
+	}
+
+	if !identity.EmailVerified {
+		return nil, errUnverifiedEmail
+	}
+
+	return identity, nil
+}
+
+// getOrCreateCustomer return the user of the given email, creating new customer account when not exist,
+// name fallback to the email local part when empty
+func (h *authHandler) getOrCreateCustomer(ctx context.Context, email, name, picture string) (db.User, error) {
+	email = util.NormalizeEmail(email)
+	user, err := h.queries.GetUserByEmail(ctx, email)
+	if err == nil || !errors.Is(err, pgx.ErrNoRows) {
+		return user, err
+	}
+
+	if name == "" {
+		name = strings.Split(email, "@")[0]
+	}
+
+	return h.queries.CreateUser(ctx, db.CreateUserParams{
+		Name:    name,
+		Email:   email,
+		Avatar:  pgtype.Text{String: picture, Valid: picture != ""},
+		IsStaff: false,
+		Status:  db.AccountStatusActive,
+	})
+}
+
 func (h *authHandler) login(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
-	//TODO: change here to get the data from oauth2 logic
-	var payload userInputData
-	createAccount := false
+	var payload loginInputData
 
 	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		util.ErrorResponseWriter(w, r, util.BadRequest(err.Error()))
 		return
 	}
 
 	if err := h.validate.Struct(payload); err != nil {
-		ts := map[string]string{}
-		for _, err := range err.(validator.ValidationErrors) {
-			ts[err.Field()] = err.Tag()
-		}
+		util.ErrorResponseWriter(w, r, util.ValidationFailed(err))
+		return
+	}
 
-		util.JsonResponseWriter(w, http.StatusBadRequest, ts)
+	ipKey := ipThrottleKey(r)
+	if checkLoginLocked(w, r, h.queries, ipKey) {
 		return
 	}
 
-	// start of logic get or create user
-	user, err := h.queries.GetUserByEmail(ctx, payload.Email)
+	identity, err := h.verifyIdentity(ctx, payload)
 	if err != nil {
-		if !errors.Is(err, pgx.ErrNoRows) {
-			http.Error(w, "issue in getting user data", http.StatusInternalServerError)
+		if errors.Is(err, errInvalidLoginRequest) || errors.Is(err, errUnverifiedEmail) {
+			loginFailed(ctx, h.queries, ipKey)
+			util.ErrorResponseWriter(w, r, util.Unauthorized(err.Error()))
 			return
 		}
 
-		// when the user ont exist in our DB then change create user flag to true
-		createAccount = true
+		util.ErrorResponseWriter(w, r, err)
+		return
 	}
 
-	if createAccount {
-		newUser, err := h.queries.CreateUser(ctx, db.CreateUserParams{
-			Name:    payload.Name,
-			Email:   payload.Email,
-			Avatar:  pgtype.Text{String: payload.Avatar, Valid: true},
-			IsStaff: false,
-		})
-		if err != nil {
-			http.Error(w, "failed to create user", http.StatusBadRequest)
-			return
-		}
+	accountKey := accountThrottleKey(identity.Email)
+	if checkLoginLocked(w, r, h.queries, accountKey) {
+		return
+	}
 
-		user = newUser
+	user, err := h.getOrCreateCustomer(ctx, identity.Email, identity.Name, identity.Picture)
+	if err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	// staff login only through staffLogin, which asks for their second factor
+	if user.IsStaff {
+		loginFailed(ctx, h.queries, ipKey, accountKey)
+		h.recordLoginEvent(ctx, r, user.Email, user.ID, oidcLoginMethod(identity.Provider), loginFailureNotAllowed)
+		util.ErrorResponseWriter(w, r, util.Unauthorized(errLoginFailed.Error()))
+		return
 	}
 
 	// validate user not blocked
 	if !user.IsActive() {
-		http.Error(w, "There are issue in your account, please contact with support.", http.StatusForbidden)
-		return
-	}
-
-	// update user data
-	// TODO: complete this
-	//  - update last login
-	//  - update user data from payload
-
-	// get access token and refresh token
-	_, accessToken, _ := h.conf.AccessAuth.Encode(
-		map[string]interface{}{
-			"sub":   user.ID.String(),
-			"exp":   time.Now().Add(time.Minute * 15).Unix(),
-			"staff": user.IsStaff,
-		},
-	)
-	_, refreshToken, _ := h.conf.RefreshAuth.Encode(
-		map[string]interface{}{
-			"sub": user.ID.String(),
-			"exp": time.Now().Add(time.Hour * 72).Unix(),
-		},
-	)
-
-	response := struct {
-		Name         string
-		Email        string      `json:"email"`
-		PhoneNumber  pgtype.Text `json:"phone_number"`
-		Avatar       pgtype.Text `json:"avatar"`
-		AccessToken  string      `json:"access_token"`
-		RefreshToken string      `json:"refresh_token"`
-	}{
-		user.Name,
-		user.Email,
-		user.PhoneNumber,
-		user.Avatar,
-		accessToken,
-		refreshToken,
-	}
-
-	util.JsonResponseWriter(w, http.StatusOK, response)
+		loginFailed(ctx, h.queries, ipKey, accountKey)
+		h.recordLoginEvent(ctx, r, user.Email, user.ID, oidcLoginMethod(identity.Provider), loginFailureInactive)
+		util.ErrorResponseWriter(w, r, util.Forbidden("There are issue in your account, please contact with support."))
+		return
+	}
+
+	// update last login and user data from identity provider
+	if user, err = h.completeLogin(ctx, r, user, oidcLoginMethod(identity.Provider), identity); err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	// start new device session with access token and refresh token
+	tokens, err := h.startSession(ctx, r, user)
+	if err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	util.JsonResponseWriter(w, http.StatusOK, newLoginResponse(user, tokens))
 }
 
 func (h *authHandler) staffLogin(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
-	//TODO: change here to get the data from oauth2 logic
-	var payload userInputData
+	var payload loginInputData
 
 	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
-		http.Error(w, "invalid request payload", http.StatusBadRequest)
+		util.ErrorResponseWriter(w, r, util.BadRequest("invalid request payload"))
 		return
 	}
 
 	if err := h.validate.Struct(payload); err != nil {
-		ts := map[string]string{}
-		for _, err := range err.(validator.ValidationErrors) {
-			ts[err.Field()] = err.Tag()
-		}
+		util.ErrorResponseWriter(w, r, util.ValidationFailed(err))
+		return
+	}
 
-		util.JsonResponseWriter(w, http.StatusBadRequest, ts)
+	ipKey := ipThrottleKey(r)
+	if checkLoginLocked(w, r, h.queries, ipKey) {
 		return
 	}
 
-	// start of logic get or create user
-	user, err := h.queries.GetUserByEmail(ctx, payload.Email)
+	identity, err := h.verifyIdentity(ctx, payload)
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			http.Error(w, "this user does not exist in the system.", http.StatusNotFound)
+		if errors.Is(err, errInvalidLoginRequest) || errors.Is(err, errUnverifiedEmail) {
+			loginFailed(ctx, h.queries, ipKey)
+			util.ErrorResponseWriter(w, r, util.Unauthorized(err.Error()))
 			return
 		}
 
-		http.Error(w, "issue in getting user data", http.StatusInternalServerError)
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	accountKey := accountThrottleKey(identity.Email)
+	if checkLoginLocked(w, r, h.queries, accountKey) {
+		return
+	}
+
+	// staff accounts are created by admins only, so no account creation here.
+	// missing, customer and inactive accounts get the same response so staff emails can not be discovered
+	user, err := h.queries.GetUserByEmail(ctx, identity.Email)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	if err != nil || !user.IsActive() || !user.IsStaff {
+		loginFailed(ctx, h.queries, ipKey, accountKey)
+		h.recordLoginEvent(ctx, r, identity.Email, user.ID, oidcLoginMethod(identity.Provider), loginFailureNotAllowed)
+		util.ErrorResponseWriter(w, r, util.Unauthorized(errLoginFailed.Error()))
 		return
 	}
 
-	if !user.IsActive() || !user.IsStaff {
-		http.Error(w, "There are issue in your account, Contact with your IT support.", http.StatusForbidden)
+	// staff with two factor authentication enabled get short lived token to complete login with their code
+	mfa, err := h.queries.GetUserMfa(ctx, user.ID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		util.ErrorResponseWriter(w, r, err)
 		return
 	}
 
-	// get access token and refresh token
-	_, accessToken, accessErr := h.conf.AccessAuth.Encode(
-		map[string]interface{}{
-			"sub":   user.ID.String(),
-			"exp":   time.Now().Add(time.Minute * 15).Unix(),
-			"staff": user.IsStaff,
-		},
-	)
-	_, refreshToken, refreshErr := h.conf.RefreshAuth.Encode(
-		map[string]interface{}{
-			"sub": user.ID.String(),
-			"exp": time.Now().Add(time.Hour * 24).Unix(),
-		},
-	)
-	if accessErr != nil || refreshErr != nil {
-		http.Error(w, "failed to generate token", http.StatusInternalServerError)
+	if err == nil && mfa.EnabledAt.Valid {
+		mfaToken, err := h.signPurposeToken(mfaPendingPurpose, user.ID.String(), mfaTokenTTL)
+		if err != nil {
+			util.ErrorResponseWriter(w, r, err)
+			return
+		}
+
+		// failed attempts are kept across logins, they are reset only by accepted code or after mfaLockout
+		util.JsonResponseWriter(w, http.StatusOK, map[string]interface{}{
+			"mfa_required": true,
+			"mfa_token":    mfaToken,
+		})
 		return
 	}
 
-	response := struct {
-		Name         string
-		Email        string      `json:"email"`
-		PhoneNumber  pgtype.Text `json:"phone_number"`
-		Avatar       pgtype.Text `json:"avatar"`
-		AccessToken  string      `json:"access_token"`
-		RefreshToken string      `json:"refresh_token"`
-	}{
-		user.Name,
-		user.Email,
-		user.PhoneNumber,
-		user.Avatar,
-		accessToken,
-		refreshToken,
+	if user, err = h.completeLogin(ctx, r, user, oidcLoginMethod(identity.Provider), identity); err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
 	}
 
-	util.JsonResponseWriter(w, http.StatusOK, response)
+	// start new device session with access token and refresh token
+	tokens, err := h.startSession(ctx, r, user)
+	if err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	util.JsonResponseWriter(w, http.StatusOK, newLoginResponse(user, tokens))
 }
 
 func (h *authHandler) refreshAccessToken(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
-	// get userId from refresh token
-	_, claims, _ := jwtauth.FromContext(r.Context())
-	sub := claims["sub"].(string)
-	userId, err := uuid.Parse(sub)
-	if err != nil {
-		http.Error(w, "invalid user id", http.StatusBadRequest)
+	// token signature and expiry already checked by the verifier middleware
+	refreshToken := jwtauth.TokenFromHeader(r)
+	if refreshToken == "" {
+		util.ErrorResponseWriter(w, r, util.Unauthorized("authentication required"))
 		return
 	}
 
-	// get user from DB
-	user, err := h.queries.GetUSerById(ctx, userId)
+	user, tokens, err := h.rotateSession(ctx, r, refreshToken)
 	if err != nil {
-		util.JsonResponseWriter(w, http.StatusNotFound, "this user does not exist in the system.")
+		if errors.Is(err, errInvalidRefreshToken) || errors.Is(err, errRefreshTokenReused) {
+			util.ErrorResponseWriter(w, r, util.Unauthorized(err.Error()))
+			return
+		}
+
+		if errors.Is(err, pgx.ErrNoRows) {
+			util.ErrorResponseWriter(w, r, util.NotFound("this user does not exist in the system."))
+			return
+		}
+
+		util.ErrorResponseWriter(w, r, err)
 		return
 	}
 
 	// user validation
 	if !user.IsActive() {
-		http.Error(w, "There are issue in your account, please contact with support.", http.StatusForbidden)
+		util.ErrorResponseWriter(w, r, util.Forbidden("There are issue in your account, please contact with support."))
 		return
 	}
 
-	// generate new access token
-	_, accessToken, _ := h.conf.AccessAuth.Encode(
-		map[string]interface{}{
-			"sub":   user.ID.String(),
-			"exp":   time.Now().Add(time.Minute * 15).Unix(),
-			"staff": user.IsStaff,
-		},
-	)
+	util.JsonResponseWriter(w, http.StatusOK, tokens)
+}
 
-	util.JsonResponseWriter(w, http.StatusOK, map[string]string{
-		"access_token": accessToken,
-	})
+func (h *authHandler) logout(w http.ResponseWriter, r *http.Request) {
+	sessionId, err := currentSessionId(r)
+	if err != nil {
+		util.ErrorResponseWriter(w, r, util.BadRequest("invalid session id"))
+		return
+	}
+
+	if err := h.queries.RevokeSession(r.Context(), sessionId); err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	util.JsonResponseWriter(w, http.StatusNoContent, nil)
+}
+
+func (h *authHandler) logoutAll(w http.ResponseWriter, r *http.Request) {
+	userId, err := currentUserId(r)
+	if err != nil {
+		util.ErrorResponseWriter(w, r, util.BadRequest("invalid user id"))
+		return
+	}
+
+	if err := h.queries.RevokeUserSessions(r.Context(), userId); err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	util.JsonResponseWriter(w, http.StatusNoContent, nil)
 }
@@ -0,0 +1,218 @@
+package user
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/bigusef/texorbit/internal/apikey"
+	"github.com/bigusef/texorbit/internal/audit"
+	db "github.com/bigusef/texorbit/internal/database"
+	"github.com/bigusef/texorbit/pkg/config"
+	"github.com/bigusef/texorbit/pkg/imaging"
+	"github.com/bigusef/texorbit/pkg/jwtkeys"
+	"github.com/bigusef/texorbit/pkg/middleware"
+	"github.com/bigusef/texorbit/pkg/storage"
+	"github.com/bigusef/texorbit/pkg/util"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// maxAvatarSize of uploaded avatar file
+const maxAvatarSize = 5 << 20
+
+// avatarURLTTL validity of avatar download links
+const avatarURLTTL = 15 * time.Minute
+
+// avatarSizes standard square thumbnails generated for every uploaded avatar, in pixels
+var avatarSizes = map[string]int{
+	"small":  64,
+	"medium": 256,
+	"large":  512,
+}
+
+// avatarBlobKey return the stored thumbnail key of the avatar
+func avatarBlobKey(avatarKey, size string) string {
+	return avatarKey + "/" + size + ".jpg"
+}
+
+// deleteAvatarBlobs remove all thumbnails of the avatar, failures are only logged as the files are not reachable anymore
+func deleteAvatarBlobs(ctx context.Context, store storage.BlobStore, avatarKey string) {
+	for size := range avatarSizes {
+		if err := store.Delete(ctx, avatarBlobKey(avatarKey, size)); err != nil {
+			slog.Error("failed to delete avatar", "key", avatarKey, "error", err)
+		}
+	}
+}
+
+// uploadAvatar replace the current user avatar with the "avatar" image of multipart form,
+// the image is stored as JPEG thumbnails without its metadata
+func (h *customerHandler) uploadAvatar(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userId, err := currentUserId(r)
+	if err != nil {
+		util.ErrorResponseWriter(w, r, util.BadRequest("invalid user id"))
+		return
+	}
+
+	// extra space for the multipart boundaries and headers
+	r.Body = http.MaxBytesReader(w, r.Body, maxAvatarSize+(64<<10))
+	file, _, err := r.FormFile("avatar")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			util.ErrorResponseWriter(w, r, util.NewError(http.StatusRequestEntityTooLarge, util.CodePayloadTooLarge, "avatar must not exceed 5MB"))
+			return
+		}
+
+		util.ErrorResponseWriter(w, r, util.Invalid(map[string]string{"avatar": "required"}))
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxAvatarSize+1))
+	if err != nil {
+		util.ErrorResponseWriter(w, r, util.BadRequest("invalid avatar file"))
+		return
+	}
+	if len(data) > maxAvatarSize {
+		util.ErrorResponseWriter(w, r, util.NewError(http.StatusRequestEntityTooLarge, util.CodePayloadTooLarge, "avatar must not exceed 5MB"))
+		return
+	}
+
+	img, err := imaging.Decode(data)
+	if err != nil {
+		if errors.Is(err, imaging.ErrTooLarge) {
+			util.ErrorResponseWriter(w, r, util.NewError(http.StatusRequestEntityTooLarge, util.CodePayloadTooLarge, err.Error()))
+			return
+		}
+
+		util.ErrorResponseWriter(w, r, util.NewError(http.StatusUnsupportedMediaType, util.CodeUnsupportedMedia, err.Error()))
+		return
+	}
+
+	user, err := h.queries.GetUSerById(ctx, userId)
+	if err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	// new key for every upload, so cached links of the old avatar are not served the new one
+	version := make([]byte, 8)
+	if _, err := rand.Read(version); err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+	avatarKey := fmt.Sprintf("avatars/%s/%s", userId, hex.EncodeToString(version))
+
+	for size, pixels := range avatarSizes {
+		thumbnail, err := imaging.EncodeJPEG(imaging.Thumbnail(img, pixels))
+		if err != nil {
+			util.ErrorResponseWriter(w, r, err)
+			return
+		}
+
+		if err := h.conf.BlobStore.Put(ctx, avatarBlobKey(avatarKey, size), "image/jpeg", thumbnail); err != nil {
+			slog.Error("failed to store avatar", "key", avatarKey, "error", err)
+			util.ErrorResponseWriter(w, r, err)
+			return
+		}
+	}
+
+	updated, err := h.queries.SetUserAvatar(ctx, db.SetUserAvatarParams{
+		Avatar:    pgtype.Text{String: avatarURL(h.conf, userId, avatarKey), Valid: true},
+		AvatarKey: pgtype.Text{String: avatarKey, Valid: true},
+		ID:        userId,
+	})
+	if err != nil {
+		deleteAvatarBlobs(ctx, h.conf.BlobStore, avatarKey)
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	if user.AvatarKey.Valid {
+		deleteAvatarBlobs(ctx, h.conf.BlobStore, user.AvatarKey.String)
+	}
+
+	util.JsonResponseWriter(w, http.StatusOK, newCustomerInfo(updated))
+}
+
+// avatarURL return the stable avatar address stored in the user profile, it redirects to short-lived signed URL
+func avatarURL(conf *config.Setting, userId uuid.UUID, avatarKey string) string {
+	return fmt.Sprintf("%s/avatars/%s/medium?v=%s", conf.PublicURL, userId, avatarKey[len(avatarKey)-16:])
+}
+
+// canSeeAvatar report whether the caller may see the user avatar, users see their own avatar and staff
+// see avatars of the accounts they can read
+func canSeeAvatar(r *http.Request, user db.User) bool {
+	_, claims, _ := jwtauth.FromContext(r.Context())
+	if sub, _ := claims["sub"].(string); sub == user.ID.String() {
+		return true
+	}
+
+	if user.IsStaff {
+		return middleware.HasPermission(claims, middleware.StaffRead)
+	}
+	return middleware.HasPermission(claims, middleware.CustomerRead)
+}
+
+// AvatarRouter serve uploaded avatars to the users allowed to see them, the access token may be sent
+// in "jwt" cookie so the avatar links can be used directly in image tags
+func AvatarRouter(conf *config.Setting, queries *db.Queries) http.Handler {
+	r := chi.NewRouter()
+
+	r.Use(jwtkeys.Verifier(conf.AccessAuth))
+	r.Use(middleware.APIKeyVerifier(apikey.Lookup(queries)))
+	r.Use(jwtkeys.Authenticator(conf.AccessAuth))
+	r.Use(audit.Impersonation(queries))
+
+	r.Get("/{id}/{size}", func(w http.ResponseWriter, r *http.Request) {
+		id, err := uuid.Parse(chi.URLParam(r, "id"))
+		if err != nil {
+			util.ErrorResponseWriter(w, r, util.BadRequest("invalid user id"))
+			return
+		}
+
+		size := chi.URLParam(r, "size")
+		if _, ok := avatarSizes[size]; !ok {
+			util.ErrorResponseWriter(w, r, util.NotFound("size should be small, medium or large"))
+			return
+		}
+
+		user, err := queries.GetUSerById(r.Context(), id)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				util.ErrorResponseWriter(w, r, util.NotFound("resource not found"))
+				return
+			}
+
+			util.ErrorResponseWriter(w, r, err)
+			return
+		}
+		// avatars of other accounts are not found, so user ids can not be probed
+		if !user.AvatarKey.Valid || !canSeeAvatar(r, user) {
+			util.ErrorResponseWriter(w, r, util.NotFound("resource not found"))
+			return
+		}
+
+		signed, err := conf.BlobStore.SignedURL(avatarBlobKey(user.AvatarKey.String, size), avatarURLTTL)
+		if err != nil {
+			util.ErrorResponseWriter(w, r, err)
+			return
+		}
+
+		// versioned links never change content, so the redirect can be cached until the signed URL expires
+		w.Header().Set("Cache-Control", fmt.Sprintf("private, max-age=%d", int(avatarURLTTL.Seconds())-60))
+		http.Redirect(w, r, signed, http.StatusFound)
+	})
+
+	return r
+}
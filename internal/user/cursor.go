@@ -0,0 +1,42 @@
+package user
+
+import (
+	db "github.com/bigusef/texorbit/internal/database"
+	"github.com/bigusef/texorbit/pkg/middleware"
+	"github.com/bigusef/texorbit/pkg/util"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"time"
+)
+
+// user lists are ordered by join date then id, newest first, cursors keep both keys of the row
+func userKeys(user db.User) []string {
+	return []string{user.JoinDate.Time.Format(time.RFC3339Nano), user.ID.String()}
+}
+
+// pageKeys keys of the first and last users of the page
+func pageKeys(users []db.User) (first, last []string) {
+	if len(users) == 0 {
+		return nil, nil
+	}
+	return userKeys(users[0]), userKeys(users[len(users)-1])
+}
+
+// cursorKeys join date and id of the cursor row
+func cursorKeys(cursor *middleware.Cursor) (pgtype.Timestamptz, uuid.UUID, error) {
+	invalid := util.Invalid(map[string]string{"cursor": "invalid"})
+	if len(cursor.Keys) != 2 {
+		return pgtype.Timestamptz{}, uuid.Nil, invalid
+	}
+
+	joinDate, err := time.Parse(time.RFC3339Nano, cursor.Keys[0])
+	if err != nil {
+		return pgtype.Timestamptz{}, uuid.Nil, invalid
+	}
+	id, err := uuid.Parse(cursor.Keys[1])
+	if err != nil {
+		return pgtype.Timestamptz{}, uuid.Nil, invalid
+	}
+
+	return pgtype.Timestamptz{Time: joinDate, Valid: true}, id, nil
+}
@@ -1,24 +1,325 @@
 package user
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	db "github.com/bigusef/texorbit/internal/database"
 	"github.com/bigusef/texorbit/pkg/config"
+	"github.com/bigusef/texorbit/pkg/i18n"
+	"github.com/bigusef/texorbit/pkg/middleware"
+	"github.com/bigusef/texorbit/pkg/util"
+	"github.com/go-chi/chi/v5"
 	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"net/http"
+	"time"
 )
 
 type customerHandler struct {
 	conf     *config.Setting
+	pool     *pgxpool.Pool
 	queries  *db.Queries
 	validate *validator.Validate
 }
 
-func (h *customerHandler) getUserInfo(w http.ResponseWriter, r *http.Request) {}
+func (h *customerHandler) getUserInfo(w http.ResponseWriter, r *http.Request) {
+	userId, err := currentUserId(r)
+	if err != nil {
+		util.ErrorResponseWriter(w, r, util.BadRequest("invalid user id"))
+		return
+	}
 
-func (h *customerHandler) updateUserInfo(w http.ResponseWriter, r *http.Request) {}
+	user, err := h.queries.GetUSerById(r.Context(), userId)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			util.ErrorResponseWriter(w, r, util.NotFound("this user does not exist in the system."))
+			return
+		}
 
-func (h *customerHandler) listAllCustomers(w http.ResponseWriter, r *http.Request) {}
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
 
-func (h *customerHandler) getCustomerInfo(w http.ResponseWriter, r *http.Request) {}
+	util.JsonResponseWriter(w, http.StatusOK, newCustomerInfo(user))
+}
+
+func (h *customerHandler) updateUserInfo(w http.ResponseWriter, r *http.Request) {
+	userId, err := currentUserId(r)
+	if err != nil {
+		util.ErrorResponseWriter(w, r, util.BadRequest("invalid user id"))
+		return
+	}
+
+	var input updateProfile
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		util.ErrorResponseWriter(w, r, util.BadRequest("invalid request body"))
+		return
+	}
+
+	if err := h.validate.Struct(input); err != nil {
+		util.ErrorResponseWriter(w, r, util.ValidationFailed(err))
+		return
+	}
+
+	user, err := h.queries.UpdateUserProfile(r.Context(), db.UpdateUserProfileParams{
+		ID:          userId,
+		Name:        input.Name,
+		PhoneNumber: phoneText(input.PhoneNumber),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			util.ErrorResponseWriter(w, r, util.NotFound("this user does not exist in the system."))
+			return
+		}
+
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	util.JsonResponseWriter(w, http.StatusOK, newCustomerInfo(user))
+}
+
+// updateMyLanguage set the language of responses, emails and SMS of the current user,
+// authenticated requests use it after the next token refresh
+func (h *customerHandler) updateMyLanguage(w http.ResponseWriter, r *http.Request) {
+	userId, err := currentUserId(r)
+	if err != nil {
+		util.ErrorResponseWriter(w, r, util.BadRequest("invalid user id"))
+		return
+	}
+
+	var input updateLanguage
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		util.ErrorResponseWriter(w, r, util.BadRequest("invalid request body"))
+		return
+	}
+
+	if err := h.validate.Struct(input); err != nil {
+		util.ErrorResponseWriter(w, r, util.ValidationFailed(err))
+		return
+	}
+
+	language := pgtype.Text{}
+	if input.Language != nil {
+		locale, _ := i18n.Parameter(*input.Language)
+		language = pgtype.Text{String: locale, Valid: true}
+	}
+
+	user, err := h.queries.SetUserPreferredLanguage(r.Context(), db.SetUserPreferredLanguageParams{
+		PreferredLanguage: language,
+		ID:                userId,
+	})
+	if err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	util.JsonResponseWriter(w, http.StatusOK, newCustomerInfo(user))
+}
+
+// parseJoinDate parse date (2024-01-31) or RFC 3339 time of the join date filters,
+// the exclusive end of a date range is the start of the next day so the named day is included
+func parseJoinDate(raw string, end bool) (time.Time, error) {
+	date, err := time.Parse(time.DateOnly, raw)
+	if err != nil {
+		return time.Parse(time.RFC3339, raw)
+	}
+
+	if end {
+		date = date.AddDate(0, 0, 1)
+	}
+	return date, nil
+}
+
+func (h *customerHandler) listAllCustomers(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	page := ctx.Value("pagination").(*middleware.Paginator)
+	query := r.URL.Query()
+
+	// optional filters, empty values are ignored by the query
+	filter := db.FilterCustomersCountParams{}
+	if status := query.Get("status"); status != "" {
+		filter.Status = db.NullAccountStatus{AccountStatus: db.AccountStatus(status), Valid: true}
+		if err := h.validate.Var(status, "oneof=pending_verification active suspended deleted"); err != nil {
+			util.ErrorResponseWriter(w, r, util.Invalid(map[string]string{"status": "oneof"}))
+			return
+		}
+	}
+
+	for key, value := range map[string]*pgtype.Timestamptz{"joined_from": &filter.JoinedFrom, "joined_to": &filter.JoinedTo} {
+		raw := query.Get(key)
+		if raw == "" {
+			continue
+		}
+
+		date, err := parseJoinDate(raw, key == "joined_to")
+		if err != nil {
+			util.ErrorResponseWriter(w, r, util.Invalid(map[string]string{key: "datetime"}))
+			return
+		}
+		*value = pgtype.Timestamptz{Time: date, Valid: true}
+	}
+
+	if q := query.Get("q"); q != "" {
+		filter.Query = pgtype.Text{String: util.ContainsPattern(q), Valid: true}
+	}
 
-func (h *customerHandler) updateCustomerInfo(w http.ResponseWriter, r *http.Request) {}
+	customers, more, err := h.pageCustomers(ctx, page, filter)
+	if err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+	first, last := pageKeys(customers)
+	page.SetLinks(w, first, last, more)
+
+	result := make([]customerInfo, len(customers))
+	for i, customer := range customers {
+		result[i] = newCustomerInfo(customer)
+	}
+
+	count, err := h.countCustomers(ctx, page.Count, filter)
+	if err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	util.JsonPageResponseWriter(w, http.StatusOK, result, count)
+}
+
+// countCustomers total count of the filtered customers in the count mode, nil when it is not counted
+func (h *customerHandler) countCustomers(ctx context.Context, mode string, filter db.FilterCustomersCountParams) (*int64, error) {
+	var count int64
+	var err error
+	switch mode {
+	case middleware.CountExact:
+		count, err = h.queries.FilterCustomersCount(ctx, filter)
+	case middleware.CountEstimated:
+		count, err = estimateFilterCustomersCount(ctx, h.pool, filter)
+	default:
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &count, nil
+}
+
+// pageCustomers query the filtered customers of the offset or keyset page, it reports whether
+// more customers follow the page in the direction it was read
+func (h *customerHandler) pageCustomers(ctx context.Context, page *middleware.Paginator, filter db.FilterCustomersCountParams) ([]db.User, bool, error) {
+	var customers []db.User
+	var err error
+	switch {
+	case page.Cursor == nil:
+		customers, err = h.queries.FilterCustomers(ctx, db.FilterCustomersParams{
+			Limit:      page.Fetch(),
+			Offset:     page.Offset,
+			Status:     filter.Status,
+			JoinedFrom: filter.JoinedFrom,
+			JoinedTo:   filter.JoinedTo,
+			Query:      filter.Query,
+		})
+	case page.Backward():
+		joinDate, id, keyErr := cursorKeys(page.Cursor)
+		if keyErr != nil {
+			return nil, false, keyErr
+		}
+		customers, err = h.queries.FilterCustomersBefore(ctx, db.FilterCustomersBeforeParams{
+			Status:         filter.Status,
+			JoinedFrom:     filter.JoinedFrom,
+			JoinedTo:       filter.JoinedTo,
+			Query:          filter.Query,
+			BeforeJoinDate: joinDate,
+			BeforeID:       id,
+			PageSize:       page.Fetch(),
+		})
+	default:
+		joinDate, id, keyErr := cursorKeys(page.Cursor)
+		if keyErr != nil {
+			return nil, false, keyErr
+		}
+		customers, err = h.queries.FilterCustomersAfter(ctx, db.FilterCustomersAfterParams{
+			Status:        filter.Status,
+			JoinedFrom:    filter.JoinedFrom,
+			JoinedTo:      filter.JoinedTo,
+			Query:         filter.Query,
+			AfterJoinDate: joinDate,
+			AfterID:       id,
+			PageSize:      page.Fetch(),
+		})
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	customers, more := middleware.Page(page, customers)
+	return customers, more, nil
+}
+
+func (h *customerHandler) getCustomerInfo(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		util.ErrorResponseWriter(w, r, util.BadRequest("invalid id"))
+		return
+	}
+
+	customer, err := h.queries.GetCustomerById(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			util.ErrorResponseWriter(w, r, util.NotFound("resource not found"))
+			return
+		}
+
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	util.JsonResponseWriter(w, http.StatusOK, newCustomerInfo(customer))
+}
+
+func (h *customerHandler) updateCustomerInfo(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		util.ErrorResponseWriter(w, r, util.BadRequest("invalid id"))
+		return
+	}
+
+	customer, err := h.queries.GetCustomerById(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			util.ErrorResponseWriter(w, r, util.NotFound("resource not found"))
+			return
+		}
+
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	var input updateCustomer
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		util.ErrorResponseWriter(w, r, util.BadRequest("invalid request body"))
+		return
+	}
+
+	if err := h.validate.Struct(input); err != nil {
+		util.ErrorResponseWriter(w, r, util.ValidationFailed(err))
+		return
+	}
+
+	updated, err := h.queries.UpdateCustomer(ctx, db.UpdateCustomerParams{
+		ID:          customer.ID,
+		Name:        input.Name,
+		PhoneNumber: phoneText(input.PhoneNumber),
+	})
+	if err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	util.JsonResponseWriter(w, http.StatusOK, newCustomerInfo(updated))
+}
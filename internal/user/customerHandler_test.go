@@ -0,0 +1,37 @@
+package user
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseJoinDate(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		end  bool
+		want time.Time
+		err  bool
+	}{
+		{"date from", "2024-01-31", false, time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC), false},
+		{"date to include the whole day", "2024-01-31", true, time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), false},
+		{"date to at month end of leap year", "2024-02-29", true, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), false},
+		{"time from", "2024-01-31T10:00:00Z", false, time.Date(2024, 1, 31, 10, 0, 0, 0, time.UTC), false},
+		{"time to kept exact", "2024-01-31T10:00:00Z", true, time.Date(2024, 1, 31, 10, 0, 0, 0, time.UTC), false},
+		{"time with offset", "2024-01-31T10:00:00+02:00", true, time.Date(2024, 1, 31, 8, 0, 0, 0, time.UTC), false},
+		{"invalid date", "2024-13-01", false, time.Time{}, true},
+		{"not a date", "yesterday", true, time.Time{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseJoinDate(tt.raw, tt.end)
+			if (err != nil) != tt.err {
+				t.Fatalf("parseJoinDate(%q) error = %v, want error %v", tt.raw, err, tt.err)
+			}
+			if !tt.err && !got.Equal(tt.want) {
+				t.Errorf("parseJoinDate(%q) = %v, want %v", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package user
+
+import (
+	"github.com/bigusef/texorbit/internal/database"
+	"github.com/bigusef/texorbit/pkg/util"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"time"
+)
+
+type customerInfo struct {
+	ID                  uuid.UUID  `json:"id"`
+	Name                string     `json:"name"`
+	Email               string     `json:"email"`
+	PhoneNumber         string     `json:"phone_number"`
+	PhoneVerified       bool       `json:"phone_verified"`
+	Avatar              string     `json:"avatar"`
+	Status              string     `json:"status"`
+	StatusReason        string     `json:"status_reason,omitempty"`
+	JoinDate            time.Time  `json:"join_date"`
+	LastLogin           time.Time  `json:"last_login"`
+	SuspendedUntil      *time.Time `json:"suspended_until,omitempty"`
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at,omitempty"`
+	PreferredLanguage   string     `json:"preferred_language,omitempty"`
+}
+
+type updateProfile struct {
+	Name        string `json:"name" validate:"required,max=75"`
+	PhoneNumber string `json:"phone_number" validate:"omitempty,phone_number"`
+}
+
+// updateLanguage preferred language of the user, null clear it so Accept-Language is used again
+type updateLanguage struct {
+	Language *string `json:"language" validate:"omitempty,supported_locale"`
+}
+
+type updateCustomer struct {
+	Name        string `json:"name" validate:"required,max=75"`
+	PhoneNumber string `json:"phone_number" validate:"omitempty,phone_number"`
+}
+
+func newCustomerInfo(user database.User) customerInfo {
+	info := customerInfo{
+		ID:                user.ID,
+		Name:              user.Name,
+		Email:             user.Email,
+		PhoneNumber:       user.PhoneNumber.String,
+		PhoneVerified:     user.PhoneVerifiedAt.Valid,
+		Avatar:            user.Avatar.String,
+		Status:            string(user.Status),
+		StatusReason:      user.StatusReason.String,
+		JoinDate:          user.JoinDate.Time,
+		LastLogin:         user.LastLogin.Time,
+		PreferredLanguage: user.PreferredLanguage.String,
+	}
+
+	if user.SuspendedUntil.Valid {
+		info.SuspendedUntil = &user.SuspendedUntil.Time
+	}
+	if user.DeletionScheduledAt.Valid {
+		info.DeletionScheduledAt = &user.DeletionScheduledAt.Time
+	}
+
+	return info
+}
+
+// phoneText convert optional phone number input to its stored E.164 form
+func phoneText(number string) pgtype.Text {
+	if number == "" {
+		return pgtype.Text{}
+	}
+
+	normalized, err := util.NormalizePhone(number)
+	if err != nil {
+		return pgtype.Text{}
+	}
+
+	return pgtype.Text{String: normalized, Valid: true}
+}
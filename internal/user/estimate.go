@@ -0,0 +1,36 @@
+package user
+
+import (
+	"context"
+	db "github.com/bigusef/texorbit/internal/database"
+	"github.com/bigusef/texorbit/pkg/util"
+)
+
+// planner estimates of the count queries, the rows of AllStaffCount and FilterCustomersCount
+// selected without counting them. keep the conditions in sync with sql/queries/user.sql
+const (
+	estimateAllStaff = `EXPLAIN (FORMAT JSON)
+SELECT 1
+FROM users
+WHERE is_staff = TRUE`
+
+	estimateFilterCustomers = `EXPLAIN (FORMAT JSON)
+SELECT 1
+FROM users
+WHERE is_staff = FALSE
+  AND ($1::account_status IS NULL OR status = $1)
+  AND ($2::timestamptz IS NULL OR join_date >= $2)
+  AND ($3::timestamptz IS NULL OR join_date < $3)
+  AND ($4::text IS NULL
+    OR name ILIKE $4
+    OR email ILIKE $4
+    OR phone_number ILIKE $4)`
+)
+
+func estimateAllStaffCount(ctx context.Context, conn util.RowQuerier) (int64, error) {
+	return util.EstimateRows(ctx, conn, estimateAllStaff)
+}
+
+func estimateFilterCustomersCount(ctx context.Context, conn util.RowQuerier, arg db.FilterCustomersCountParams) (int64, error) {
+	return util.EstimateRows(ctx, conn, estimateFilterCustomers, arg.Status, arg.JoinedFrom, arg.JoinedTo, arg.Query)
+}
@@ -0,0 +1,202 @@
+package user
+
+import (
+	"encoding/json"
+	"errors"
+	db "github.com/bigusef/texorbit/internal/database"
+	"github.com/bigusef/texorbit/pkg/middleware"
+	"github.com/bigusef/texorbit/pkg/util"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"net/http"
+	"time"
+)
+
+const (
+	impersonationTTL = time.Minute * 10
+	// impersonationScope restricted scope of impersonation tokens, they carry no permissions and no refresh token
+	impersonationScope = "impersonation"
+)
+
+type impersonateInput struct {
+	Reason string `json:"reason" validate:"required,max=255"`
+}
+
+type impersonationInfo struct {
+	ID        uuid.UUID `json:"id"`
+	StaffID   uuid.UUID `json:"staff_id"`
+	UserID    uuid.UUID `json:"user_id"`
+	Reason    string    `json:"reason"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type impersonationRequestInfo struct {
+	Method    string    `json:"method"`
+	Path      string    `json:"path"`
+	Status    int32     `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func newImpersonationInfo(i db.Impersonation) impersonationInfo {
+	return impersonationInfo{
+		ID:        i.ID,
+		StaffID:   i.StaffID,
+		UserID:    i.UserID,
+		Reason:    i.Reason,
+		CreatedAt: i.CreatedAt.Time,
+		ExpiresAt: i.ExpiresAt.Time,
+	}
+}
+
+// impersonate mint short lived access token of customer account for the current staff member,
+// the token "act" claim identify the staff member and "sid" the audit record of this impersonation
+func (h *authHandler) impersonate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	staffId, err := currentUserId(r)
+	if err != nil {
+		util.ErrorResponseWriter(w, r, util.BadRequest("invalid user id"))
+		return
+	}
+
+	// impersonation tokens can not be used to start another impersonation
+	if middleware.IsImpersonating(ctx) {
+		util.ErrorResponseWriter(w, r, util.Forbidden("access denied"))
+		return
+	}
+
+	customerId, err := uuid.Parse(chi.URLParam(r, "userId"))
+	if err != nil {
+		util.ErrorResponseWriter(w, r, util.BadRequest("invalid user id"))
+		return
+	}
+
+	var input impersonateInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		util.ErrorResponseWriter(w, r, util.BadRequest("invalid request payload"))
+		return
+	}
+
+	if err := h.validate.Struct(input); err != nil {
+		util.ErrorResponseWriter(w, r, util.ValidationFailed(err))
+		return
+	}
+
+	customer, err := h.queries.GetCustomerById(ctx, customerId)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			util.ErrorResponseWriter(w, r, util.NotFound("customer not found"))
+			return
+		}
+
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	expiresAt := time.Now().Add(impersonationTTL)
+	impersonation, err := h.queries.CreateImpersonation(ctx, db.CreateImpersonationParams{
+		StaffID:   staffId,
+		UserID:    customer.ID,
+		Reason:    input.Reason,
+		ExpiresAt: pgtype.Timestamptz{Time: expiresAt, Valid: true},
+	})
+	if err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	_, accessToken, err := h.conf.AccessAuth.Encode(
+		map[string]interface{}{
+			"sub":   customer.ID.String(),
+			"sid":   impersonation.ID.String(),
+			"exp":   expiresAt.Unix(),
+			"staff": false,
+			"perms": []string{},
+			"scope": impersonationScope,
+			"act":   map[string]interface{}{"sub": staffId.String()},
+		},
+	)
+	if err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	util.JsonResponseWriter(w, http.StatusOK, map[string]interface{}{
+		"access_token":     accessToken,
+		"expires_at":       expiresAt,
+		"impersonation_id": impersonation.ID,
+	})
+}
+
+func (h *policyHandler) listImpersonations(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	page := ctx.Value("pagination").(*middleware.Paginator)
+
+	impersonations, err := h.queries.AllImpersonations(ctx, db.AllImpersonationsParams{Limit: page.Limit, Offset: page.Offset})
+	if err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	result := make([]impersonationInfo, len(impersonations))
+	for i, v := range impersonations {
+		result[i] = newImpersonationInfo(v)
+	}
+
+	count, err := h.queries.AllImpersonationsCount(ctx)
+	if err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	util.JsonListResponseWriter(w, http.StatusOK, result, count)
+}
+
+func (h *policyHandler) getImpersonation(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		util.ErrorResponseWriter(w, r, util.BadRequest("invalid impersonation id"))
+		return
+	}
+
+	impersonation, err := h.queries.GetImpersonation(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			util.ErrorResponseWriter(w, r, util.NotFound("impersonation not found"))
+			return
+		}
+
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	requests, err := h.queries.ImpersonationRequests(ctx, id)
+	if err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	result := struct {
+		impersonationInfo
+		Requests []impersonationRequestInfo `json:"requests"`
+	}{
+		impersonationInfo: newImpersonationInfo(impersonation),
+		Requests:          make([]impersonationRequestInfo, len(requests)),
+	}
+	for i, v := range requests {
+		result.Requests[i] = impersonationRequestInfo{
+			Method:    v.Method,
+			Path:      v.Path,
+			Status:    v.Status,
+			CreatedAt: v.CreatedAt.Time,
+		}
+	}
+
+	util.JsonResponseWriter(w, http.StatusOK, result)
+}
@@ -0,0 +1,138 @@
+package user
+
+import (
+	"context"
+	db "github.com/bigusef/texorbit/internal/database"
+	"github.com/bigusef/texorbit/pkg/middleware"
+	"github.com/bigusef/texorbit/pkg/oidc"
+	"github.com/bigusef/texorbit/pkg/util"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// login methods and failure reasons recorded in login history
+const (
+	loginMethodMagicLink = "magic_link"
+	loginMethodMfa       = "mfa"
+
+	loginFailureInactive   = "inactive_account"
+	loginFailureNotAllowed = "not_allowed"
+	loginFailureInvalidMfa = "invalid_mfa_code"
+)
+
+type loginEventInfo struct {
+	Method        string    `json:"method"`
+	Success       bool      `json:"success"`
+	FailureReason string    `json:"failure_reason,omitempty"`
+	IpAddress     string    `json:"ip_address"`
+	UserAgent     string    `json:"user_agent"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+func newLoginEventInfo(e db.LoginEvent) loginEventInfo {
+	return loginEventInfo{
+		Method:        e.Method,
+		Success:       e.Success,
+		FailureReason: e.FailureReason,
+		IpAddress:     e.IpAddress,
+		UserAgent:     e.UserAgent,
+		CreatedAt:     e.CreatedAt.Time,
+	}
+}
+
+func oidcLoginMethod(provider string) string {
+	return "oidc:" + provider
+}
+
+// recordLoginEvent write login attempt to the login history, userId is nil for emails without account
+func (h *authHandler) recordLoginEvent(ctx context.Context, r *http.Request, email string, userId uuid.UUID, method, failure string) {
+	_, userAgent, ip := clientInfo(r)
+
+	err := h.queries.CreateLoginEvent(ctx, db.CreateLoginEventParams{
+		UserID:        pgtype.UUID{Bytes: userId, Valid: userId != uuid.Nil},
+		Email:         email,
+		Method:        method,
+		Success:       failure == "",
+		FailureReason: failure,
+		IpAddress:     ip,
+		UserAgent:     userAgent,
+	})
+	if err != nil {
+		slog.Error("failed to record login event", "email", email, "error", err)
+	}
+}
+
+// completeLogin update the user last login, refresh name and avatar from the identity provider when given,
+// and record the successful login
+func (h *authHandler) completeLogin(ctx context.Context, r *http.Request, user db.User, method string, identity *oidc.Identity) (db.User, error) {
+	user, err := activatePendingUser(ctx, h.queries, user)
+	if err != nil {
+		return user, err
+	}
+
+	params := db.RecordUserLoginParams{ID: user.ID}
+	if identity != nil {
+		params.Name = pgtype.Text{String: identity.Name, Valid: identity.Name != ""}
+		params.Avatar = pgtype.Text{String: identity.Picture, Valid: identity.Picture != ""}
+	}
+
+	updated, err := h.queries.RecordUserLogin(ctx, params)
+	if err != nil {
+		return user, err
+	}
+
+	loginSucceeded(ctx, h.queries, user.Email)
+	h.recordLoginEvent(ctx, r, user.Email, user.ID, method, "")
+
+	return updated, nil
+}
+
+// writeLoginEvents write page of the user login history
+func writeLoginEvents(w http.ResponseWriter, r *http.Request, queries *db.Queries, userId uuid.UUID) {
+	ctx := r.Context()
+
+	page := ctx.Value("pagination").(*middleware.Paginator)
+	id := pgtype.UUID{Bytes: userId, Valid: true}
+
+	events, err := queries.UserLoginEvents(ctx, db.UserLoginEventsParams{UserID: id, Limit: page.Limit, Offset: page.Offset})
+	if err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	result := make([]loginEventInfo, len(events))
+	for i, e := range events {
+		result[i] = newLoginEventInfo(e)
+	}
+
+	count, err := queries.UserLoginEventsCount(ctx, id)
+	if err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	util.JsonListResponseWriter(w, http.StatusOK, result, count)
+}
+
+func (h *customerHandler) listCustomerLoginEvents(w http.ResponseWriter, r *http.Request) {
+	customer, ok := h.customerFromURL(w, r)
+	if !ok {
+		return
+	}
+
+	writeLoginEvents(w, r, h.queries, customer.ID)
+}
+
+func (h *staffHandler) listStaffLoginEvents(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		util.ErrorResponseWriter(w, r, util.BadRequest("invalid user id"))
+		return
+	}
+
+	writeLoginEvents(w, r, h.queries, id)
+}
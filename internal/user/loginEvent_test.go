@@ -0,0 +1,204 @@
+package user
+
+import (
+	"context"
+	db "github.com/bigusef/texorbit/internal/database"
+	"github.com/bigusef/texorbit/pkg/oidc"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"net/http/httptest"
+	"testing"
+)
+
+type dbCall struct {
+	name string
+	args []interface{}
+}
+
+// recordingDB record every query by name, rows answer QueryRow calls and missing rows are pgx.ErrNoRows
+type recordingDB struct {
+	calls []dbCall
+	rows  map[string]func(args []interface{}) pgx.Row
+}
+
+func (f *recordingDB) Exec(_ context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	f.calls = append(f.calls, dbCall{queryName(sql), args})
+	return pgconn.NewCommandTag("UPDATE 1"), nil
+}
+
+func (f *recordingDB) Query(_ context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	f.calls = append(f.calls, dbCall{queryName(sql), args})
+	return nil, pgx.ErrNoRows
+}
+
+func (f *recordingDB) QueryRow(_ context.Context, sql string, args ...interface{}) pgx.Row {
+	name := queryName(sql)
+	f.calls = append(f.calls, dbCall{name, args})
+	if row, ok := f.rows[name]; ok {
+		return row(args)
+	}
+	return fakeRow{err: pgx.ErrNoRows}
+}
+
+// called return the arguments of the calls of the query
+func (f *recordingDB) called(name string) [][]interface{} {
+	var args [][]interface{}
+	for _, call := range f.calls {
+		if call.name == name {
+			args = append(args, call.args)
+		}
+	}
+	return args
+}
+
+// userRow row of users columns
+func userRow(user db.User) pgx.Row {
+	return fakeRow{scan: func(dest ...interface{}) {
+		*dest[0].(*uuid.UUID) = user.ID
+		*dest[1].(*string) = user.Name
+		*dest[2].(*string) = user.Email
+		*dest[3].(*pgtype.Text) = user.PhoneNumber
+		*dest[4].(*pgtype.Text) = user.Avatar
+		*dest[5].(*db.AccountStatus) = user.Status
+		*dest[6].(*bool) = user.IsStaff
+		*dest[7].(*pgtype.Timestamptz) = user.JoinDate
+		*dest[8].(*pgtype.Timestamptz) = user.LastLogin
+		*dest[9].(*pgtype.Text) = user.StatusReason
+		*dest[10].(*pgtype.Timestamptz) = user.PhoneVerifiedAt
+		*dest[11].(*pgtype.Timestamptz) = user.DeletionScheduledAt
+		*dest[12].(*pgtype.Timestamptz) = user.SuspendedUntil
+		*dest[13].(*pgtype.Text) = user.AvatarKey
+		*dest[14].(*pgtype.Text) = user.PreferredLanguage
+	}}
+}
+
+func TestCompleteLogin(t *testing.T) {
+	tests := []struct {
+		name         string
+		status       db.AccountStatus
+		identity     *oidc.Identity
+		wantActivate bool
+		wantName     pgtype.Text
+		wantAvatar   pgtype.Text
+	}{
+		{
+			name:   "magic link keep the profile",
+			status: db.AccountStatusActive,
+		},
+		{
+			name:       "identity provider sync name and picture",
+			status:     db.AccountStatusActive,
+			identity:   &oidc.Identity{Provider: "google", Name: "Mona Adel", Picture: "https://idp.example/mona.png"},
+			wantName:   pgtype.Text{String: "Mona Adel", Valid: true},
+			wantAvatar: pgtype.Text{String: "https://idp.example/mona.png", Valid: true},
+		},
+		{
+			name:     "empty identity claims keep the profile",
+			status:   db.AccountStatusActive,
+			identity: &oidc.Identity{Provider: "google"},
+		},
+		{
+			name:         "first login activate pending account",
+			status:       db.AccountStatusPendingVerification,
+			wantActivate: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := db.User{ID: uuid.New(), Name: "Mona", Email: "mona@example.com", Status: tt.status}
+			fake := &recordingDB{rows: map[string]func([]interface{}) pgx.Row{
+				"ChangeUserStatus": func([]interface{}) pgx.Row {
+					activated := user
+					activated.Status = db.AccountStatusActive
+					return userRow(activated)
+				},
+				"RecordUserLogin": func(args []interface{}) pgx.Row {
+					updated := user
+					updated.Status = db.AccountStatusActive
+					if name := args[0].(pgtype.Text); name.Valid {
+						updated.Name = name.String
+					}
+					updated.LastLogin = pgtype.Timestamptz{Valid: true}
+					return userRow(updated)
+				},
+			}}
+			h := &authHandler{queries: db.New(fake)}
+			r := httptest.NewRequest("POST", "/auth/staff-login", nil)
+			r.RemoteAddr = "203.0.113.7:4321"
+			r.Header.Set("User-Agent", "texorbit-test")
+
+			updated, err := h.completeLogin(context.Background(), r, user, loginMethodMagicLink, tt.identity)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if activations := fake.called("ChangeUserStatus"); (len(activations) == 1) != tt.wantActivate {
+				t.Errorf("ChangeUserStatus calls = %v, want activation %v", activations, tt.wantActivate)
+			}
+
+			logins := fake.called("RecordUserLogin")
+			if len(logins) != 1 {
+				t.Fatalf("RecordUserLogin calls = %d, want 1", len(logins))
+			}
+			if logins[0][0] != tt.wantName || logins[0][1] != tt.wantAvatar || logins[0][2] != user.ID {
+				t.Errorf("RecordUserLogin args = %v, want name %v and avatar %v", logins[0], tt.wantName, tt.wantAvatar)
+			}
+			if !updated.LastLogin.Valid || updated.Status != db.AccountStatusActive {
+				t.Errorf("completeLogin returned %+v, want the updated user", updated)
+			}
+
+			if cleared := fake.called("ClearLoginFailures"); len(cleared) != 1 || cleared[0][0] != accountThrottleKey(user.Email) {
+				t.Errorf("ClearLoginFailures calls = %v, want the account key", cleared)
+			}
+
+			events := fake.called("CreateLoginEvent")
+			if len(events) != 1 {
+				t.Fatalf("CreateLoginEvent calls = %d, want 1", len(events))
+			}
+			want := []interface{}{pgtype.UUID{Bytes: user.ID, Valid: true}, user.Email, loginMethodMagicLink, true, "", "203.0.113.7", "texorbit-test"}
+			for i := range want {
+				if events[0][i] != want[i] {
+					t.Errorf("login event arg %d = %v, want %v", i, events[0][i], want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestRecordLoginEventFailure(t *testing.T) {
+	tests := []struct {
+		name   string
+		userId uuid.UUID
+		method string
+		reason string
+	}{
+		{"unknown email", uuid.Nil, oidcLoginMethod("google"), loginFailureNotAllowed},
+		{"inactive account", uuid.New(), loginMethodMagicLink, loginFailureInactive},
+		{"wrong mfa code", uuid.New(), loginMethodMfa, loginFailureInvalidMfa},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &recordingDB{}
+			h := &authHandler{queries: db.New(fake)}
+			r := httptest.NewRequest("POST", "/auth/login", nil)
+
+			h.recordLoginEvent(context.Background(), r, "user@example.com", tt.userId, tt.method, tt.reason)
+
+			events := fake.called("CreateLoginEvent")
+			if len(events) != 1 {
+				t.Fatalf("CreateLoginEvent calls = %d, want 1", len(events))
+			}
+			args := events[0]
+			if userId := args[0].(pgtype.UUID); userId.Valid != (tt.userId != uuid.Nil) || (userId.Valid && userId.Bytes != tt.userId) {
+				t.Errorf("user id = %v, want %v", userId, tt.userId)
+			}
+			if args[2] != tt.method || args[3] != false || args[4] != tt.reason {
+				t.Errorf("login event = %v, want failed %s with %s", args, tt.method, tt.reason)
+			}
+		})
+	}
+}
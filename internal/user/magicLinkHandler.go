@@ -0,0 +1,187 @@
+package user
+
+import (
+	"encoding/json"
+	"errors"
+	db "github.com/bigusef/texorbit/internal/database"
+	"github.com/bigusef/texorbit/pkg/i18n"
+	"github.com/bigusef/texorbit/pkg/notify"
+	"github.com/bigusef/texorbit/pkg/util"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const (
+	magicLinkTTL        = time.Minute * 15
+	magicLinkMaxPerHour = 5
+	magicLinkPurpose    = "magic_link"
+)
+
+var errInvalidMagicLink = errors.New("login link is invalid, expired or already used")
+
+type magicLinkRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type magicLinkVerify struct {
+	Token string `json:"token" validate:"required"`
+}
+
+// requestMagicLink email single use login link to the given address,
+// the response is the same whether the account exist or not so it can not be used to discover emails
+func (h *authHandler) requestMagicLink(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var input magicLinkRequest
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		util.ErrorResponseWriter(w, r, util.BadRequest("invalid request payload"))
+		return
+	}
+
+	if err := h.validate.Struct(input); err != nil {
+		util.ErrorResponseWriter(w, r, util.ValidationFailed(err))
+		return
+	}
+	email := util.NormalizeEmail(input.Email)
+
+	if err := h.queries.DeleteExpiredMagicLinks(ctx); err != nil {
+		slog.Error("failed to delete expired magic links", "error", err)
+	}
+
+	count, err := h.queries.MagicLinkCountSince(ctx, db.MagicLinkCountSinceParams{
+		Email: email,
+		Since: pgtype.Timestamptz{Time: time.Now().Add(-time.Hour), Valid: true},
+	})
+	if err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+	if count >= magicLinkMaxPerHour {
+		util.ErrorResponseWriter(w, r, util.TooManyRequests("too many login links requested, try again later"))
+		return
+	}
+
+	// the link is signed like our tokens, and its hash is stored to make it single use
+	token, err := h.signPurposeToken(magicLinkPurpose, email, magicLinkTTL)
+	if err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	err = h.queries.CreateMagicLink(ctx, db.CreateMagicLinkParams{
+		TokenHash: hashToken(token),
+		Email:     email,
+		ExpiresAt: pgtype.Timestamptz{Time: time.Now().Add(magicLinkTTL), Valid: true},
+	})
+	if err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	// existing accounts get the email in their preferred language
+	locale := i18n.FromContext(ctx)
+	if user, err := h.queries.GetUserByEmail(ctx, email); err == nil && user.PreferredLanguage.Valid {
+		locale = user.PreferredLanguage.String
+	}
+
+	subject, body, err := notify.Render("magic_link", locale, map[string]interface{}{
+		"Link":      h.conf.MagicLinkURL + "?token=" + url.QueryEscape(token),
+		"ExpiresIn": int(magicLinkTTL.Minutes()),
+	})
+	if err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	if err := h.conf.Mailer.Send(ctx, notify.Message{To: email, Subject: subject, Body: body}); err != nil {
+		slog.Error("failed to send magic link", "error", err)
+		util.ErrorResponseWriter(w, r, util.NewError(http.StatusBadGateway, util.CodeUnavailable, "failed to send login link"))
+		return
+	}
+
+	util.JsonResponseWriter(w, http.StatusAccepted, nil)
+}
+
+// verifyMagicLink exchange a login link token for new session tokens, creating customer account on first login
+func (h *authHandler) verifyMagicLink(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var input magicLinkVerify
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		util.ErrorResponseWriter(w, r, util.BadRequest("invalid request payload"))
+		return
+	}
+
+	if err := h.validate.Struct(input); err != nil {
+		util.ErrorResponseWriter(w, r, util.ValidationFailed(err))
+		return
+	}
+
+	ipKey := ipThrottleKey(r)
+	if checkLoginLocked(w, r, h.queries, ipKey) {
+		return
+	}
+
+	subject, err := h.verifyPurposeToken(input.Token, magicLinkPurpose)
+	if err != nil {
+		loginFailed(ctx, h.queries, ipKey)
+		util.ErrorResponseWriter(w, r, util.Unauthorized(errInvalidMagicLink.Error()))
+		return
+	}
+
+	// signed tokens carry the email, so replayed links count against the account too
+	accountKey := accountThrottleKey(subject)
+	if checkLoginLocked(w, r, h.queries, accountKey) {
+		return
+	}
+
+	email, err := h.queries.ConsumeMagicLink(ctx, hashToken(input.Token))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			loginFailed(ctx, h.queries, ipKey, accountKey)
+			util.ErrorResponseWriter(w, r, util.Unauthorized(errInvalidMagicLink.Error()))
+			return
+		}
+
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	user, err := h.getOrCreateCustomer(ctx, email, "", "")
+	if err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	// staff login only through staffLogin, which asks for their second factor
+	if user.IsStaff {
+		loginFailed(ctx, h.queries, ipKey, accountKey)
+		h.recordLoginEvent(ctx, r, user.Email, user.ID, loginMethodMagicLink, loginFailureNotAllowed)
+		util.ErrorResponseWriter(w, r, util.Unauthorized(errLoginFailed.Error()))
+		return
+	}
+
+	if !user.IsActive() {
+		loginFailed(ctx, h.queries, ipKey, accountKey)
+		h.recordLoginEvent(ctx, r, user.Email, user.ID, loginMethodMagicLink, loginFailureInactive)
+		util.ErrorResponseWriter(w, r, util.Forbidden("There are issue in your account, please contact with support."))
+		return
+	}
+
+	if user, err = h.completeLogin(ctx, r, user, loginMethodMagicLink, nil); err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	tokens, err := h.startSession(ctx, r, user)
+	if err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	util.JsonResponseWriter(w, http.StatusOK, newLoginResponse(user, tokens))
+}
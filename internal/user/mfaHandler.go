@@ -0,0 +1,423 @@
+package user
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"encoding/json"
+	"errors"
+	db "github.com/bigusef/texorbit/internal/database"
+	"github.com/bigusef/texorbit/pkg/otp"
+	"github.com/bigusef/texorbit/pkg/totp"
+	"github.com/bigusef/texorbit/pkg/util"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	mfaIssuer          = "TexOrbit"
+	mfaPendingPurpose  = "mfa_pending"
+	mfaTokenTTL        = time.Minute * 5
+	mfaMaxAttempts     = 5
+	mfaLockout         = time.Minute * 15
+	recoveryCodesCount = 10
+
+	// staffMfaPolicy security policy key that force all staff to enroll two factor authentication
+	staffMfaPolicy = "staff_mfa_required"
+)
+
+var (
+	errMfaNotEnabled  = errors.New("two factor authentication is not enabled")
+	errInvalidMfaCode = errors.New("invalid authentication code")
+)
+
+type mfaCodeInput struct {
+	Code string `json:"code" validate:"required,numeric,len=6"`
+}
+
+type mfaVerifyInput struct {
+	MfaToken     string `json:"mfa_token" validate:"required"`
+	Code         string `json:"code" validate:"required_without=RecoveryCode,omitempty,numeric,len=6"`
+	RecoveryCode string `json:"recovery_code" validate:"required_without=Code"`
+}
+
+// staffMfaSetupRequired report whether the policy require two factor authentication and the user did not enable it yet
+func (h *authHandler) staffMfaSetupRequired(ctx context.Context, userId uuid.UUID) (bool, error) {
+	required, err := h.queries.GetSecurityPolicy(ctx, staffMfaPolicy)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return false, err
+	}
+	if !required {
+		return false, nil
+	}
+
+	mfa, err := h.queries.GetUserMfa(ctx, userId)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return true, nil
+		}
+		return false, err
+	}
+
+	return !mfa.EnabledAt.Valid, nil
+}
+
+// checkMfaCode validate the authenticator code, every code accepted only once
+func (h *authHandler) checkMfaCode(ctx context.Context, mfa db.UserMfa, code string) (bool, error) {
+	counter, ok := totp.Validate(mfa.Secret, code, time.Now())
+	if !ok {
+		return false, nil
+	}
+
+	affected, err := h.queries.UseMfaCounter(ctx, db.UseMfaCounterParams{Counter: counter, UserID: mfa.UserID})
+	return affected == 1, err
+}
+
+// newRecoveryCodes replace the user recovery codes, only their hashes are stored so they are shown once
+func (h *authHandler) newRecoveryCodes(ctx context.Context, userId uuid.UUID) ([]string, error) {
+	codes := make([]string, recoveryCodesCount)
+	hashes := make([]string, recoveryCodesCount)
+	for i := range codes {
+		buf := make([]byte, 5)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, err
+		}
+
+		code := strings.ToLower(base32.StdEncoding.EncodeToString(buf))
+		codes[i] = code[:4] + "-" + code[4:]
+		hashes[i] = otp.Hash(h.conf.OTPSecret, userId.String(), code)
+	}
+
+	if err := h.queries.DeleteRecoveryCodes(ctx, userId); err != nil {
+		return nil, err
+	}
+
+	err := h.queries.CreateRecoveryCodes(ctx, db.CreateRecoveryCodesParams{UserID: userId, CodeHashes: hashes})
+	if err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// useRecoveryCode consume one of the user recovery codes, dashes and case are ignored
+func (h *authHandler) useRecoveryCode(ctx context.Context, userId uuid.UUID, code string) (bool, error) {
+	code = strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+
+	// codes issued before the hashes were keyed are accepted until they are used or regenerated
+	for _, hash := range []string{otp.Hash(h.conf.OTPSecret, userId.String(), code), otp.LegacyHash(userId.String(), code)} {
+		affected, err := h.queries.UseRecoveryCode(ctx, db.UseRecoveryCodeParams{UserID: userId, CodeHash: hash})
+		if err != nil || affected == 1 {
+			return affected == 1, err
+		}
+	}
+
+	return false, nil
+}
+
+// currentStaff return the authenticated user, only staff members can manage two factor authentication
+func (h *authHandler) currentStaff(w http.ResponseWriter, r *http.Request) (db.User, bool) {
+	userId, err := currentUserId(r)
+	if err != nil {
+		util.ErrorResponseWriter(w, r, util.BadRequest("invalid user id"))
+		return db.User{}, false
+	}
+
+	user, err := h.queries.GetUSerById(r.Context(), userId)
+	if err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return db.User{}, false
+	}
+
+	if !user.IsStaff {
+		util.ErrorResponseWriter(w, r, util.Forbidden("access denied"))
+		return db.User{}, false
+	}
+
+	return user, true
+}
+
+// setupMfa generate new authenticator secret, it is not required at login until activated with valid code
+func (h *authHandler) setupMfa(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.currentStaff(w, r)
+	if !ok {
+		return
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	mfa, err := h.queries.SetupUserMfa(r.Context(), db.SetupUserMfaParams{UserID: user.ID, Secret: secret})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			util.ErrorResponseWriter(w, r, util.Conflict("two factor authentication already enabled"))
+			return
+		}
+
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	util.JsonResponseWriter(w, http.StatusOK, map[string]string{
+		"secret":           mfa.Secret,
+		"provisioning_uri": totp.ProvisioningURI(mfa.Secret, mfaIssuer, user.Email),
+	})
+}
+
+// activateMfa confirm the enrolled secret with code from the authenticator app and return the recovery codes
+func (h *authHandler) activateMfa(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, ok := h.currentStaff(w, r)
+	if !ok {
+		return
+	}
+
+	var input mfaCodeInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		util.ErrorResponseWriter(w, r, util.BadRequest("invalid request payload"))
+		return
+	}
+
+	if err := h.validate.Struct(input); err != nil {
+		util.ErrorResponseWriter(w, r, util.ValidationFailed(err))
+		return
+	}
+
+	mfa, err := h.queries.GetUserMfa(ctx, user.ID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			util.ErrorResponseWriter(w, r, util.NotFound("two factor authentication setup is not started"))
+			return
+		}
+
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+	if mfa.EnabledAt.Valid {
+		util.ErrorResponseWriter(w, r, util.Conflict("two factor authentication already enabled"))
+		return
+	}
+
+	counter, valid := totp.Validate(mfa.Secret, input.Code, time.Now())
+	if !valid {
+		util.ErrorResponseWriter(w, r, util.Invalid(map[string]string{"code": "invalid"}))
+		return
+	}
+
+	if err := h.queries.EnableUserMfa(ctx, db.EnableUserMfaParams{LastCounter: counter, UserID: user.ID}); err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	codes, err := h.newRecoveryCodes(ctx, user.ID)
+	if err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	util.JsonResponseWriter(w, http.StatusOK, map[string][]string{"recovery_codes": codes})
+}
+
+// regenerateRecoveryCodes replace all recovery codes, old codes stop working
+func (h *authHandler) regenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	mfa, ok := h.confirmMfa(w, r)
+	if !ok {
+		return
+	}
+
+	codes, err := h.newRecoveryCodes(r.Context(), mfa.UserID)
+	if err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	util.JsonResponseWriter(w, http.StatusOK, map[string][]string{"recovery_codes": codes})
+}
+
+// disableMfa remove the authenticator secret and recovery codes, not allowed while the policy require it
+func (h *authHandler) disableMfa(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	required, err := h.queries.GetSecurityPolicy(ctx, staffMfaPolicy)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+	if required {
+		util.ErrorResponseWriter(w, r, util.Forbidden("two factor authentication is required for staff accounts"))
+		return
+	}
+
+	mfa, ok := h.confirmMfa(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.queries.DeleteUserMfa(ctx, mfa.UserID); err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+	if err := h.queries.DeleteRecoveryCodes(ctx, mfa.UserID); err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	util.JsonResponseWriter(w, http.StatusNoContent, nil)
+}
+
+// confirmMfa require valid authenticator code from the current staff before changing enabled two factor settings
+func (h *authHandler) confirmMfa(w http.ResponseWriter, r *http.Request) (db.UserMfa, bool) {
+	ctx := r.Context()
+	user, ok := h.currentStaff(w, r)
+	if !ok {
+		return db.UserMfa{}, false
+	}
+
+	var input mfaCodeInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		util.ErrorResponseWriter(w, r, util.BadRequest("invalid request payload"))
+		return db.UserMfa{}, false
+	}
+
+	if err := h.validate.Struct(input); err != nil {
+		util.ErrorResponseWriter(w, r, util.ValidationFailed(err))
+		return db.UserMfa{}, false
+	}
+
+	mfa, err := h.queries.GetUserMfa(ctx, user.ID)
+	if (err != nil && errors.Is(err, pgx.ErrNoRows)) || (err == nil && !mfa.EnabledAt.Valid) {
+		util.ErrorResponseWriter(w, r, util.NotFound(errMfaNotEnabled.Error()))
+		return db.UserMfa{}, false
+	}
+	if err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return db.UserMfa{}, false
+	}
+
+	valid, err := h.checkMfaCode(ctx, mfa, input.Code)
+	if err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return db.UserMfa{}, false
+	}
+	if !valid {
+		util.ErrorResponseWriter(w, r, util.Invalid(map[string]string{"code": "invalid"}))
+		return db.UserMfa{}, false
+	}
+
+	return mfa, true
+}
+
+// verifyMfa complete staff login started with "mfa_pending" token using authenticator or recovery code
+func (h *authHandler) verifyMfa(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var input mfaVerifyInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		util.ErrorResponseWriter(w, r, util.BadRequest("invalid request payload"))
+		return
+	}
+
+	if err := h.validate.Struct(input); err != nil {
+		util.ErrorResponseWriter(w, r, util.ValidationFailed(err))
+		return
+	}
+
+	ipKey := ipThrottleKey(r)
+	if checkLoginLocked(w, r, h.queries, ipKey) {
+		return
+	}
+
+	subject, err := h.verifyPurposeToken(input.MfaToken, mfaPendingPurpose)
+	if err != nil {
+		loginFailed(ctx, h.queries, ipKey)
+		util.ErrorResponseWriter(w, r, util.Unauthorized(err.Error()))
+		return
+	}
+	userId, err := uuid.Parse(subject)
+	if err != nil {
+		util.ErrorResponseWriter(w, r, util.Unauthorized(errInvalidPurposeToken.Error()))
+		return
+	}
+
+	user, err := h.queries.GetUSerById(ctx, userId)
+	if err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+	if !user.IsActive() || !user.IsStaff {
+		util.ErrorResponseWriter(w, r, util.Forbidden("There are issue in your account, Contact with your IT support."))
+		return
+	}
+
+	mfa, err := h.queries.GetUserMfa(ctx, user.ID)
+	if err != nil || !mfa.EnabledAt.Valid {
+		util.ErrorResponseWriter(w, r, util.Unauthorized(errMfaNotEnabled.Error()))
+		return
+	}
+
+	accountKey := accountThrottleKey(user.Email)
+	if checkLoginLocked(w, r, h.queries, accountKey) {
+		return
+	}
+
+	// failed attempts limit guessing codes across all pending tokens of the user until mfaLockout pass.
+	// the attempt is counted before checking the code so parallel guesses can not pass the limit,
+	// accepted codes reset the count
+	_, err = h.queries.IncrementMfaFailures(ctx, db.IncrementMfaFailuresParams{
+		WindowStart: pgtype.Timestamptz{Time: time.Now().Add(-mfaLockout), Valid: true},
+		UserID:      user.ID,
+		MaxAttempts: mfaMaxAttempts,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			util.ErrorResponseWriter(w, r, util.TooManyRequests("too many failed attempts, try again later"))
+			return
+		}
+
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	var valid bool
+	if input.Code != "" {
+		valid, err = h.checkMfaCode(ctx, mfa, input.Code)
+	} else {
+		valid, err = h.useRecoveryCode(ctx, user.ID, input.RecoveryCode)
+	}
+	if err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	if !valid {
+		loginFailed(ctx, h.queries, ipKey, accountKey)
+		h.recordLoginEvent(ctx, r, user.Email, user.ID, loginMethodMfa, loginFailureInvalidMfa)
+		util.ErrorResponseWriter(w, r, util.Unauthorized(errInvalidMfaCode.Error()))
+		return
+	}
+
+	if err := h.queries.ResetMfaFailures(ctx, user.ID); err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	if user, err = h.completeLogin(ctx, r, user, loginMethodMfa, nil); err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	tokens, err := h.startSession(ctx, r, user)
+	if err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	util.JsonResponseWriter(w, http.StatusOK, newLoginResponse(user, tokens))
+}
@@ -0,0 +1,172 @@
+package user
+
+import (
+	"encoding/json"
+	"errors"
+	db "github.com/bigusef/texorbit/internal/database"
+	"github.com/bigusef/texorbit/pkg/i18n"
+	"github.com/bigusef/texorbit/pkg/otp"
+	"github.com/bigusef/texorbit/pkg/util"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type phoneOtpRequest struct {
+	PhoneNumber string `json:"phone_number" validate:"required,phone_number"`
+}
+
+type phoneOtpVerify struct {
+	Code string `json:"code" validate:"required,numeric,len=6"`
+}
+
+func (h *customerHandler) requestPhoneOtp(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userId, err := currentUserId(r)
+	if err != nil {
+		util.ErrorResponseWriter(w, r, util.BadRequest("invalid user id"))
+		return
+	}
+
+	var input phoneOtpRequest
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		util.ErrorResponseWriter(w, r, util.BadRequest("invalid request body"))
+		return
+	}
+
+	if err := h.validate.Struct(input); err != nil {
+		util.ErrorResponseWriter(w, r, util.ValidationFailed(err))
+		return
+	}
+	phone, _ := util.NormalizePhone(input.PhoneNumber)
+
+	// resend throttling, one code per interval and limited number of codes per hour
+	last, err := h.queries.LastPhoneOtp(ctx, userId)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+	if wait := otp.ResendInterval - time.Since(last.CreatedAt.Time); err == nil && wait > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(wait.Seconds())+1))
+		util.ErrorResponseWriter(w, r, util.TooManyRequests("please wait before requesting new code"))
+		return
+	}
+
+	count, err := h.queries.PhoneOtpCountSince(ctx, db.PhoneOtpCountSinceParams{
+		UserID: userId,
+		Since:  pgtype.Timestamptz{Time: time.Now().Add(-time.Hour), Valid: true},
+	})
+	if err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+	if count >= otp.MaxPerHour {
+		util.ErrorResponseWriter(w, r, util.TooManyRequests("too many codes requested, try again later"))
+		return
+	}
+
+	code, err := otp.GenerateCode()
+	if err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	// only the latest code can be used
+	if err := h.queries.ConsumePhoneOtps(ctx, userId); err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	_, err = h.queries.CreatePhoneOtp(ctx, db.CreatePhoneOtpParams{
+		UserID:      userId,
+		PhoneNumber: phone,
+		CodeHash:    otp.Hash(h.conf.OTPSecret, phone, code),
+		ExpiresAt:   pgtype.Timestamptz{Time: time.Now().Add(otp.CodeTTL), Valid: true},
+	})
+	if err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	message := i18n.T(i18n.FromContext(ctx), "sms.phone_otp", code)
+	if err := h.conf.SMSSender.Send(ctx, phone, message); err != nil {
+		slog.Error("failed to send verification sms", "error", err)
+		util.ErrorResponseWriter(w, r, util.NewError(http.StatusBadGateway, util.CodeUnavailable, "failed to send verification code"))
+		return
+	}
+
+	util.JsonResponseWriter(w, http.StatusAccepted, map[string]interface{}{
+		"phone_number": phone,
+		"expires_in":   int(otp.CodeTTL.Seconds()),
+	})
+}
+
+func (h *customerHandler) verifyPhoneOtp(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userId, err := currentUserId(r)
+	if err != nil {
+		util.ErrorResponseWriter(w, r, util.BadRequest("invalid user id"))
+		return
+	}
+
+	var input phoneOtpVerify
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		util.ErrorResponseWriter(w, r, util.BadRequest("invalid request body"))
+		return
+	}
+
+	if err := h.validate.Struct(input); err != nil {
+		util.ErrorResponseWriter(w, r, util.ValidationFailed(err))
+		return
+	}
+
+	pending, err := h.queries.ActivePhoneOtp(ctx, userId)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			util.ErrorResponseWriter(w, r, util.Invalid(map[string]string{"code": "otp_expired"}))
+			return
+		}
+
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	// the attempt is used before checking the code, so parallel guesses can not pass the attempts limit
+	_, err = h.queries.IncrementPhoneOtpAttempts(ctx, db.IncrementPhoneOtpAttemptsParams{
+		ID:          pending.ID,
+		MaxAttempts: otp.MaxAttempts,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			util.ErrorResponseWriter(w, r, util.TooManyRequests("too many wrong attempts, request new code"))
+			return
+		}
+
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	if !otp.Verify(h.conf.OTPSecret, pending.CodeHash, pending.PhoneNumber, input.Code) {
+		util.ErrorResponseWriter(w, r, util.Invalid(map[string]string{"code": "invalid"}))
+		return
+	}
+
+	if err := h.queries.ConsumePhoneOtps(ctx, userId); err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	user, err := h.queries.SetUserPhoneVerified(ctx, db.SetUserPhoneVerifiedParams{
+		ID:          userId,
+		PhoneNumber: pgtype.Text{String: pending.PhoneNumber, Valid: true},
+	})
+	if err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	util.JsonResponseWriter(w, http.StatusOK, newCustomerInfo(user))
+}
@@ -0,0 +1,135 @@
+package user
+
+import (
+	"encoding/json"
+	"errors"
+	db "github.com/bigusef/texorbit/internal/database"
+	"github.com/bigusef/texorbit/pkg/config"
+	"github.com/bigusef/texorbit/pkg/util"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"net/http"
+	"time"
+)
+
+type policyInput struct {
+	Enabled *bool `json:"enabled" validate:"required"`
+}
+
+type policyResponse struct {
+	Key       string    `json:"key"`
+	Enabled   bool      `json:"enabled"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type policyHandler struct {
+	conf     *config.Setting
+	queries  *db.Queries
+	validate *validator.Validate
+}
+
+func (h *policyHandler) listPolicies(w http.ResponseWriter, r *http.Request) {
+	policies, err := h.queries.AllSecurityPolicies(r.Context())
+	if err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	result := make([]policyResponse, len(policies))
+	for i, p := range policies {
+		result[i] = policyResponse{Key: p.Key, Enabled: p.Enabled, UpdatedAt: p.UpdatedAt.Time}
+	}
+
+	util.JsonResponseWriter(w, http.StatusOK, result)
+}
+
+func (h *policyHandler) updatePolicy(w http.ResponseWriter, r *http.Request) {
+	var input policyInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		util.ErrorResponseWriter(w, r, util.BadRequest("invalid request body"))
+		return
+	}
+
+	if err := h.validate.Struct(input); err != nil {
+		util.ErrorResponseWriter(w, r, util.ValidationFailed(err))
+		return
+	}
+
+	policy, err := h.queries.SetSecurityPolicy(r.Context(), db.SetSecurityPolicyParams{
+		Enabled: *input.Enabled,
+		Key:     chi.URLParam(r, "key"),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			util.ErrorResponseWriter(w, r, util.NotFound("policy not found"))
+			return
+		}
+
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	util.JsonResponseWriter(w, http.StatusOK, policyResponse{
+		Key:       policy.Key,
+		Enabled:   policy.Enabled,
+		UpdatedAt: policy.UpdatedAt.Time,
+	})
+}
+
+type lockoutInfo struct {
+	Key           string    `json:"key"`
+	Failures      int32     `json:"failures"`
+	LastFailureAt time.Time `json:"last_failure_at"`
+	LockedUntil   time.Time `json:"locked_until"`
+}
+
+func (h *policyHandler) listLockouts(w http.ResponseWriter, r *http.Request) {
+	lockouts, err := h.queries.AllLockedLogins(r.Context())
+	if err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	result := make([]lockoutInfo, len(lockouts))
+	for i, l := range lockouts {
+		result[i] = lockoutInfo{
+			Key:           l.Key,
+			Failures:      l.Failures,
+			LastFailureAt: l.LastFailureAt.Time,
+			LockedUntil:   l.LockedUntil.Time,
+		}
+	}
+
+	util.JsonResponseWriter(w, http.StatusOK, result)
+}
+
+// unlockAccount clear the failed login attempts of the account so its owner can login again immediately
+func (h *policyHandler) unlockAccount(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		util.ErrorResponseWriter(w, r, util.BadRequest("invalid user id"))
+		return
+	}
+
+	user, err := h.queries.GetUSerById(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			util.ErrorResponseWriter(w, r, util.NotFound("this user does not exist in the system."))
+			return
+		}
+
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	if _, err := h.queries.ClearLoginFailures(ctx, accountThrottleKey(user.Email)); err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	util.JsonResponseWriter(w, http.StatusNoContent, nil)
+}
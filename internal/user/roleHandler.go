@@ -0,0 +1,359 @@
+package user
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	db "github.com/bigusef/texorbit/internal/database"
+	"github.com/bigusef/texorbit/pkg/config"
+	"github.com/bigusef/texorbit/pkg/middleware"
+	"github.com/bigusef/texorbit/pkg/util"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"net/http"
+	"strconv"
+)
+
+var (
+	errUnknownRole = errors.New("unknown role")
+	// errNotGrantable staff can not give roles or edit accounts with permissions they do not have
+	errNotGrantable = errors.New("you can not grant permissions you do not have")
+)
+
+type roleHandler struct {
+	conf     *config.Setting
+	queries  *db.Queries
+	validate *validator.Validate
+}
+
+func (h *roleHandler) listPermissions(w http.ResponseWriter, r *http.Request) {
+	permissions, err := h.queries.AllPermissions(r.Context())
+	if err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	result := make([]permissionResponse, len(permissions))
+	for i, p := range permissions {
+		result[i] = permissionResponse{Code: p.Code, Description: p.Description}
+	}
+
+	util.JsonResponseWriter(w, http.StatusOK, result)
+}
+
+func (h *roleHandler) listRoles(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	roles, err := h.queries.AllRoles(ctx)
+	if err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	rolePermissions, err := h.queries.AllRolePermissions(ctx)
+	if err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	grouped := map[int64][]string{}
+	for _, rp := range rolePermissions {
+		grouped[rp.RoleID] = append(grouped[rp.RoleID], rp.Permission)
+	}
+
+	result := make([]roleResponse, len(roles))
+	for i, role := range roles {
+		result[i] = roleResponse{
+			ID:          role.ID,
+			Name:        role.Name,
+			Description: role.Description,
+			Permissions: grouped[role.ID],
+		}
+	}
+
+	util.JsonResponseWriter(w, http.StatusOK, result)
+}
+
+func (h *roleHandler) createRole(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var input roleInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		util.ErrorResponseWriter(w, r, util.BadRequest("invalid request body"))
+		return
+	}
+
+	if err := h.validate.Struct(input); err != nil {
+		util.ErrorResponseWriter(w, r, util.ValidationFailed(err))
+		return
+	}
+
+	if ok, err := h.validPermissions(ctx, input.Permissions); err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	} else if !ok {
+		util.ErrorResponseWriter(w, r, util.Invalid(map[string]string{"permissions": "unknown_permission"}))
+		return
+	}
+	if !heldPermissions(r, input.Permissions) {
+		util.ErrorResponseWriter(w, r, util.Forbidden(errNotGrantable.Error()))
+		return
+	}
+
+	role, err := h.queries.CreateRole(ctx, db.CreateRoleParams{
+		Name:        input.Name,
+		Description: input.Description,
+	})
+	if err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	err = h.queries.SetRolePermissions(ctx, db.SetRolePermissionsParams{RoleID: role.ID, Permissions: input.Permissions})
+	if err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	util.JsonResponseWriter(w, http.StatusCreated, roleResponse{
+		ID:          role.ID,
+		Name:        role.Name,
+		Description: role.Description,
+		Permissions: input.Permissions,
+	})
+}
+
+func (h *roleHandler) updateRole(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		util.ErrorResponseWriter(w, r, util.BadRequest("invalid id"))
+		return
+	}
+
+	var input roleInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		util.ErrorResponseWriter(w, r, util.BadRequest("invalid request body"))
+		return
+	}
+
+	if err := h.validate.Struct(input); err != nil {
+		util.ErrorResponseWriter(w, r, util.ValidationFailed(err))
+		return
+	}
+
+	if ok, err := h.validPermissions(ctx, input.Permissions); err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	} else if !ok {
+		util.ErrorResponseWriter(w, r, util.Invalid(map[string]string{"permissions": "unknown_permission"}))
+		return
+	}
+	if !heldPermissions(r, input.Permissions) {
+		util.ErrorResponseWriter(w, r, util.Forbidden(errNotGrantable.Error()))
+		return
+	}
+
+	role, err := h.queries.UpdateRole(ctx, db.UpdateRoleParams{
+		ID:          id,
+		Name:        input.Name,
+		Description: input.Description,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			util.ErrorResponseWriter(w, r, util.NotFound("Role not found"))
+			return
+		}
+
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	err = h.queries.SetRolePermissions(ctx, db.SetRolePermissionsParams{RoleID: role.ID, Permissions: input.Permissions})
+	if err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	util.JsonResponseWriter(w, http.StatusOK, roleResponse{
+		ID:          role.ID,
+		Name:        role.Name,
+		Description: role.Description,
+		Permissions: input.Permissions,
+	})
+}
+
+func (h *roleHandler) deleteRole(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		util.ErrorResponseWriter(w, r, util.BadRequest("invalid id"))
+		return
+	}
+
+	if err = h.queries.DeleteRole(r.Context(), id); err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	util.JsonResponseWriter(w, http.StatusNoContent, nil)
+}
+
+func (h *roleHandler) setStaffRoles(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		util.ErrorResponseWriter(w, r, util.BadRequest("invalid id"))
+		return
+	}
+
+	user, err := h.queries.GetUSerById(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			util.ErrorResponseWriter(w, r, util.NotFound("resource not found"))
+			return
+		}
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	if !user.IsStaff {
+		util.ErrorResponseWriter(w, r, util.Invalid(map[string]string{"id": "staff_only"}))
+		return
+	}
+
+	var input userRolesInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		util.ErrorResponseWriter(w, r, util.BadRequest("invalid request body"))
+		return
+	}
+
+	if err := h.validate.Struct(input); err != nil {
+		util.ErrorResponseWriter(w, r, util.ValidationFailed(err))
+		return
+	}
+
+	roles, err := roleNames(ctx, h.queries, input.Roles)
+	if err != nil {
+		if errors.Is(err, errUnknownRole) {
+			util.ErrorResponseWriter(w, r, util.Invalid(map[string]string{"roles": "unknown_role"}))
+			return
+		}
+
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	if ok, err := rolesGrantable(ctx, h.queries, r, input.Roles); err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	} else if !ok {
+		util.ErrorResponseWriter(w, r, util.Forbidden(errNotGrantable.Error()))
+		return
+	}
+
+	if err = h.queries.SetUserRoles(ctx, db.SetUserRolesParams{UserID: user.ID, RoleIds: input.Roles}); err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	util.JsonResponseWriter(w, http.StatusOK, roles)
+}
+
+// validPermissions make sure all given permissions codes exist
+func (h *roleHandler) validPermissions(ctx context.Context, permissions []string) (bool, error) {
+	all, err := h.queries.AllPermissions(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	known := make(map[string]bool, len(all))
+	for _, p := range all {
+		known[p.Code] = true
+	}
+
+	for _, p := range permissions {
+		if !known[p] {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// heldPermissions report whether the request token grants all the given permissions
+func heldPermissions(r *http.Request, permissions []string) bool {
+	_, claims, _ := jwtauth.FromContext(r.Context())
+	for _, permission := range permissions {
+		if !middleware.HasPermission(claims, permission) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// rolesGrantable report whether the request token grants every permission of the given roles
+func rolesGrantable(ctx context.Context, queries *db.Queries, r *http.Request, roleIds []int64) (bool, error) {
+	if len(roleIds) == 0 {
+		return true, nil
+	}
+
+	all, err := queries.AllRolePermissions(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	wanted := make(map[int64]bool, len(roleIds))
+	for _, id := range roleIds {
+		wanted[id] = true
+	}
+
+	var permissions []string
+	for _, rp := range all {
+		if wanted[rp.RoleID] {
+			permissions = append(permissions, rp.Permission)
+		}
+	}
+
+	return heldPermissions(r, permissions), nil
+}
+
+// staffEditable report whether the request token grants every permission the staff member has,
+// so accounts with more access (ex: admins) can not be taken over by changing their email
+func staffEditable(ctx context.Context, queries *db.Queries, r *http.Request, userId uuid.UUID) (bool, error) {
+	permissions, err := queries.UserPermissions(ctx, userId)
+	if err != nil {
+		return false, err
+	}
+
+	return heldPermissions(r, permissions), nil
+}
+
+// roleNames make sure all role ids exist and return their names
+func roleNames(ctx context.Context, queries *db.Queries, roleIds []int64) ([]string, error) {
+	roles, err := queries.AllRoles(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[int64]string, len(roles))
+	for _, role := range roles {
+		names[role.ID] = role.Name
+	}
+
+	result := make([]string, len(roleIds))
+	for i, id := range roleIds {
+		name, ok := names[id]
+		if !ok {
+			return nil, errUnknownRole
+		}
+		result[i] = name
+	}
+
+	return result, nil
+}
@@ -1,12 +1,15 @@
 package user
 
 import (
+	"github.com/bigusef/texorbit/internal/apikey"
+	"github.com/bigusef/texorbit/internal/audit"
 	db "github.com/bigusef/texorbit/internal/database"
 	"github.com/bigusef/texorbit/pkg/config"
+	"github.com/bigusef/texorbit/pkg/jwtkeys"
 	"github.com/bigusef/texorbit/pkg/middleware"
 	"github.com/go-chi/chi/v5"
-	"github.com/go-chi/jwtauth/v5"
 	"github.com/go-playground/validator/v10"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"net/http"
 )
 
@@ -19,60 +22,148 @@ func AuthRouter(conf *config.Setting, queries *db.Queries, validate *validator.V
 	}
 
 	// public
+	r.Get("/{provider}/authorize", h.authorize)
 	r.Post("/login", h.login)
 	r.Post("/staff-login", h.staffLogin)
+	r.Post("/magic-link", h.requestMagicLink)
+	r.Post("/magic-link/verify", h.verifyMagicLink)
+	r.Post("/mfa/verify", h.verifyMfa)
 
 	// staff and customers
 	r.With(
-		jwtauth.Verifier(conf.RefreshAuth),
-		jwtauth.Authenticator(conf.RefreshAuth),
+		jwtkeys.Verifier(conf.RefreshAuth),
+		jwtkeys.Authenticator(conf.RefreshAuth),
 	).Get("/refresh", h.refreshAccessToken)
 
+	r.Group(func(ir chi.Router) {
+		ir.Use(jwtkeys.Verifier(conf.AccessAuth))
+		ir.Use(jwtkeys.Authenticator(conf.AccessAuth))
+		ir.Use(middleware.PreferredLocale)
+
+		ir.Use(audit.Impersonation(queries))
+
+		ir.Post("/logout", h.logout)
+		ir.With(middleware.DenyImpersonation).Post("/logout-all", h.logoutAll)
+
+		// staff two factor authentication
+		ir.Group(func(mr chi.Router) {
+			mr.Use(middleware.DenyImpersonation)
+
+			mr.Post("/mfa/setup", h.setupMfa)
+			mr.Post("/mfa/activate", h.activateMfa)
+			mr.Post("/mfa/recovery-codes", h.regenerateRecoveryCodes)
+			mr.Post("/mfa/disable", h.disableMfa)
+		})
+
+		// support staff acting as customers
+		ir.With(middleware.RequirePermission(middleware.CustomerImpersonate)).Post("/impersonate/{userId}", h.impersonate)
+	})
+
 	return r
 }
 
-func StaffRouter(conf *config.Setting, queries *db.Queries, validate *validator.Validate) http.Handler {
+func StaffRouter(conf *config.Setting, pool *pgxpool.Pool, queries *db.Queries, validate *validator.Validate) http.Handler {
 	r := chi.NewRouter()
 	h := &staffHandler{
+		pool:     pool,
 		queries:  queries,
 		conf:     conf,
 		validate: validate,
 	}
-	r.Use(jwtauth.Verifier(conf.AccessAuth))
-	r.Use(jwtauth.Authenticator(conf.AccessAuth))
-	r.Use(middleware.StaffPermission)
+	rh := &roleHandler{
+		queries:  queries,
+		conf:     conf,
+		validate: validate,
+	}
+	ph := &policyHandler{
+		queries:  queries,
+		conf:     conf,
+		validate: validate,
+	}
+	r.Use(jwtkeys.Verifier(conf.AccessAuth))
+	r.Use(middleware.APIKeyVerifier(apikey.Lookup(queries)))
+	r.Use(jwtkeys.Authenticator(conf.AccessAuth))
+	r.Use(middleware.PreferredLocale)
+	r.Use(audit.Impersonation(queries))
 
 	// Only Staff users [admin]
-	r.With(middleware.Pagination).Get("/", h.listStaffHandler)
-	r.Post("/", h.createStaffHandler)
-	r.Put("/{id}", h.updateStaffHandler)
+	r.With(middleware.RequirePermission(middleware.StaffRead), middleware.CursorPagination).Get("/", h.listStaffHandler)
+	r.With(middleware.RequirePermission(middleware.StaffManage)).Post("/", h.createStaffHandler)
+	r.With(middleware.RequirePermission(middleware.StaffManage)).Put("/{id}", h.updateStaffHandler)
+	r.With(middleware.RequirePermission(middleware.StaffManage)).Post("/{id}/status", h.changeStaffStatus)
+	r.With(middleware.RequirePermission(middleware.StaffRead), middleware.Pagination).Get("/{id}/status-history", h.listStaffStatusHistory)
+	r.With(middleware.RequirePermission(middleware.StaffRead), middleware.Pagination).Get("/{id}/login-events", h.listStaffLoginEvents)
+
+	// roles and permissions management
+	r.Group(func(ir chi.Router) {
+		ir.Use(middleware.RequirePermission(middleware.RoleManage))
+
+		ir.Get("/permissions", rh.listPermissions)
+		ir.Get("/roles", rh.listRoles)
+		ir.Post("/roles", rh.createRole)
+		ir.Put("/roles/{id}", rh.updateRole)
+		ir.Delete("/roles/{id}", rh.deleteRole)
+		ir.Put("/{id}/roles", rh.setStaffRoles)
+	})
+
+	// security policies, impersonation audit and login lockouts
+	r.Group(func(ir chi.Router) {
+		ir.Use(middleware.RequirePermission(middleware.SecurityManage))
+
+		ir.Get("/policies", ph.listPolicies)
+		ir.Put("/policies/{key}", ph.updatePolicy)
+		ir.With(middleware.Pagination).Get("/impersonations", ph.listImpersonations)
+		ir.Get("/impersonations/{id}", ph.getImpersonation)
+		ir.Get("/lockouts", ph.listLockouts)
+		ir.Post("/accounts/{id}/unlock", ph.unlockAccount)
+	})
 
 	return r
 }
 
-func CustomerRouter(conf *config.Setting, queries *db.Queries, validate *validator.Validate) http.Handler {
+func CustomerRouter(conf *config.Setting, pool *pgxpool.Pool, queries *db.Queries, validate *validator.Validate) http.Handler {
 	r := chi.NewRouter()
 	h := &customerHandler{
+		pool:     pool,
 		queries:  queries,
 		conf:     conf,
 		validate: validate,
 	}
 
-	r.Use(jwtauth.Verifier(conf.AccessAuth))
-	r.Use(jwtauth.Authenticator(conf.AccessAuth))
+	r.Use(jwtkeys.Verifier(conf.AccessAuth))
+	r.Use(middleware.APIKeyVerifier(apikey.Lookup(queries)))
+	r.Use(jwtkeys.Authenticator(conf.AccessAuth))
+	r.Use(middleware.PreferredLocale)
+	r.Use(audit.Impersonation(queries))
 
 	// only authenticated user will get this based on auth token
 	r.Get("/me", h.getUserInfo)
-	r.Put("/me", h.updateUserInfo)
+	r.With(middleware.DenyImpersonation).Put("/me", h.updateUserInfo)
+	r.With(middleware.DenyImpersonation).Delete("/me", h.deleteMyAccount)
+	r.With(middleware.DenyImpersonation).Put("/me/avatar", h.uploadAvatar)
+	r.With(middleware.DenyImpersonation).Get("/me/export", h.exportMyData)
+	r.With(middleware.DenyImpersonation).Post("/me/phone/otp", h.requestPhoneOtp)
+	r.With(middleware.DenyImpersonation).Post("/me/phone/verify", h.verifyPhoneOtp)
+	r.With(middleware.DenyImpersonation).Put("/me/language", h.updateMyLanguage)
+	r.Get("/me/sessions", h.listMySessions)
+	r.Get("/me/addresses", h.listMyAddresses)
+	r.Post("/me/addresses", h.createMyAddress)
+	r.Get("/me/addresses/{addressId}", h.getMyAddress)
+	r.Put("/me/addresses/{addressId}", h.updateMyAddress)
+	r.Put("/me/addresses/{addressId}/default", h.setMyDefaultAddress)
+	r.Delete("/me/addresses/{addressId}", h.deleteMyAddress)
+	r.With(middleware.DenyImpersonation).Delete("/me/sessions/{sessionId}", h.revokeMySession)
 
 	// only staff users [Admin]
-	r.Group(func(ir chi.Router) {
-		ir.Use(middleware.StaffPermission)
-
-		ir.Get("/", h.listAllCustomers)
-		ir.Get("/{id}", h.getCustomerInfo)
-		ir.Put("/{id}", h.updateCustomerInfo)
-	})
+	r.With(middleware.RequirePermission(middleware.CustomerRead), middleware.CursorPagination).Get("/", h.listAllCustomers)
+	r.With(middleware.RequirePermission(middleware.CustomerRead)).Get("/{id}", h.getCustomerInfo)
+	r.With(middleware.RequirePermission(middleware.CustomerWrite)).Put("/{id}", h.updateCustomerInfo)
+	r.With(middleware.RequirePermission(middleware.CustomerWrite)).Post("/{id}/status", h.changeCustomerStatus)
+	r.With(middleware.RequirePermission(middleware.CustomerRead), middleware.Pagination).Get("/{id}/status-history", h.listCustomerStatusHistory)
+	r.With(middleware.RequirePermission(middleware.CustomerRead)).Get("/{id}/sessions", h.listCustomerSessions)
+	r.With(middleware.RequirePermission(middleware.CustomerRead)).Get("/{id}/addresses", h.listCustomerAddresses)
+	r.With(middleware.RequirePermission(middleware.CustomerRead), middleware.Pagination).Get("/{id}/login-events", h.listCustomerLoginEvents)
+	r.With(middleware.RequirePermission(middleware.CustomerWrite)).Delete("/{id}/sessions/{sessionId}", h.revokeCustomerSession)
 
 	return r
 }
@@ -0,0 +1,273 @@
+package user
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	db "github.com/bigusef/texorbit/internal/database"
+	"github.com/bigusef/texorbit/pkg/oidc"
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+)
+
+const accessTokenTTL = time.Minute * 15
+
+var (
+	errInvalidRefreshToken = errors.New("invalid refresh token")
+	errRefreshTokenReused  = errors.New("refresh token already used, all tokens of this session are revoked")
+	errInvalidPurposeToken = errors.New("invalid or expired token")
+)
+
+type tokenPair struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// refreshTokenTTL staff sessions are shorter than customers sessions
+func refreshTokenTTL(user db.User) time.Duration {
+	if user.IsStaff {
+		return time.Hour * 24
+	}
+
+	return time.Hour * 72
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// deviceID return the client device identifier sent in "X-Device-Id" header,
+// or generate new one for clients that do not send it
+func deviceID(r *http.Request) string {
+	if id := r.Header.Get("X-Device-Id"); id != "" && len(id) <= 64 {
+		return id
+	}
+
+	return uuid.NewString()
+}
+
+// clientInfo return the device label sent in "X-Device-Label" header, user agent and ip address of the request,
+// trimmed to the sessions columns size
+func clientInfo(r *http.Request) (label, userAgent, ip string) {
+	label = truncate(r.Header.Get("X-Device-Label"), 100)
+	userAgent = truncate(r.UserAgent(), 255)
+
+	ip = r.RemoteAddr
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		ip = host
+	}
+
+	return label, userAgent, truncate(ip, 45)
+}
+
+func truncate(value string, size int) string {
+	if runes := []rune(value); len(runes) > size {
+		return string(runes[:size])
+	}
+
+	return value
+}
+
+// currentSessionId return the session id from the access token "sid" claim
+func currentSessionId(r *http.Request) (uuid.UUID, error) {
+	_, claims, err := jwtauth.FromContext(r.Context())
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	sid, _ := claims["sid"].(string)
+	return uuid.Parse(sid)
+}
+
+// currentUserId return the authenticated user id from the access token "sub" claim
+func currentUserId(r *http.Request) (uuid.UUID, error) {
+	_, claims, err := jwtauth.FromContext(r.Context())
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	sub, _ := claims["sub"].(string)
+	return uuid.Parse(sub)
+}
+
+// startSession create new session for the user device, replacing any active session on the same device,
+// and issue its first token pair
+func (h *authHandler) startSession(ctx context.Context, r *http.Request, user db.User) (*tokenPair, error) {
+	device := deviceID(r)
+	label, userAgent, ip := clientInfo(r)
+	ttl := refreshTokenTTL(user)
+
+	err := h.queries.RevokeDeviceSessions(ctx, db.RevokeDeviceSessionsParams{UserID: user.ID, DeviceID: device})
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := h.queries.CreateSession(ctx, db.CreateSessionParams{
+		UserID:      user.ID,
+		DeviceID:    device,
+		DeviceLabel: label,
+		UserAgent:   userAgent,
+		IpAddress:   ip,
+		ExpiresAt:   pgtype.Timestamptz{Time: time.Now().Add(ttl), Valid: true},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return h.issueTokens(ctx, user, session.ID, ttl)
+}
+
+// rotateSession exchange a refresh token for a new token pair of the same session.
+// presenting a refresh token that was already rotated means it leaked, so the whole session get revoked
+func (h *authHandler) rotateSession(ctx context.Context, r *http.Request, refreshToken string) (db.User, *tokenPair, error) {
+	tokenHash := hashToken(refreshToken)
+
+	stored, err := h.queries.GetRefreshToken(ctx, tokenHash)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.User{}, nil, errInvalidRefreshToken
+		}
+		return db.User{}, nil, err
+	}
+
+	if stored.RevokedAt.Valid || stored.ExpiresAt.Time.Before(time.Now()) {
+		return db.User{}, nil, errInvalidRefreshToken
+	}
+
+	// conditional update so two concurrent refresh calls with the same token can not both succeed
+	affected, err := h.queries.UseRefreshToken(ctx, tokenHash)
+	if err != nil {
+		return db.User{}, nil, err
+	}
+
+	if stored.UsedAt.Valid || affected == 0 {
+		slog.Warn("refresh token reuse detected", "session", stored.SessionID, "user", stored.UserID)
+		if err := h.queries.RevokeSession(ctx, stored.SessionID); err != nil {
+			return db.User{}, nil, err
+		}
+		return db.User{}, nil, errRefreshTokenReused
+	}
+
+	user, err := h.queries.GetUSerById(ctx, stored.UserID)
+	if err != nil {
+		return db.User{}, nil, err
+	}
+
+	ttl := refreshTokenTTL(user)
+	_, userAgent, ip := clientInfo(r)
+	err = h.queries.TouchSession(ctx, db.TouchSessionParams{
+		UserAgent: userAgent,
+		IpAddress: ip,
+		ExpiresAt: pgtype.Timestamptz{Time: time.Now().Add(ttl), Valid: true},
+		ID:        stored.SessionID,
+	})
+	if err != nil {
+		return db.User{}, nil, err
+	}
+
+	tokens, err := h.issueTokens(ctx, user, stored.SessionID, ttl)
+	return user, tokens, err
+}
+
+func (h *authHandler) issueTokens(ctx context.Context, user db.User, sessionID uuid.UUID, ttl time.Duration) (*tokenPair, error) {
+	// unique token id make sure every refresh token has different hash
+	jti, err := oidc.RandomString(16)
+	if err != nil {
+		return nil, err
+	}
+
+	// permissions resolved from staff roles at issue time, so role changes apply on next refresh
+	permissions := []string{}
+	mfaSetupRequired := false
+	if user.IsStaff {
+		if mfaSetupRequired, err = h.staffMfaSetupRequired(ctx, user.ID); err != nil {
+			return nil, err
+		}
+
+		if !mfaSetupRequired {
+			if permissions, err = h.queries.UserPermissions(ctx, user.ID); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	claims := map[string]interface{}{
+		"sub":   user.ID.String(),
+		"sid":   sessionID.String(),
+		"exp":   time.Now().Add(accessTokenTTL).Unix(),
+		"staff": user.IsStaff,
+		"perms": permissions,
+		// staff must enroll two factor authentication before getting any permission
+		"mfa_setup_required": mfaSetupRequired,
+	}
+	// preferred language of the user, used by PreferredLocale middleware instead of Accept-Language
+	if user.PreferredLanguage.Valid {
+		claims["lang"] = user.PreferredLanguage.String
+	}
+
+	_, accessToken, err := h.conf.AccessAuth.Encode(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	_, refreshToken, err := h.conf.RefreshAuth.Encode(
+		map[string]interface{}{
+			"sub": user.ID.String(),
+			"sid": sessionID.String(),
+			"jti": jti,
+			"exp": time.Now().Add(ttl).Unix(),
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	err = h.queries.CreateRefreshToken(ctx, db.CreateRefreshTokenParams{
+		TokenHash: hashToken(refreshToken),
+		SessionID: sessionID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &tokenPair{AccessToken: accessToken, RefreshToken: refreshToken}, nil
+}
+
+// signPurposeToken sign short lived token for single step of the login flow (ex: magic link, mfa challenge)
+// with the refresh key ring, the "purpose" claim keep it from being accepted in place of other tokens
+func (h *authHandler) signPurposeToken(purpose, subject string, ttl time.Duration) (string, error) {
+	jti, err := oidc.RandomString(16)
+	if err != nil {
+		return "", err
+	}
+
+	_, token, err := h.conf.RefreshAuth.Encode(map[string]interface{}{
+		"sub":     subject,
+		"jti":     jti,
+		"purpose": purpose,
+		"exp":     time.Now().Add(ttl).Unix(),
+	})
+	return token, err
+}
+
+// verifyPurposeToken return the subject of valid token signed for the given purpose
+func (h *authHandler) verifyPurposeToken(tokenString, purpose string) (string, error) {
+	token, err := h.conf.RefreshAuth.Verify(tokenString)
+	if err != nil {
+		return "", errInvalidPurposeToken
+	}
+
+	if p, _ := token.Get("purpose"); p != purpose {
+		return "", errInvalidPurposeToken
+	}
+
+	return token.Subject(), nil
+}
@@ -0,0 +1,134 @@
+package user
+
+import (
+	"errors"
+	db "github.com/bigusef/texorbit/internal/database"
+	"github.com/bigusef/texorbit/pkg/util"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"net/http"
+	"time"
+)
+
+type sessionInfo struct {
+	ID          uuid.UUID `json:"id"`
+	DeviceLabel string    `json:"device_label"`
+	UserAgent   string    `json:"user_agent"`
+	IpAddress   string    `json:"ip_address"`
+	CreatedAt   time.Time `json:"created_at"`
+	LastUsedAt  time.Time `json:"last_used_at"`
+	Current     bool      `json:"current"`
+}
+
+func newSessionsInfo(sessions []db.Session, current uuid.UUID) []sessionInfo {
+	result := make([]sessionInfo, len(sessions))
+	for i, s := range sessions {
+		result[i] = sessionInfo{
+			ID:          s.ID,
+			DeviceLabel: s.DeviceLabel,
+			UserAgent:   s.UserAgent,
+			IpAddress:   s.IpAddress,
+			CreatedAt:   s.CreatedAt.Time,
+			LastUsedAt:  s.LastUsedAt.Time,
+			Current:     s.ID == current,
+		}
+	}
+
+	return result
+}
+
+func (h *customerHandler) listMySessions(w http.ResponseWriter, r *http.Request) {
+	userId, err := currentUserId(r)
+	if err != nil {
+		util.ErrorResponseWriter(w, r, util.BadRequest("invalid user id"))
+		return
+	}
+	sessionId, _ := currentSessionId(r)
+
+	sessions, err := h.queries.ActiveUserSessions(r.Context(), userId)
+	if err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	util.JsonResponseWriter(w, http.StatusOK, newSessionsInfo(sessions, sessionId))
+}
+
+func (h *customerHandler) revokeMySession(w http.ResponseWriter, r *http.Request) {
+	userId, err := currentUserId(r)
+	if err != nil {
+		util.ErrorResponseWriter(w, r, util.BadRequest("invalid user id"))
+		return
+	}
+
+	h.revokeSession(w, r, userId)
+}
+
+func (h *customerHandler) listCustomerSessions(w http.ResponseWriter, r *http.Request) {
+	customer, ok := h.customerFromURL(w, r)
+	if !ok {
+		return
+	}
+
+	sessions, err := h.queries.ActiveUserSessions(r.Context(), customer.ID)
+	if err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	util.JsonResponseWriter(w, http.StatusOK, newSessionsInfo(sessions, uuid.Nil))
+}
+
+func (h *customerHandler) revokeCustomerSession(w http.ResponseWriter, r *http.Request) {
+	customer, ok := h.customerFromURL(w, r)
+	if !ok {
+		return
+	}
+
+	h.revokeSession(w, r, customer.ID)
+}
+
+// revokeSession revoke the session in the url when it belongs to the given user,
+// its refresh token stop working immediately and its access token expire within minutes
+func (h *customerHandler) revokeSession(w http.ResponseWriter, r *http.Request, userId uuid.UUID) {
+	sessionId, err := uuid.Parse(chi.URLParam(r, "sessionId"))
+	if err != nil {
+		util.ErrorResponseWriter(w, r, util.BadRequest("invalid session id"))
+		return
+	}
+
+	affected, err := h.queries.RevokeUserSession(r.Context(), db.RevokeUserSessionParams{ID: sessionId, UserID: userId})
+	if err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+	if affected == 0 {
+		util.ErrorResponseWriter(w, r, util.NotFound("session not found"))
+		return
+	}
+
+	util.JsonResponseWriter(w, http.StatusNoContent, nil)
+}
+
+// customerFromURL return the customer of the "id" url parameter
+func (h *customerHandler) customerFromURL(w http.ResponseWriter, r *http.Request) (db.User, bool) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		util.ErrorResponseWriter(w, r, util.BadRequest("invalid user id"))
+		return db.User{}, false
+	}
+
+	customer, err := h.queries.GetCustomerById(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			util.ErrorResponseWriter(w, r, util.NotFound("customer not found"))
+			return db.User{}, false
+		}
+
+		util.ErrorResponseWriter(w, r, err)
+		return db.User{}, false
+	}
+
+	return customer, true
+}
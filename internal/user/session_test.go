@@ -0,0 +1,321 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	db "github.com/bigusef/texorbit/internal/database"
+	"github.com/bigusef/texorbit/pkg/config"
+	"github.com/bigusef/texorbit/pkg/jwtkeys"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type storedToken struct {
+	sessionID uuid.UUID
+	used      bool
+}
+
+type storedSession struct {
+	userID    uuid.UUID
+	expiresAt time.Time
+	revoked   bool
+}
+
+// fakeSessionDB keep refresh tokens and sessions in memory and answer the session queries used by rotation
+type fakeSessionDB struct {
+	user     db.User
+	tokens   map[string]*storedToken
+	sessions map[uuid.UUID]*storedSession
+	// concurrent simulate another request using the token between the read and the conditional update
+	concurrent bool
+}
+
+func newFakeSessionDB(user db.User) *fakeSessionDB {
+	return &fakeSessionDB{
+		user:     user,
+		tokens:   map[string]*storedToken{},
+		sessions: map[uuid.UUID]*storedSession{},
+	}
+}
+
+// addSession create session of the fake user with its first refresh token
+func (f *fakeSessionDB) addSession(refreshToken string, expiresAt time.Time) uuid.UUID {
+	id := uuid.New()
+	f.sessions[id] = &storedSession{userID: f.user.ID, expiresAt: expiresAt}
+	f.tokens[hashToken(refreshToken)] = &storedToken{sessionID: id}
+	return id
+}
+
+func queryName(sql string) string {
+	name, _, _ := strings.Cut(strings.TrimPrefix(sql, "-- name: "), " ")
+	return name
+}
+
+func (f *fakeSessionDB) Exec(_ context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	switch queryName(sql) {
+	case "UseRefreshToken":
+		token, ok := f.tokens[args[0].(string)]
+		if !ok || token.used || f.concurrent {
+			return pgconn.NewCommandTag("UPDATE 0"), nil
+		}
+		token.used = true
+		return pgconn.NewCommandTag("UPDATE 1"), nil
+	case "RevokeSession":
+		f.sessions[args[0].(uuid.UUID)].revoked = true
+		return pgconn.NewCommandTag("UPDATE 1"), nil
+	case "TouchSession":
+		f.sessions[args[3].(uuid.UUID)].expiresAt = args[2].(pgtype.Timestamptz).Time
+		return pgconn.NewCommandTag("UPDATE 1"), nil
+	case "CreateRefreshToken":
+		f.tokens[args[0].(string)] = &storedToken{sessionID: args[1].(uuid.UUID)}
+		return pgconn.NewCommandTag("INSERT 0 1"), nil
+	}
+	return pgconn.CommandTag{}, fmt.Errorf("unexpected exec %q", queryName(sql))
+}
+
+func (f *fakeSessionDB) Query(_ context.Context, sql string, _ ...interface{}) (pgx.Rows, error) {
+	return nil, fmt.Errorf("unexpected query %q", queryName(sql))
+}
+
+func (f *fakeSessionDB) QueryRow(_ context.Context, sql string, args ...interface{}) pgx.Row {
+	switch queryName(sql) {
+	case "GetRefreshToken":
+		token, ok := f.tokens[args[0].(string)]
+		if !ok {
+			return fakeRow{err: pgx.ErrNoRows}
+		}
+		session := f.sessions[token.sessionID]
+		return fakeRow{scan: func(dest ...interface{}) {
+			*dest[0].(*uuid.UUID) = token.sessionID
+			*dest[1].(*pgtype.Timestamptz) = pgtype.Timestamptz{Time: time.Now(), Valid: token.used}
+			*dest[2].(*uuid.UUID) = session.userID
+			*dest[3].(*pgtype.Timestamptz) = pgtype.Timestamptz{Time: session.expiresAt, Valid: true}
+			*dest[4].(*pgtype.Timestamptz) = pgtype.Timestamptz{Time: time.Now(), Valid: session.revoked}
+		}}
+	case "GetUSerById":
+		return fakeRow{scan: func(dest ...interface{}) {
+			*dest[0].(*uuid.UUID) = f.user.ID
+			*dest[6].(*bool) = f.user.IsStaff
+			*dest[14].(*pgtype.Text) = f.user.PreferredLanguage
+		}}
+	}
+	return fakeRow{err: fmt.Errorf("unexpected query row %q", queryName(sql))}
+}
+
+type fakeRow struct {
+	scan func(dest ...interface{})
+	err  error
+}
+
+func (r fakeRow) Scan(dest ...interface{}) error {
+	if r.err != nil {
+		return r.err
+	}
+	r.scan(dest...)
+	return nil
+}
+
+func newTestAuthHandler(fake *fakeSessionDB) *authHandler {
+	return &authHandler{
+		conf: &config.Setting{
+			AccessAuth:  jwtkeys.NewSecret([]byte("access-secret")),
+			RefreshAuth: jwtkeys.NewSecret([]byte("refresh-secret")),
+		},
+		queries: db.New(fake),
+	}
+}
+
+func TestRotateSession(t *testing.T) {
+	customer := db.User{ID: uuid.New(), PreferredLanguage: pgtype.Text{String: "ar", Valid: true}}
+
+	tests := []struct {
+		name        string
+		setup       func(f *fakeSessionDB) uuid.UUID
+		token       string
+		wantErr     error
+		wantRevoked bool
+	}{
+		{
+			name: "valid token",
+			setup: func(f *fakeSessionDB) uuid.UUID {
+				return f.addSession("refresh", time.Now().Add(time.Hour))
+			},
+			token: "refresh",
+		},
+		{
+			name: "unknown token",
+			setup: func(f *fakeSessionDB) uuid.UUID {
+				return f.addSession("refresh", time.Now().Add(time.Hour))
+			},
+			token:   "other",
+			wantErr: errInvalidRefreshToken,
+		},
+		{
+			name: "expired session",
+			setup: func(f *fakeSessionDB) uuid.UUID {
+				return f.addSession("refresh", time.Now().Add(-time.Minute))
+			},
+			token:   "refresh",
+			wantErr: errInvalidRefreshToken,
+		},
+		{
+			name: "revoked session",
+			setup: func(f *fakeSessionDB) uuid.UUID {
+				id := f.addSession("refresh", time.Now().Add(time.Hour))
+				f.sessions[id].revoked = true
+				return id
+			},
+			token:       "refresh",
+			wantErr:     errInvalidRefreshToken,
+			wantRevoked: true,
+		},
+		{
+			name: "used token revoke the session",
+			setup: func(f *fakeSessionDB) uuid.UUID {
+				id := f.addSession("refresh", time.Now().Add(time.Hour))
+				f.tokens[hashToken("refresh")].used = true
+				return id
+			},
+			token:       "refresh",
+			wantErr:     errRefreshTokenReused,
+			wantRevoked: true,
+		},
+		{
+			name: "concurrent use revoke the session",
+			setup: func(f *fakeSessionDB) uuid.UUID {
+				f.concurrent = true
+				return f.addSession("refresh", time.Now().Add(time.Hour))
+			},
+			token:       "refresh",
+			wantErr:     errRefreshTokenReused,
+			wantRevoked: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := newFakeSessionDB(customer)
+			sessionID := tt.setup(fake)
+			h := newTestAuthHandler(fake)
+			r := httptest.NewRequest("POST", "/auth/refresh", nil)
+
+			user, tokens, err := h.rotateSession(context.Background(), r, tt.token)
+			if fake.sessions[sessionID].revoked != tt.wantRevoked {
+				t.Errorf("session revoked = %v, want %v", fake.sessions[sessionID].revoked, tt.wantRevoked)
+			}
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("rotateSession() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("rotateSession() error = %v", err)
+			}
+
+			if user.ID != customer.ID {
+				t.Errorf("user = %v, want %v", user.ID, customer.ID)
+			}
+			if !fake.tokens[hashToken(tt.token)].used {
+				t.Error("presented refresh token not marked used")
+			}
+			if fake.sessions[sessionID].expiresAt.Before(time.Now().Add(refreshTokenTTL(user) - time.Minute)) {
+				t.Error("session expiry not extended")
+			}
+
+			rotated, ok := fake.tokens[hashToken(tokens.RefreshToken)]
+			if !ok || rotated.used || rotated.sessionID != sessionID {
+				t.Fatalf("new refresh token not stored for the session: %+v", rotated)
+			}
+
+			access, err := h.conf.AccessAuth.Verify(tokens.AccessToken)
+			if err != nil {
+				t.Fatalf("access token: %v", err)
+			}
+			if sid, _ := access.Get("sid"); sid != sessionID.String() {
+				t.Errorf("access sid = %v, want %v", sid, sessionID)
+			}
+			if lang, _ := access.Get("lang"); lang != "ar" {
+				t.Errorf("access lang = %v, want ar", lang)
+			}
+			if _, err := h.conf.RefreshAuth.Verify(tokens.RefreshToken); err != nil {
+				t.Errorf("refresh token: %v", err)
+			}
+		})
+	}
+}
+
+func TestRotateSessionReuse(t *testing.T) {
+	fake := newFakeSessionDB(db.User{ID: uuid.New()})
+	sessionID := fake.addSession("first", time.Now().Add(time.Hour))
+	h := newTestAuthHandler(fake)
+	r := httptest.NewRequest("POST", "/auth/refresh", nil)
+	ctx := context.Background()
+
+	_, second, err := h.rotateSession(ctx, r, "first")
+	if err != nil {
+		t.Fatalf("first rotation: %v", err)
+	}
+	_, third, err := h.rotateSession(ctx, r, second.RefreshToken)
+	if err != nil {
+		t.Fatalf("second rotation: %v", err)
+	}
+
+	// replaying the leaked first token revoke the session, so the latest token stop working too
+	if _, _, err := h.rotateSession(ctx, r, "first"); !errors.Is(err, errRefreshTokenReused) {
+		t.Fatalf("replay error = %v, want %v", err, errRefreshTokenReused)
+	}
+	if !fake.sessions[sessionID].revoked {
+		t.Fatal("session not revoked after replay")
+	}
+	if _, _, err := h.rotateSession(ctx, r, third.RefreshToken); !errors.Is(err, errInvalidRefreshToken) {
+		t.Errorf("latest token after revoke error = %v, want %v", err, errInvalidRefreshToken)
+	}
+}
+
+func TestVerifyPurposeToken(t *testing.T) {
+	h := newTestAuthHandler(newFakeSessionDB(db.User{}))
+	other := newTestAuthHandler(newFakeSessionDB(db.User{}))
+	other.conf.RefreshAuth = jwtkeys.NewSecret([]byte("other-secret"))
+
+	valid, _ := h.signPurposeToken(mfaPendingPurpose, "user@example.com", time.Minute)
+	expired, _ := h.signPurposeToken(mfaPendingPurpose, "user@example.com", -time.Minute)
+	foreign, _ := other.signPurposeToken(mfaPendingPurpose, "user@example.com", time.Minute)
+	_, refresh, _ := h.conf.RefreshAuth.Encode(map[string]interface{}{"sub": "user@example.com"})
+
+	tests := []struct {
+		name    string
+		token   string
+		purpose string
+		wantErr bool
+	}{
+		{"valid", valid, mfaPendingPurpose, false},
+		{"other purpose", valid, "magic_link", true},
+		{"expired", expired, mfaPendingPurpose, true},
+		{"signed with other key", foreign, mfaPendingPurpose, true},
+		{"token without purpose", refresh, mfaPendingPurpose, true},
+		{"malformed", "not-a-token", mfaPendingPurpose, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			subject, err := h.verifyPurposeToken(tt.token, tt.purpose)
+			if tt.wantErr {
+				if !errors.Is(err, errInvalidPurposeToken) {
+					t.Errorf("verifyPurposeToken() error = %v, want %v", err, errInvalidPurposeToken)
+				}
+				return
+			}
+			if err != nil || subject != "user@example.com" {
+				t.Errorf("verifyPurposeToken() = %q, %v", subject, err)
+			}
+		})
+	}
+}
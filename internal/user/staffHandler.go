@@ -1,6 +1,7 @@
 package user
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	db "github.com/bigusef/texorbit/internal/database"
@@ -10,13 +11,13 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
-	"github.com/jackc/pgx/v5"
-	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"net/http"
 )
 
 type staffHandler struct {
 	conf     *config.Setting
+	pool     *pgxpool.Pool
 	queries  *db.Queries
 	validate *validator.Validate
 }
@@ -26,11 +27,14 @@ func (h *staffHandler) listStaffHandler(w http.ResponseWriter, r *http.Request)
 
 	page := ctx.Value("pagination").(*middleware.Paginator)
 
-	staff, err := h.queries.AllStaff(ctx, db.AllStaffParams{page.Limit, page.Offset})
+	staff, more, err := h.pageStaff(ctx, page)
 	if err != nil {
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		util.ErrorResponseWriter(w, r, err)
 		return
 	}
+	first, last := pageKeys(staff)
+	page.SetLinks(w, first, last, more)
+
 	result := make([]*listStaff, len(staff))
 	for i, v := range staff {
 		result[i] = &listStaff{
@@ -41,13 +45,60 @@ func (h *staffHandler) listStaffHandler(w http.ResponseWriter, r *http.Request)
 		}
 	}
 
-	count, err := h.queries.AllStaffCount(ctx)
+	count, err := h.countStaff(ctx, page.Count)
 	if err != nil {
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		util.ErrorResponseWriter(w, r, err)
 		return
 	}
 
-	util.JsonListResponseWriter(w, http.StatusOK, result, count)
+	util.JsonPageResponseWriter(w, http.StatusOK, result, count)
+}
+
+// countStaff total count of the staff in the count mode, nil when it is not counted
+func (h *staffHandler) countStaff(ctx context.Context, mode string) (*int64, error) {
+	var count int64
+	var err error
+	switch mode {
+	case middleware.CountExact:
+		count, err = h.queries.AllStaffCount(ctx)
+	case middleware.CountEstimated:
+		count, err = estimateAllStaffCount(ctx, h.pool)
+	default:
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &count, nil
+}
+
+// pageStaff query the staff of the offset or keyset page, it reports whether more staff
+// follow the page in the direction it was read
+func (h *staffHandler) pageStaff(ctx context.Context, page *middleware.Paginator) ([]db.User, bool, error) {
+	var staff []db.User
+	var err error
+	switch {
+	case page.Cursor == nil:
+		staff, err = h.queries.AllStaff(ctx, db.AllStaffParams{Limit: page.Fetch(), Offset: page.Offset})
+	case page.Backward():
+		joinDate, id, keyErr := cursorKeys(page.Cursor)
+		if keyErr != nil {
+			return nil, false, keyErr
+		}
+		staff, err = h.queries.StaffBefore(ctx, db.StaffBeforeParams{BeforeJoinDate: joinDate, BeforeID: id, PageSize: page.Fetch()})
+	default:
+		joinDate, id, keyErr := cursorKeys(page.Cursor)
+		if keyErr != nil {
+			return nil, false, keyErr
+		}
+		staff, err = h.queries.StaffAfter(ctx, db.StaffAfterParams{AfterJoinDate: joinDate, AfterID: id, PageSize: page.Fetch()})
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	staff, more := middleware.Page(page, staff)
+	return staff, more, nil
 }
 
 func (h *staffHandler) createStaffHandler(w http.ResponseWriter, r *http.Request) {
@@ -55,119 +106,106 @@ func (h *staffHandler) createStaffHandler(w http.ResponseWriter, r *http.Request
 
 	var input newStaff
 	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
-		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		util.ErrorResponseWriter(w, r, util.BadRequest("invalid request body"))
 		return
 	}
 
 	if err := h.validate.Struct(input); err != nil {
-		ts := map[string]string{}
-		for _, err := range err.(validator.ValidationErrors) {
-			ts[err.Field()] = err.Tag()
-		}
-
-		util.JsonResponseWriter(w, http.StatusBadRequest, ts)
+		util.ErrorResponseWriter(w, r, util.ValidationFailed(err))
 		return
 	}
 
-	if _, err := h.queries.GetUserByEmail(ctx, input.Email); err != nil {
-		if !errors.Is(err, pgx.ErrNoRows) {
-			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	if input.Roles == nil {
+		input.Roles = []int64{}
+	}
+
+	roles, err := roleNames(ctx, h.queries, input.Roles)
+	if err != nil {
+		if errors.Is(err, errUnknownRole) {
+			util.ErrorResponseWriter(w, r, util.Invalid(map[string]string{"roles": "unknown_role"}))
 			return
 		}
-	} else {
-		util.JsonResponseWriter(w, http.StatusBadRequest, map[string]string{"email": "email already used by another user"})
+
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	if ok, err := rolesGrantable(ctx, h.queries, r, input.Roles); err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	} else if !ok {
+		util.ErrorResponseWriter(w, r, util.Forbidden(errNotGrantable.Error()))
 		return
 	}
 
 	user, err := h.queries.CreateUser(ctx, db.CreateUserParams{
 		Name:        input.Name,
-		Email:       input.Email,
-		PhoneNumber: pgtype.Text{String: input.PhoneNumber, Valid: true},
+		Email:       util.NormalizeEmail(input.Email),
+		PhoneNumber: phoneText(input.PhoneNumber),
 		IsStaff:     true,
+		// staff account is verified by its owner first login
+		Status: db.AccountStatusPendingVerification,
 	})
 	if err != nil {
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		util.ErrorResponseWriter(w, r, err)
 		return
 	}
 
-	util.JsonResponseWriter(w, http.StatusCreated, staffInfo{
-		Id:          user.ID,
-		Name:        user.Name,
-		Email:       user.Email,
-		Avatar:      user.Avatar.String,
-		PhoneNumber: user.PhoneNumber.String,
-		Status:      string(user.Status),
-		JoinDate:    user.JoinDate.Time,
-		LastLogin:   user.LastLogin.Time,
-	})
+	// new staff member has no permissions until roles assigned
+	if err = h.queries.SetUserRoles(ctx, db.SetUserRolesParams{UserID: user.ID, RoleIds: input.Roles}); err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	info := newStaffInfo(user)
+	info.Roles = roles
+
+	util.JsonResponseWriter(w, http.StatusCreated, info)
 }
 
 func (h *staffHandler) updateStaffHandler(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	id, err := uuid.Parse(chi.URLParam(r, "id"))
 	if err != nil {
-		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		util.ErrorResponseWriter(w, r, util.BadRequest("invalid id"))
 		return
 	}
 
-	user, err := h.queries.GetUSerById(ctx, id)
-	if err != nil {
-		if !errors.Is(err, pgx.ErrNoRows) {
-			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
-			return
-		}
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	// unknown staff member is not found, used emails are conflicts (users.email is unique)
+	if _, err := h.queries.GetUSerById(ctx, id); err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	if ok, err := staffEditable(ctx, h.queries, r, id); err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	} else if !ok {
+		util.ErrorResponseWriter(w, r, util.Forbidden(errNotGrantable.Error()))
 		return
 	}
 
 	var input updateStaff
 	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
-		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		util.ErrorResponseWriter(w, r, util.BadRequest("invalid request body"))
 		return
 	}
 
 	if err := h.validate.Struct(input); err != nil {
-		ts := map[string]string{}
-		for _, err := range err.(validator.ValidationErrors) {
-			ts[err.Field()] = err.Tag()
-		}
-
-		util.JsonResponseWriter(w, http.StatusBadRequest, ts)
+		util.ErrorResponseWriter(w, r, util.ValidationFailed(err))
 		return
 	}
 
-	if input.Email != user.Email {
-		if _, err := h.queries.GetUserByEmail(ctx, input.Email); err != nil {
-			if !errors.Is(err, pgx.ErrNoRows) {
-				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
-				return
-			}
-		} else {
-			util.JsonResponseWriter(w, http.StatusBadRequest, map[string]string{"email": "email already used by another user"})
-			return
-		}
-	}
-
 	updatedUser, err := h.queries.UpdateUser(ctx, db.UpdateUserParams{
 		ID:          id,
 		Name:        input.Name,
-		Email:       input.Email,
-		PhoneNumber: pgtype.Text{String: input.PhoneNumber, Valid: true},
-		Status:      input.Status,
+		Email:       util.NormalizeEmail(input.Email),
+		PhoneNumber: phoneText(input.PhoneNumber),
 	})
 	if err != nil {
-		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		util.ErrorResponseWriter(w, r, err)
 		return
 	}
 
-	util.JsonResponseWriter(w, http.StatusOK, staffInfo{
-		Id:          updatedUser.ID,
-		Name:        updatedUser.Name,
-		Email:       updatedUser.Email,
-		Avatar:      updatedUser.Avatar.String,
-		PhoneNumber: updatedUser.PhoneNumber.String,
-		Status:      string(updatedUser.Status),
-		JoinDate:    updatedUser.JoinDate.Time,
-		LastLogin:   updatedUser.LastLogin.Time,
-	})
+	util.JsonResponseWriter(w, http.StatusOK, newStaffInfo(updatedUser))
 }
@@ -14,25 +14,41 @@ type listStaff struct {
 }
 
 type newStaff struct {
-	Name        string `json:"name" validate:"required"`
-	Email       string `json:"email" validate:"required,email"`
-	PhoneNumber string `json:"phone_number" validate:"phone_number"`
+	Name        string  `json:"name" validate:"required"`
+	Email       string  `json:"email" validate:"required,email"`
+	PhoneNumber string  `json:"phone_number" validate:"phone_number"`
+	Roles       []int64 `json:"roles"`
 }
 
 type staffInfo struct {
-	Id          uuid.UUID `json:"id"`
-	Name        string    `json:"name"`
-	Email       string    `json:"email"`
-	PhoneNumber string    `json:"phone_number"`
-	Avatar      string    `json:"avatar"`
-	Status      string    `json:"status"`
-	JoinDate    time.Time `json:"joinDate"`
-	LastLogin   time.Time `json:"lastLogin"`
+	Id                uuid.UUID `json:"id"`
+	Name              string    `json:"name"`
+	Email             string    `json:"email"`
+	PhoneNumber       string    `json:"phone_number"`
+	Avatar            string    `json:"avatar"`
+	Status            string    `json:"status"`
+	JoinDate          time.Time `json:"joinDate"`
+	LastLogin         time.Time `json:"lastLogin"`
+	Roles             []string  `json:"roles,omitempty"`
+	PreferredLanguage string    `json:"preferred_language,omitempty"`
 }
 
 type updateStaff struct {
-	Name        string                 `json:"name"`
-	Email       string                 `json:"email" validate:"required,email"`
-	PhoneNumber string                 `json:"phone_number"`
-	Status      database.AccountStatus `json:"status" validate:"required"`
+	Name        string `json:"name"`
+	Email       string `json:"email" validate:"required,email"`
+	PhoneNumber string `json:"phone_number" validate:"omitempty,phone_number"`
+}
+
+func newStaffInfo(user database.User) staffInfo {
+	return staffInfo{
+		Id:                user.ID,
+		Name:              user.Name,
+		Email:             user.Email,
+		Avatar:            user.Avatar.String,
+		PhoneNumber:       user.PhoneNumber.String,
+		Status:            string(user.Status),
+		JoinDate:          user.JoinDate.Time,
+		LastLogin:         user.LastLogin.Time,
+		PreferredLanguage: user.PreferredLanguage.String,
+	}
 }
@@ -0,0 +1,268 @@
+package user
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	db "github.com/bigusef/texorbit/internal/database"
+	"github.com/bigusef/texorbit/pkg/middleware"
+	"github.com/bigusef/texorbit/pkg/util"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// statusTransitions allowed account status changes, deleted accounts can not be revived
+var statusTransitions = map[db.AccountStatus][]db.AccountStatus{
+	db.AccountStatusPendingVerification: {db.AccountStatusActive, db.AccountStatusSuspended, db.AccountStatusDeleted},
+	db.AccountStatusActive:              {db.AccountStatusSuspended, db.AccountStatusDeleted},
+	db.AccountStatusSuspended:           {db.AccountStatusActive, db.AccountStatusDeleted},
+	db.AccountStatusDeleted:             {},
+}
+
+type statusChange struct {
+	Status db.AccountStatus `json:"status" validate:"required,oneof=active suspended deleted"`
+	Reason string           `json:"reason" validate:"required,max=255"`
+	// Until end the suspension automatically
+	Until *time.Time `json:"until" validate:"omitempty,excluded_unless=Status suspended"`
+}
+
+type statusChangeInfo struct {
+	FromStatus string     `json:"from_status"`
+	ToStatus   string     `json:"to_status"`
+	Reason     string     `json:"reason"`
+	ChangedBy  *uuid.UUID `json:"changed_by"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+func newStatusChangeInfo(c db.UserStatusHistory) statusChangeInfo {
+	info := statusChangeInfo{
+		FromStatus: string(c.FromStatus),
+		ToStatus:   string(c.ToStatus),
+		Reason:     c.Reason,
+		CreatedAt:  c.CreatedAt.Time,
+	}
+	if c.ChangedBy.Valid {
+		changedBy := uuid.UUID(c.ChangedBy.Bytes)
+		info.ChangedBy = &changedBy
+	}
+	return info
+}
+
+func canChangeStatus(from, to db.AccountStatus) bool {
+	for _, status := range statusTransitions[from] {
+		if status == to {
+			return true
+		}
+	}
+
+	return false
+}
+
+// changeStatus move the user to the requested status and record the change with its reason,
+// accounts leaving the active states lose all their sessions
+func changeStatus(w http.ResponseWriter, r *http.Request, queries *db.Queries, validate *validator.Validate, user db.User) (db.User, bool) {
+	ctx := r.Context()
+
+	var input statusChange
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		util.ErrorResponseWriter(w, r, util.BadRequest("invalid request body"))
+		return user, false
+	}
+
+	if err := validate.Struct(input); err != nil {
+		util.ErrorResponseWriter(w, r, util.ValidationFailed(err))
+		return user, false
+	}
+
+	if input.Until != nil && !input.Until.After(time.Now()) {
+		util.ErrorResponseWriter(w, r, util.Invalid(map[string]string{"until": "future"}))
+		return user, false
+	}
+
+	if !canChangeStatus(user.Status, input.Status) {
+		util.ErrorResponseWriter(w, r, util.Conflict("account can not move from "+string(user.Status)+" to "+string(input.Status)))
+		return user, false
+	}
+
+	params := db.ChangeUserStatusParams{
+		ToStatus:   input.Status,
+		Reason:     pgtype.Text{String: input.Reason, Valid: true},
+		ID:         user.ID,
+		FromStatus: user.Status,
+	}
+	if input.Until != nil {
+		params.SuspendedUntil = pgtype.Timestamptz{Time: *input.Until, Valid: true}
+	}
+	// api keys have no user to record
+	if staffId, err := currentUserId(r); err == nil {
+		params.ChangedBy = pgtype.UUID{Bytes: staffId, Valid: true}
+	}
+
+	updated, err := queries.ChangeUserStatus(ctx, params)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			// status changed by another request in the meantime
+			util.ErrorResponseWriter(w, r, util.Conflict("account status changed, please try again"))
+			return user, false
+		}
+
+		util.ErrorResponseWriter(w, r, err)
+		return user, false
+	}
+
+	// suspended or deleted user should not be able to refresh his tokens
+	if !updated.IsActive() {
+		if err := queries.RevokeUserSessions(ctx, updated.ID); err != nil {
+			util.ErrorResponseWriter(w, r, err)
+			return user, false
+		}
+	}
+
+	return updated, true
+}
+
+// writeStatusHistory write page of the user status changes
+func writeStatusHistory(w http.ResponseWriter, r *http.Request, queries *db.Queries, userId uuid.UUID) {
+	ctx := r.Context()
+
+	page := ctx.Value("pagination").(*middleware.Paginator)
+
+	changes, err := queries.UserStatusChanges(ctx, db.UserStatusChangesParams{UserID: userId, Limit: page.Limit, Offset: page.Offset})
+	if err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	result := make([]statusChangeInfo, len(changes))
+	for i, c := range changes {
+		result[i] = newStatusChangeInfo(c)
+	}
+
+	count, err := queries.UserStatusChangesCount(ctx, userId)
+	if err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return
+	}
+
+	util.JsonListResponseWriter(w, http.StatusOK, result, count)
+}
+
+func (h *customerHandler) changeCustomerStatus(w http.ResponseWriter, r *http.Request) {
+	customer, ok := h.customerFromURL(w, r)
+	if !ok {
+		return
+	}
+
+	updated, ok := changeStatus(w, r, h.queries, h.validate, customer)
+	if !ok {
+		return
+	}
+
+	util.JsonResponseWriter(w, http.StatusOK, newCustomerInfo(updated))
+}
+
+func (h *customerHandler) listCustomerStatusHistory(w http.ResponseWriter, r *http.Request) {
+	customer, ok := h.customerFromURL(w, r)
+	if !ok {
+		return
+	}
+
+	writeStatusHistory(w, r, h.queries, customer.ID)
+}
+
+func (h *staffHandler) changeStaffStatus(w http.ResponseWriter, r *http.Request) {
+	staff, ok := h.staffFromURL(w, r)
+	if !ok {
+		return
+	}
+
+	// staff can not lock themselves out
+	if userId, err := currentUserId(r); err == nil && userId == staff.ID {
+		util.ErrorResponseWriter(w, r, util.Forbidden("you can not change your own account status"))
+		return
+	}
+
+	updated, ok := changeStatus(w, r, h.queries, h.validate, staff)
+	if !ok {
+		return
+	}
+
+	util.JsonResponseWriter(w, http.StatusOK, newStaffInfo(updated))
+}
+
+func (h *staffHandler) listStaffStatusHistory(w http.ResponseWriter, r *http.Request) {
+	staff, ok := h.staffFromURL(w, r)
+	if !ok {
+		return
+	}
+
+	writeStatusHistory(w, r, h.queries, staff.ID)
+}
+
+// staffFromURL return the staff member of the "id" url parameter
+func (h *staffHandler) staffFromURL(w http.ResponseWriter, r *http.Request) (db.User, bool) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		util.ErrorResponseWriter(w, r, util.BadRequest("invalid user id"))
+		return db.User{}, false
+	}
+
+	staff, err := h.queries.GetUSerById(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			util.ErrorResponseWriter(w, r, util.NotFound("staff member not found"))
+			return db.User{}, false
+		}
+
+		util.ErrorResponseWriter(w, r, err)
+		return db.User{}, false
+	}
+	if !staff.IsStaff {
+		util.ErrorResponseWriter(w, r, util.NotFound("staff member not found"))
+		return db.User{}, false
+	}
+
+	return staff, true
+}
+
+// activatePendingUser move pending verification account to active on its owner first login
+func activatePendingUser(ctx context.Context, queries *db.Queries, user db.User) (db.User, error) {
+	if user.Status != db.AccountStatusPendingVerification {
+		return user, nil
+	}
+
+	return queries.ChangeUserStatus(ctx, db.ChangeUserStatusParams{
+		ToStatus:   db.AccountStatusActive,
+		Reason:     pgtype.Text{String: "verified by first login", Valid: true},
+		ID:         user.ID,
+		FromStatus: user.Status,
+		ChangedBy:  pgtype.UUID{Bytes: user.ID, Valid: true},
+	})
+}
+
+// SuspensionJob reactivate accounts whose scheduled suspension ended, every interval until the context is done
+func SuspensionJob(ctx context.Context, queries *db.Queries, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		ids, err := queries.UnsuspendDueUsers(ctx)
+		if err != nil {
+			slog.Error("failed to end account suspensions", "error", err)
+		} else if len(ids) > 0 {
+			slog.Info("account suspensions ended", "count", len(ids))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package user
+
+import (
+	db "github.com/bigusef/texorbit/internal/database"
+	"github.com/go-playground/validator/v10"
+	"testing"
+	"time"
+)
+
+func TestCanChangeStatus(t *testing.T) {
+	pending, active, suspended, deleted := db.AccountStatusPendingVerification, db.AccountStatusActive, db.AccountStatusSuspended, db.AccountStatusDeleted
+
+	tests := []struct {
+		from db.AccountStatus
+		to   db.AccountStatus
+		want bool
+	}{
+		{pending, pending, false},
+		{pending, active, true},
+		{pending, suspended, true},
+		{pending, deleted, true},
+		{active, pending, false},
+		{active, active, false},
+		{active, suspended, true},
+		{active, deleted, true},
+		{suspended, pending, false},
+		{suspended, active, true},
+		{suspended, suspended, false},
+		{suspended, deleted, true},
+		{deleted, pending, false},
+		{deleted, active, false},
+		{deleted, suspended, false},
+		{deleted, deleted, false},
+		{"unknown", active, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.from)+"->"+string(tt.to), func(t *testing.T) {
+			if got := canChangeStatus(tt.from, tt.to); got != tt.want {
+				t.Errorf("canChangeStatus(%s, %s) = %v, want %v", tt.from, tt.to, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStatusChangeValidation(t *testing.T) {
+	until := time.Now().Add(time.Hour * 24)
+
+	tests := []struct {
+		name    string
+		input   statusChange
+		wantErr bool
+	}{
+		{"suspend until date", statusChange{Status: db.AccountStatusSuspended, Reason: "chargeback", Until: &until}, false},
+		{"suspend without end", statusChange{Status: db.AccountStatusSuspended, Reason: "chargeback"}, false},
+		{"activate", statusChange{Status: db.AccountStatusActive, Reason: "resolved"}, false},
+		{"until only for suspension", statusChange{Status: db.AccountStatusActive, Reason: "resolved", Until: &until}, true},
+		{"pending is not a target", statusChange{Status: db.AccountStatusPendingVerification, Reason: "reset"}, true},
+		{"missing reason", statusChange{Status: db.AccountStatusDeleted}, true},
+	}
+
+	validate := validator.New()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validate.Struct(tt.input); (err != nil) != tt.wantErr {
+				t.Errorf("validate error = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
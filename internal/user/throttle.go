@@ -0,0 +1,131 @@
+package user
+
+import (
+	"context"
+	"errors"
+	db "github.com/bigusef/texorbit/internal/database"
+	"github.com/bigusef/texorbit/pkg/util"
+	"github.com/jackc/pgx/v5/pgtype"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// login attempts are throttled per client ip and per account, failures older than an hour are forgotten
+const (
+	loginLockout = time.Minute * 15
+
+	// failures allowed before backoff start, and failures that lock out for loginLockout,
+	// ip limits are higher as many users can share the same address
+	accountFreeAttempts = 3
+	accountMaxFailures  = 10
+	ipFreeAttempts      = 10
+	ipMaxFailures       = 50
+)
+
+var errLoginFailed = errors.New("login failed, please check your account and try again")
+
+func ipThrottleKey(r *http.Request) string {
+	_, _, ip := clientInfo(r)
+	return "ip:" + ip
+}
+
+func accountThrottleKey(email string) string {
+	return "account:" + strings.ToLower(email)
+}
+
+// loginLockDuration exponential backoff after the free attempts, capped by the lockout duration
+func loginLockDuration(key string, failures int32) time.Duration {
+	free, max := int32(accountFreeAttempts), int32(accountMaxFailures)
+	if strings.HasPrefix(key, "ip:") {
+		free, max = ipFreeAttempts, ipMaxFailures
+	}
+
+	if failures < free {
+		return 0
+	}
+	if failures >= max {
+		return loginLockout
+	}
+
+	// large shifts overflow the duration, they are far beyond the lockout anyway
+	exponent := failures - free
+	if exponent >= 32 {
+		return loginLockout
+	}
+
+	backoff := time.Second << exponent
+	if backoff > loginLockout {
+		return loginLockout
+	}
+
+	return backoff
+}
+
+// loginLocked return how long the given keys are still locked, zero when login is allowed
+func loginLocked(ctx context.Context, queries *db.Queries, keys ...string) (time.Duration, error) {
+	throttles, err := queries.LockedLoginThrottles(ctx, keys)
+	if err != nil {
+		return 0, err
+	}
+
+	var wait time.Duration
+	for _, t := range throttles {
+		if remaining := time.Until(t.LockedUntil.Time); remaining > wait {
+			wait = remaining
+		}
+	}
+
+	return wait, nil
+}
+
+// loginFailed record failed attempt for each key and lock it according to its failures
+func loginFailed(ctx context.Context, queries *db.Queries, keys ...string) {
+	for _, key := range keys {
+		failures, err := queries.RecordLoginFailure(ctx, key)
+		if err != nil {
+			slog.Error("failed to record login failure", "key", key, "error", err)
+			continue
+		}
+
+		if lock := loginLockDuration(key, failures); lock > 0 {
+			err := queries.LockLogin(ctx, db.LockLoginParams{
+				LockedUntil: pgtype.Timestamptz{Time: time.Now().Add(lock), Valid: true},
+				Key:         key,
+			})
+			if err != nil {
+				slog.Error("failed to lock login", "key", key, "error", err)
+			}
+		}
+
+		if failures == accountMaxFailures && strings.HasPrefix(key, "account:") {
+			slog.Warn("account login locked out", "key", key, "failures", failures)
+		}
+	}
+}
+
+// loginSucceeded forget the account failures, ip failures are kept until they expire
+func loginSucceeded(ctx context.Context, queries *db.Queries, email string) {
+	if _, err := queries.ClearLoginFailures(ctx, accountThrottleKey(email)); err != nil {
+		slog.Error("failed to clear login failures", "error", err)
+	}
+}
+
+// checkLoginLocked write too many requests response when any of the keys is locked
+func checkLoginLocked(w http.ResponseWriter, r *http.Request, queries *db.Queries, keys ...string) bool {
+	wait, err := loginLocked(r.Context(), queries, keys...)
+	if err != nil {
+		util.ErrorResponseWriter(w, r, err)
+		return true
+	}
+
+	if wait > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(wait.Seconds())+1))
+		util.ErrorResponseWriter(w, r, util.TooManyRequests("too many failed attempts, try again later"))
+		return true
+	}
+
+	return false
+}
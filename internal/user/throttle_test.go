@@ -0,0 +1,70 @@
+package user
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLoginLockDuration(t *testing.T) {
+	tests := []struct {
+		name string
+		key  string
+		free int32
+		max  int32
+	}{
+		{"account", accountThrottleKey("User@Example.com"), accountFreeAttempts, accountMaxFailures},
+		{"ip", "ip:203.0.113.7", ipFreeAttempts, ipMaxFailures},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var previous time.Duration
+			for failures := int32(0); failures <= tt.max+5; failures++ {
+				lock := loginLockDuration(tt.key, failures)
+
+				switch {
+				case failures < tt.free:
+					if lock != 0 {
+						t.Errorf("failures %d: lock = %v, want no lock before free attempts used", failures, lock)
+					}
+				case failures >= tt.max:
+					if lock != loginLockout {
+						t.Errorf("failures %d: lock = %v, want lockout %v", failures, lock, loginLockout)
+					}
+				default:
+					if lock <= 0 || lock > loginLockout {
+						t.Errorf("failures %d: lock = %v, want between 0 and %v", failures, lock, loginLockout)
+					}
+					if lock < previous {
+						t.Errorf("failures %d: lock = %v, shorter than previous %v", failures, lock, previous)
+					}
+				}
+				previous = lock
+			}
+		})
+	}
+}
+
+func TestLoginLockDurationBackoff(t *testing.T) {
+	tests := []struct {
+		key      string
+		failures int32
+		want     time.Duration
+	}{
+		{accountThrottleKey("user@example.com"), accountFreeAttempts, time.Second},
+		{accountThrottleKey("user@example.com"), accountFreeAttempts + 1, 2 * time.Second},
+		{accountThrottleKey("user@example.com"), accountFreeAttempts + 6, 64 * time.Second},
+		{"ip:203.0.113.7", ipFreeAttempts, time.Second},
+		{"ip:203.0.113.7", ipFreeAttempts + 9, 512 * time.Second},
+		{"ip:203.0.113.7", ipFreeAttempts + 10, loginLockout},
+		// shifts of 34 and more used to overflow to negative durations
+		{"ip:203.0.113.7", 44, loginLockout},
+		{"ip:203.0.113.7", 49, loginLockout},
+	}
+
+	for _, tt := range tests {
+		if got := loginLockDuration(tt.key, tt.failures); got != tt.want {
+			t.Errorf("loginLockDuration(%q, %d) = %v, want %v", tt.key, tt.failures, got, tt.want)
+		}
+	}
+}
@@ -3,17 +3,29 @@ package config
 import (
 	"errors"
 	"fmt"
-	"github.com/go-chi/jwtauth/v5"
+	"github.com/bigusef/texorbit/pkg/jwtkeys"
 	"os"
+	"strings"
 )
 
-func getJWTSecret(key string) (*jwtauth.JWTAuth, error) {
-	jwtSecretKey := os.Getenv(key)
-	if jwtSecretKey == "" {
-		return nil, errors.New(fmt.Sprintf(`messing environment variable "%s"`, key))
+// getJWTKeys load the token key ring for the given prefix (ex: "JWT_ACCESS") signing tokens of the given type,
+// keys are read from <prefix>_KEYS comma separated PEM files or directories and the signing key
+// selected by <prefix>_KEY_ID, falling back to HS256 <prefix>_SECRET when no keys configured
+func getJWTKeys(prefix, tokenType string) (*jwtkeys.KeyRing, error) {
+	var ring *jwtkeys.KeyRing
+	if keys := os.Getenv(prefix + "_KEYS"); keys != "" {
+		var err error
+		if ring, err = jwtkeys.Load(strings.Split(keys, ","), os.Getenv(prefix+"_KEY_ID")); err != nil {
+			return nil, err
+		}
+	} else {
+		jwtSecretKey := os.Getenv(prefix + "_SECRET")
+		if jwtSecretKey == "" {
+			return nil, errors.New(fmt.Sprintf(`messing environment variable "%s_KEYS"`, prefix))
+		}
+		ring = jwtkeys.NewSecret([]byte(jwtSecretKey))
 	}
 
-	tokenAuth := jwtauth.New("HS256", []byte(jwtSecretKey), nil)
-
-	return tokenAuth, nil
+	ring.SetType(tokenType)
+	return ring, nil
 }
@@ -0,0 +1,78 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"github.com/bigusef/texorbit/pkg/i18n"
+	"os"
+	"strings"
+)
+
+// getLocales parse LOCALES environment variable, comma separated BCP 47 tags of the locales requests can select
+// (ex: en,ar,fr,fr-CA), english and arabic by default. english is always supported as the default locale
+func getLocales() ([]string, error) {
+	value := os.Getenv("LOCALES")
+	if strings.TrimSpace(value) == "" {
+		return []string{i18n.English, i18n.Arabic}, nil
+	}
+
+	locales := []string{i18n.English}
+	for _, tag := range strings.Split(value, ",") {
+		locale, err := canonicalLocale("LOCALES", tag)
+		if err != nil {
+			return nil, err
+		}
+		if !contains(locales, locale) {
+			locales = append(locales, locale)
+		}
+	}
+
+	return locales, nil
+}
+
+// getLocaleFallbacks parse LOCALE_FALLBACKS environment variable, semicolon separated locales with the comma
+// separated locales tried before english when a text is missing in them (ex: fr-CA=fr;pt-BR=pt-PT,pt)
+func getLocaleFallbacks() (map[string][]string, error) {
+	fallbacks := map[string][]string{}
+	for _, entry := range strings.Split(os.Getenv("LOCALE_FALLBACKS"), ";") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+
+		tag, values, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, errors.New(fmt.Sprintf(`invalid LOCALE_FALLBACKS entry "%s", use locale=fallback,...`, entry))
+		}
+		locale, err := canonicalLocale("LOCALE_FALLBACKS", tag)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, value := range strings.Split(values, ",") {
+			fallback, err := canonicalLocale("LOCALE_FALLBACKS", value)
+			if err != nil {
+				return nil, err
+			}
+			fallbacks[locale] = append(fallbacks[locale], fallback)
+		}
+	}
+
+	return fallbacks, nil
+}
+
+func canonicalLocale(name, tag string) (string, error) {
+	locale, ok := i18n.Canonical(strings.TrimSpace(tag))
+	if !ok {
+		return "", errors.New(fmt.Sprintf(`invalid %s locale "%s"`, name, tag))
+	}
+	return locale, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,51 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGetLocales(t *testing.T) {
+	tests := []struct {
+		value string
+		want  []string
+		err   bool
+	}{
+		{"", []string{"en", "ar"}, false},
+		{"ar,en,fr", []string{"en", "ar", "fr"}, false},
+		{" fr-ca , AR ", []string{"en", "fr-CA", "ar"}, false},
+		{"fr,fr", []string{"en", "fr"}, false},
+		{"en,not a locale", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Setenv("LOCALES", tt.value)
+		got, err := getLocales()
+		if (err != nil) != tt.err || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("getLocales(%q) = %v, %v, want %v", tt.value, got, err, tt.want)
+		}
+	}
+}
+
+func TestGetLocaleFallbacks(t *testing.T) {
+	tests := []struct {
+		value string
+		want  map[string][]string
+		err   bool
+	}{
+		{"", map[string][]string{}, false},
+		{"fr-CA=fr", map[string][]string{"fr-CA": {"fr"}}, false},
+		{"fr-ca=FR; pt-BR=pt-PT,pt;", map[string][]string{"fr-CA": {"fr"}, "pt-BR": {"pt-PT", "pt"}}, false},
+		{"fr-CA", nil, true},
+		{"fr-CA=", nil, true},
+		{"fr-CA=fr,?", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Setenv("LOCALE_FALLBACKS", tt.value)
+		got, err := getLocaleFallbacks()
+		if (err != nil) != tt.err || (!tt.err && !reflect.DeepEqual(got, tt.want)) {
+			t.Errorf("getLocaleFallbacks(%q) = %v, %v, want %v", tt.value, got, err, tt.want)
+		}
+	}
+}
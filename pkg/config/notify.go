@@ -0,0 +1,53 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"github.com/bigusef/texorbit/pkg/notify"
+	"os"
+)
+
+// getSMSSender select the SMS sender from SMS_SENDER environment variable ("log" by default, or "file")
+func getSMSSender() (notify.SMSSender, error) {
+	switch sender := os.Getenv("SMS_SENDER"); sender {
+	case "", "log":
+		return notify.LogSMSSender{}, nil
+	case "file":
+		path := os.Getenv("SMS_FILE_PATH")
+		if path == "" {
+			return nil, errors.New(`messing environment variable "SMS_FILE_PATH"`)
+		}
+		return &notify.FileSMSSender{Path: path}, nil
+	default:
+		return nil, errors.New(fmt.Sprintf(`unsupported SMS_SENDER "%s"`, sender))
+	}
+}
+
+// getMailer select the mailer from MAIL_SENDER environment variable ("log" by default, or "smtp"),
+// SMTP server configured by SMTP_HOST, SMTP_PORT, SMTP_USERNAME, SMTP_PASSWORD and SMTP_FROM
+func getMailer() (notify.Mailer, error) {
+	switch sender := os.Getenv("MAIL_SENDER"); sender {
+	case "", "log":
+		return notify.LogMailer{}, nil
+	case "smtp":
+		mailer := &notify.SMTPMailer{
+			Host:     os.Getenv("SMTP_HOST"),
+			Port:     os.Getenv("SMTP_PORT"),
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     os.Getenv("SMTP_FROM"),
+		}
+		if mailer.Port == "" {
+			mailer.Port = "587"
+		}
+
+		for key, value := range map[string]string{"SMTP_HOST": mailer.Host, "SMTP_FROM": mailer.From} {
+			if value == "" {
+				return nil, errors.New(fmt.Sprintf(`messing environment variable "%s"`, key))
+			}
+		}
+		return mailer, nil
+	default:
+		return nil, errors.New(fmt.Sprintf(`unsupported MAIL_SENDER "%s"`, sender))
+	}
+}
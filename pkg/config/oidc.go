@@ -0,0 +1,46 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"github.com/bigusef/texorbit/pkg/oidc"
+	"os"
+	"strings"
+)
+
+// getOIDCProviders build the enabled OpenID Connect providers listed in OIDC_PROVIDERS (comma separated),
+// each provider read its settings from OIDC_<NAME>_ISSUER, OIDC_<NAME>_CLIENT_ID, OIDC_<NAME>_CLIENT_SECRET
+// and OIDC_<NAME>_REDIRECT_URL
+func getOIDCProviders() (map[string]*oidc.Provider, error) {
+	providers := map[string]*oidc.Provider{}
+
+	names := os.Getenv("OIDC_PROVIDERS")
+	if names == "" {
+		return providers, nil
+	}
+
+	for _, name := range strings.Split(names, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+		conf := oidc.Config{Name: name}
+		values := map[string]*string{
+			"ISSUER":        &conf.Issuer,
+			"CLIENT_ID":     &conf.ClientID,
+			"CLIENT_SECRET": &conf.ClientSecret,
+			"REDIRECT_URL":  &conf.RedirectURL,
+		}
+		for key, value := range values {
+			if *value = os.Getenv(prefix + key); *value == "" {
+				return nil, errors.New(fmt.Sprintf(`messing environment variable "%s"`, prefix+key))
+			}
+		}
+
+		providers[name] = oidc.NewProvider(conf, nil)
+	}
+
+	return providers, nil
+}
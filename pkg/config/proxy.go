@@ -0,0 +1,42 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+)
+
+// getTrustedProxies parse TRUSTED_PROXIES environment variable, comma separated addresses or CIDR ranges
+// (ex: 10.0.0.0/8,192.168.1.10) of the proxies allowed to forward the client address. none by default
+func getTrustedProxies() ([]*net.IPNet, error) {
+	var proxies []*net.IPNet
+	for _, value := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+
+		if !strings.Contains(value, "/") {
+			ip := net.ParseIP(value)
+			if ip == nil {
+				return nil, errors.New(fmt.Sprintf(`invalid TRUSTED_PROXIES address "%s"`, value))
+			}
+			bits := 8 * net.IPv4len
+			if ip.To4() == nil {
+				bits = 8 * net.IPv6len
+			}
+			proxies = append(proxies, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, network, err := net.ParseCIDR(value)
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf(`invalid TRUSTED_PROXIES range "%s"`, value))
+		}
+		proxies = append(proxies, network)
+	}
+
+	return proxies, nil
+}
@@ -1,16 +1,46 @@
 package config
 
 import (
+	"crypto/rand"
 	"errors"
-	"github.com/go-chi/jwtauth/v5"
+	"fmt"
+	"github.com/bigusef/texorbit/pkg/jwtkeys"
+	"github.com/bigusef/texorbit/pkg/notify"
+	"github.com/bigusef/texorbit/pkg/oidc"
+	"github.com/bigusef/texorbit/pkg/storage"
+	"log/slog"
+	"net"
 	"os"
+	"strings"
 )
 
 type Setting struct {
 	Port        string
 	ConnString  string
-	AccessAuth  *jwtauth.JWTAuth
-	RefreshAuth *jwtauth.JWTAuth
+	AccessAuth  *jwtkeys.KeyRing
+	RefreshAuth *jwtkeys.KeyRing
+
+	OIDCProviders map[string]*oidc.Provider
+	SMSSender     notify.SMSSender
+	Mailer        notify.Mailer
+	MagicLinkURL  string
+
+	// PublicURL the API address as seen by clients, ex: https://api.texorbit.com
+	PublicURL string
+	BlobStore storage.BlobStore
+
+	// Locales supported request locales, english first, and LocaleFallbacks the locales tried
+	// before english when a text is missing in a locale
+	Locales         []string
+	LocaleFallbacks map[string][]string
+
+	// TrustedProxies proxies allowed to forward the client address in X-Forwarded-For and X-Real-IP headers
+	TrustedProxies []*net.IPNet
+
+	// CursorSecret key signing the page cursors of list endpoints
+	CursorSecret []byte
+	// OTPSecret key of the one-time and recovery code hashes
+	OTPSecret []byte
 }
 
 func NewSetting() (*Setting, error) {
@@ -22,19 +52,91 @@ func NewSetting() (*Setting, error) {
 		setting.Port = "8080"
 	}
 
+	// locales of responses and translations
+	if setting.Locales, err = getLocales(); err != nil {
+		return nil, err
+	}
+	if setting.LocaleFallbacks, err = getLocaleFallbacks(); err != nil {
+		return nil, err
+	}
+
+	// proxies forwarding the client address, the connection address is used when not set
+	if setting.TrustedProxies, err = getTrustedProxies(); err != nil {
+		return nil, err
+	}
+
 	// getting db url from environment variable
 	if setting.ConnString = os.Getenv("DATABASE_URL"); setting.ConnString == "" {
 		return nil, errors.New(`messing environment variable "DATABASE_URL"`)
 	}
 
 	// getting JWT configurations
-	if setting.AccessAuth, err = getJWTSecret("JWT_ACCESS_SECRET"); err != nil {
+	if setting.AccessAuth, err = getJWTKeys("JWT_ACCESS", "access"); err != nil {
+		return nil, err
+	}
+
+	if setting.RefreshAuth, err = getJWTKeys("JWT_REFRESH", "refresh"); err != nil {
+		return nil, err
+	}
+
+	// getting OpenID Connect login providers
+	if setting.OIDCProviders, err = getOIDCProviders(); err != nil {
+		return nil, err
+	}
+
+	// getting notification senders
+	if setting.SMSSender, err = getSMSSender(); err != nil {
 		return nil, err
 	}
 
-	if setting.RefreshAuth, err = getJWTSecret("JWT_REFRESH_SECRET"); err != nil {
+	if setting.Mailer, err = getMailer(); err != nil {
+		return nil, err
+	}
+
+	// client page that receives the magic link token, ex: https://app.texorbit.com/auth/magic
+	if setting.MagicLinkURL = os.Getenv("MAGIC_LINK_URL"); setting.MagicLinkURL == "" {
+		return nil, errors.New(`messing environment variable "MAGIC_LINK_URL"`)
+	}
+
+	// uploaded files storage, relative URLs are used when the public url is not set
+	setting.PublicURL = strings.TrimSuffix(os.Getenv("PUBLIC_URL"), "/")
+	if setting.BlobStore, err = getBlobStore(setting.PublicURL); err != nil {
+		return nil, err
+	}
+
+	// cursors must verify on every server and after restarts, so the secret is random only in local development
+	if setting.CursorSecret, err = getSecret("CURSOR_SECRET", "page cursors stop working on restart"); err != nil {
+		return nil, err
+	}
+
+	// key of the stored one-time code hashes, codes can not be guessed from a database copy without it
+	if setting.OTPSecret, err = getSecret("OTP_SECRET", "sent codes and recovery codes stop working on restart"); err != nil {
 		return nil, err
 	}
 
 	return setting, nil
 }
+
+// isDevelopment report whether the server runs locally for development, APP_ENV=development
+func isDevelopment() bool {
+	return os.Getenv("APP_ENV") == "development"
+}
+
+// getSecret read secret key from the environment variable, required outside development
+// where a random secret is used and the lost warning logged
+func getSecret(name, lost string) ([]byte, error) {
+	if secret := os.Getenv(name); secret != "" {
+		return []byte(secret), nil
+	}
+
+	if !isDevelopment() {
+		return nil, errors.New(fmt.Sprintf(`messing environment variable "%s"`, name))
+	}
+
+	slog.Warn(fmt.Sprintf(`"%s" is not set, using random secret, %s`, name, lost))
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	return secret, nil
+}
@@ -0,0 +1,59 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"github.com/bigusef/texorbit/pkg/storage"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// getBlobStore select the blob store from BLOB_STORE environment variable ("local" by default, or "s3").
+// local store keep files under BLOB_DIR signing its URLs with BLOB_SECRET, s3 store configured by
+// S3_ENDPOINT, S3_REGION, S3_BUCKET, S3_ACCESS_KEY, S3_SECRET_KEY and S3_PATH_STYLE
+func getBlobStore(publicURL string) (storage.BlobStore, error) {
+	switch store := os.Getenv("BLOB_STORE"); store {
+	case "", "local":
+		// signed URLs must verify on every server, so the secret is random only in local development
+		secret, err := getSecret("BLOB_SECRET", "signed blob URLs stop working on restart")
+		if err != nil {
+			return nil, err
+		}
+
+		local := &storage.LocalStore{
+			Dir:     os.Getenv("BLOB_DIR"),
+			BaseURL: publicURL + "/blobs",
+			Secret:  secret,
+		}
+		if local.Dir == "" {
+			local.Dir = "data/blobs"
+		}
+		return local, nil
+	case "s3":
+		s3 := &storage.S3Store{
+			Endpoint:  os.Getenv("S3_ENDPOINT"),
+			Region:    os.Getenv("S3_REGION"),
+			Bucket:    os.Getenv("S3_BUCKET"),
+			AccessKey: os.Getenv("S3_ACCESS_KEY"),
+			SecretKey: os.Getenv("S3_SECRET_KEY"),
+			PathStyle: strings.EqualFold(os.Getenv("S3_PATH_STYLE"), "true"),
+			Client:    &http.Client{Timeout: 30 * time.Second},
+		}
+
+		for key, value := range map[string]string{
+			"S3_ENDPOINT":   s3.Endpoint,
+			"S3_BUCKET":     s3.Bucket,
+			"S3_ACCESS_KEY": s3.AccessKey,
+			"S3_SECRET_KEY": s3.SecretKey,
+		} {
+			if value == "" {
+				return nil, errors.New(fmt.Sprintf(`messing environment variable "%s"`, key))
+			}
+		}
+		return s3, nil
+	default:
+		return nil, errors.New(fmt.Sprintf(`unsupported BLOB_STORE "%s"`, store))
+	}
+}
@@ -0,0 +1,47 @@
+package config
+
+import (
+	"github.com/bigusef/texorbit/pkg/storage"
+	"testing"
+)
+
+func TestGetBlobStoreSecret(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     string
+		secret  string
+		wantErr bool
+	}{
+		{"configured secret", "production", "blob-secret", false},
+		{"missing secret in production", "production", "", true},
+		{"missing secret without environment", "", "", true},
+		{"random secret in development", "development", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("APP_ENV", tt.env)
+			t.Setenv("BLOB_STORE", "")
+			t.Setenv("BLOB_SECRET", tt.secret)
+
+			store, err := getBlobStore("https://api.texorbit.com")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("getBlobStore() error = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			local, ok := store.(*storage.LocalStore)
+			if !ok {
+				t.Fatalf("getBlobStore() = %T, want local store", store)
+			}
+			if tt.secret == "" && len(local.Secret) != 32 {
+				t.Errorf("secret length = %d, want random 32 bytes", len(local.Secret))
+			}
+			if tt.secret != "" && string(local.Secret) != tt.secret {
+				t.Errorf("secret = %q, want %q", local.Secret, tt.secret)
+			}
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package geo
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+)
+
+// EarthRadius mean radius in meters
+const EarthRadius = 6371008.8
+
+var ErrInvalidGeometry = errors.New("geometry should be GeoJSON Polygon or MultiPolygon with closed rings of [longitude, latitude] positions")
+
+type Point struct {
+	Lat float64
+	Lng float64
+}
+
+// Distance between two points in meters along the earth surface (haversine formula)
+func Distance(a, b Point) float64 {
+	lat1, lat2 := a.Lat*math.Pi/180, b.Lat*math.Pi/180
+	dLat := lat2 - lat1
+	dLng := (b.Lng - a.Lng) * math.Pi / 180
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * EarthRadius * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// Ring closed line of points, the first and last points are the same
+type Ring []Point
+
+// Polygon outer ring followed by its holes
+type Polygon []Ring
+
+// MultiPolygon area made of separate polygons, ex: city with islands
+type MultiPolygon []Polygon
+
+// Contains report whether the point is inside the area, points on the edges are inside
+func (m MultiPolygon) Contains(p Point) bool {
+	for _, polygon := range m {
+		if polygon.Contains(p) {
+			return true
+		}
+	}
+	return false
+}
+
+// Contains report whether the point is inside the outer ring and outside all holes
+func (p Polygon) Contains(point Point) bool {
+	if len(p) == 0 || !p[0].contains(point) {
+		return false
+	}
+
+	for _, hole := range p[1:] {
+		if hole.contains(point) && !hole.onEdge(point) {
+			return false
+		}
+	}
+	return true
+}
+
+// contains use ray casting, areas are small enough to treat coordinates as plane
+func (r Ring) contains(p Point) bool {
+	if r.onEdge(p) {
+		return true
+	}
+
+	inside := false
+	for i, j := 0, len(r)-1; i < len(r); j, i = i, i+1 {
+		a, b := r[i], r[j]
+		if (a.Lat > p.Lat) != (b.Lat > p.Lat) &&
+			p.Lng < (b.Lng-a.Lng)*(p.Lat-a.Lat)/(b.Lat-a.Lat)+a.Lng {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+func (r Ring) onEdge(p Point) bool {
+	const epsilon = 1e-12
+	for i := 1; i < len(r); i++ {
+		a, b := r[i-1], r[i]
+		cross := (b.Lng-a.Lng)*(p.Lat-a.Lat) - (b.Lat-a.Lat)*(p.Lng-a.Lng)
+		if math.Abs(cross) > epsilon {
+			continue
+		}
+		if math.Min(a.Lng, b.Lng)-epsilon <= p.Lng && p.Lng <= math.Max(a.Lng, b.Lng)+epsilon &&
+			math.Min(a.Lat, b.Lat)-epsilon <= p.Lat && p.Lat <= math.Max(a.Lat, b.Lat)+epsilon {
+			return true
+		}
+	}
+	return false
+}
+
+// Center average point of the area outer rings, good enough as city center for small areas
+func (m MultiPolygon) Center() Point {
+	var center Point
+	var n float64
+	for _, polygon := range m {
+		// the closing point repeats the first one
+		for _, point := range polygon[0][1:] {
+			center.Lat += point.Lat
+			center.Lng += point.Lng
+			n++
+		}
+	}
+
+	if n == 0 {
+		return center
+	}
+	return Point{Lat: center.Lat / n, Lng: center.Lng / n}
+}
+
+type geometry struct {
+	Type        string          `json:"type"`
+	Coordinates json.RawMessage `json:"coordinates"`
+}
+
+// ParseGeoJSON read GeoJSON Polygon or MultiPolygon geometry, positions are [longitude, latitude]
+func ParseGeoJSON(data []byte) (MultiPolygon, error) {
+	var g geometry
+	if err := json.Unmarshal(data, &g); err != nil {
+		return nil, ErrInvalidGeometry
+	}
+
+	var raw [][][][2]float64
+	switch g.Type {
+	case "Polygon":
+		var polygon [][][2]float64
+		if err := json.Unmarshal(g.Coordinates, &polygon); err != nil {
+			return nil, ErrInvalidGeometry
+		}
+		raw = [][][][2]float64{polygon}
+	case "MultiPolygon":
+		if err := json.Unmarshal(g.Coordinates, &raw); err != nil {
+			return nil, ErrInvalidGeometry
+		}
+	default:
+		return nil, ErrInvalidGeometry
+	}
+
+	if len(raw) == 0 {
+		return nil, ErrInvalidGeometry
+	}
+
+	area := make(MultiPolygon, len(raw))
+	for i, polygon := range raw {
+		if len(polygon) == 0 {
+			return nil, ErrInvalidGeometry
+		}
+
+		area[i] = make(Polygon, len(polygon))
+		for j, ring := range polygon {
+			// closed ring needs at least 3 distinct points
+			if len(ring) < 4 || ring[0] != ring[len(ring)-1] {
+				return nil, ErrInvalidGeometry
+			}
+
+			area[i][j] = make(Ring, len(ring))
+			for k, position := range ring {
+				lng, lat := position[0], position[1]
+				if lat < -90 || lat > 90 || lng < -180 || lng > 180 {
+					return nil, ErrInvalidGeometry
+				}
+				area[i][j][k] = Point{Lat: lat, Lng: lng}
+			}
+		}
+	}
+
+	return area, nil
+}
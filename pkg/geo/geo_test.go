@@ -0,0 +1,168 @@
+package geo
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestDistance(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b Point
+		want float64 // meters
+	}{
+		{"same point", Point{30.0444, 31.2357}, Point{30.0444, 31.2357}, 0},
+		{"one degree of latitude", Point{0, 0}, Point{1, 0}, 111_195},
+		{"one degree of longitude on equator", Point{0, 0}, Point{0, 1}, 111_195},
+		{"cairo to alexandria", Point{30.0444, 31.2357}, Point{31.2001, 29.9187}, 180_000},
+		{"london to paris", Point{51.5074, -0.1278}, Point{48.8566, 2.3522}, 343_560},
+		{"across the antimeridian", Point{0, 179.9}, Point{0, -179.9}, 22_239},
+		{"pole to pole", Point{90, 0}, Point{-90, 0}, math.Pi * EarthRadius},
+		{"antipodal points", Point{0, 0}, Point{0, 180}, math.Pi * EarthRadius},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Distance(tt.a, tt.b)
+			// within 0.1% or one meter
+			if math.Abs(got-tt.want) > math.Max(1, tt.want*0.001) {
+				t.Errorf("Distance = %.0f m, want %.0f m", got, tt.want)
+			}
+			if reverse := Distance(tt.b, tt.a); math.Abs(reverse-got) > 1e-6 {
+				t.Errorf("Distance is not symmetric: %f and %f", got, reverse)
+			}
+		})
+	}
+}
+
+// square ring of the given corners, closed
+func square(minLng, minLat, maxLng, maxLat float64) Ring {
+	return Ring{{minLat, minLng}, {minLat, maxLng}, {maxLat, maxLng}, {maxLat, minLng}, {minLat, minLng}}
+}
+
+func TestPolygonContains(t *testing.T) {
+	// 10x10 degrees square with 4x4 hole in the middle
+	withHole := Polygon{square(0, 0, 10, 10), square(3, 3, 7, 7)}
+	diamond := Polygon{{{0, 5}, {5, 10}, {10, 5}, {5, 0}, {0, 5}}}
+
+	tests := []struct {
+		name    string
+		polygon Polygon
+		point   Point
+		want    bool
+	}{
+		{"inside", withHole, Point{1, 1}, true},
+		{"outside", withHole, Point{11, 5}, false},
+		{"outside on edge line extension", withHole, Point{0, 12}, false},
+		{"on outer edge", withHole, Point{0, 5}, true},
+		{"on outer vertex", withHole, Point{10, 10}, true},
+		{"on outer vertex ray level", withHole, Point{10, 0}, true},
+		{"inside hole", withHole, Point{5, 5}, false},
+		{"on hole edge", withHole, Point{3, 5}, true},
+		{"on hole vertex", withHole, Point{7, 7}, true},
+		{"between hole and outer ring", withHole, Point{8, 5}, true},
+		{"ray through vertex inside", diamond, Point{5, 5}, true},
+		{"ray through vertices outside", diamond, Point{5, -1}, false},
+		{"on slanted edge", diamond, Point{2.5, 7.5}, true},
+		{"near slanted edge outside", diamond, Point{2.5, 7.501}, false},
+		{"near slanted edge inside", diamond, Point{2.5, 7.499}, true},
+		{"empty polygon", Polygon{}, Point{0, 0}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.polygon.Contains(tt.point); got != tt.want {
+				t.Errorf("Contains(%v) = %v, want %v", tt.point, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMultiPolygonContains(t *testing.T) {
+	// area crossing the antimeridian split in two polygons as GeoJSON requires (RFC 7946 section 3.1.9)
+	fiji := MultiPolygon{
+		{square(177, -19, 180, -16)},
+		{square(-180, -19, -178, -16)},
+	}
+
+	tests := []struct {
+		name  string
+		point Point
+		want  bool
+	}{
+		{"east of antimeridian", Point{-17.5, 178.5}, true},
+		{"west of antimeridian", Point{-17.5, -179}, true},
+		{"on antimeridian positive", Point{-17.5, 180}, true},
+		{"on antimeridian negative", Point{-17.5, -180}, true},
+		{"prime meridian", Point{-17.5, 0}, false},
+		{"beyond west part", Point{-17.5, -177}, false},
+		{"beyond east part", Point{-17.5, 176}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fiji.Contains(tt.point); got != tt.want {
+				t.Errorf("Contains(%v) = %v, want %v", tt.point, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCenter(t *testing.T) {
+	area := MultiPolygon{{square(0, 0, 2, 4)}}
+	if got := area.Center(); got != (Point{Lat: 2, Lng: 1}) {
+		t.Errorf("Center = %v, want {2 1}", got)
+	}
+	if got := (MultiPolygon{}).Center(); got != (Point{}) {
+		t.Errorf("Center of empty area = %v, want zero point", got)
+	}
+}
+
+func TestParseGeoJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		polygons int
+		err      error
+	}{
+		{"polygon", `{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,1],[0,0]]]}`, 1, nil},
+		{"polygon with hole", `{"type":"Polygon","coordinates":[[[0,0],[10,0],[10,10],[0,10],[0,0]],[[3,3],[7,3],[7,7],[3,3]]]}`, 1, nil},
+		{"multi polygon", `{"type":"MultiPolygon","coordinates":[[[[0,0],[1,0],[1,1],[0,0]]],[[[5,5],[6,5],[6,6],[5,5]]]]}`, 2, nil},
+		{"antimeridian coordinates", `{"type":"Polygon","coordinates":[[[177,-19],[180,-19],[180,-16],[177,-19]]]}`, 1, nil},
+		{"open ring", `{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,1],[0,1]]]}`, 0, ErrInvalidGeometry},
+		{"too few points", `{"type":"Polygon","coordinates":[[[0,0],[1,0],[0,0]]]}`, 0, ErrInvalidGeometry},
+		{"longitude out of range", `{"type":"Polygon","coordinates":[[[0,0],[181,0],[1,1],[0,0]]]}`, 0, ErrInvalidGeometry},
+		{"latitude out of range", `{"type":"Polygon","coordinates":[[[0,0],[1,91],[1,1],[0,0]]]}`, 0, ErrInvalidGeometry},
+		{"point", `{"type":"Point","coordinates":[0,0]}`, 0, ErrInvalidGeometry},
+		{"empty polygon", `{"type":"Polygon","coordinates":[]}`, 0, ErrInvalidGeometry},
+		{"empty multi polygon", `{"type":"MultiPolygon","coordinates":[]}`, 0, ErrInvalidGeometry},
+		{"not json", `polygon`, 0, ErrInvalidGeometry},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			area, err := ParseGeoJSON([]byte(tt.data))
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("ParseGeoJSON error = %v, want %v", err, tt.err)
+			}
+			if len(area) != tt.polygons {
+				t.Errorf("polygons = %d, want %d", len(area), tt.polygons)
+			}
+		})
+	}
+}
+
+func TestParseGeoJSONPositionOrder(t *testing.T) {
+	// GeoJSON positions are [longitude, latitude]
+	area, err := ParseGeoJSON([]byte(`{"type":"Polygon","coordinates":[[[31,30],[32,30],[32,31],[31,30]]]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := area[0][0][1]; got != (Point{Lat: 30, Lng: 32}) {
+		t.Errorf("second point = %v, want {Lat: 30 Lng: 32}", got)
+	}
+	if !area.Contains(Point{Lat: 30.2, Lng: 31.5}) || area.Contains(Point{Lat: 31.5, Lng: 30.2}) {
+		t.Errorf("area uses swapped coordinates")
+	}
+}
@@ -0,0 +1,77 @@
+package i18n
+
+import "fmt"
+
+// catalogue messages of every supported locale, texts are fmt formats
+var catalogue = map[string]map[string]string{
+	"sms.phone_otp": {
+		English: "Your TexOrbit verification code is %s",
+		Arabic:  "رمز التحقق الخاص بك في TexOrbit هو %s",
+	},
+	"city.no_governorate": {
+		English: "Other cities",
+		Arabic:  "مدن أخرى",
+	},
+	"error.bad_request": {
+		English: "invalid request",
+		Arabic:  "طلب غير صالح",
+	},
+	"error.validation_failed": {
+		English: "request validation failed",
+		Arabic:  "بعض البيانات المرسلة غير صحيحة",
+	},
+	"error.unauthorized": {
+		English: "authentication required",
+		Arabic:  "يجب تسجيل الدخول أولاً",
+	},
+	"error.forbidden": {
+		English: "access denied",
+		Arabic:  "غير مسموح لك بهذا الإجراء",
+	},
+	"error.not_found": {
+		English: "resource not found",
+		Arabic:  "العنصر المطلوب غير موجود",
+	},
+	"error.already_exists": {
+		English: "resource already exists",
+		Arabic:  "العنصر موجود بالفعل",
+	},
+	"error.referenced": {
+		English: "resource is used by other resources",
+		Arabic:  "العنصر مستخدم في عناصر أخرى",
+	},
+	"error.too_many_requests": {
+		English: "too many requests, try again later",
+		Arabic:  "طلبات كثيرة، حاول مرة أخرى لاحقاً",
+	},
+	"error.internal": {
+		English: "internal server error",
+		Arabic:  "حدث خطأ غير متوقع، حاول مرة أخرى",
+	},
+}
+
+// T return the catalogue message of the key in the locale formatted with the args,
+// falling back through the locale Chain and then to the key itself
+func T(locale, key string, args ...interface{}) string {
+	messages, ok := catalogue[key]
+	if !ok {
+		return key
+	}
+
+	var text string
+	for _, candidate := range Chain(locale) {
+		if text, ok = messages[candidate]; ok {
+			break
+		}
+	}
+	if len(args) == 0 {
+		return text
+	}
+	return fmt.Sprintf(text, args...)
+}
+
+// Has report whether the catalogue has message for the key
+func Has(key string) bool {
+	_, ok := catalogue[key]
+	return ok
+}
@@ -0,0 +1,140 @@
+package i18n
+
+import (
+	"context"
+	"golang.org/x/text/language"
+	"net/http"
+)
+
+// built-in locales, english is the default and ends every fallback chain
+const (
+	English = "en"
+	Arabic  = "ar"
+)
+
+// Supported locales requests can select, set with Configure
+var Supported = []string{English, Arabic}
+
+var matcher = language.NewMatcher([]language.Tag{language.English, language.Arabic})
+
+// Fallbacks locales tried before english by Chain when a text is missing in the locale (ex: "fr-CA": {"fr"}),
+// set with Configure
+var Fallbacks = map[string][]string{}
+
+// Configure set the supported locales (canonical BCP 47 tags, english first) and their fallbacks,
+// it is called once at startup before serving requests
+func Configure(supported []string, fallbacks map[string][]string) {
+	tags := make([]language.Tag, len(supported))
+	for i, locale := range supported {
+		tags[i] = language.Make(locale)
+	}
+
+	Supported = supported
+	Fallbacks = fallbacks
+	matcher = language.NewMatcher(tags)
+}
+
+type contextKey struct{}
+
+// IsSupported report whether the locale is one of the supported locales
+func IsSupported(locale string) bool {
+	for _, supported := range Supported {
+		if locale == supported {
+			return true
+		}
+	}
+	return false
+}
+
+// Negotiate select the supported locale best matching Accept-Language header with its q-values
+// (ex: "ar-EG,ar;q=0.9,en;q=0.8" is arabic), english when nothing matches
+func Negotiate(acceptLanguage string) string {
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return English
+	}
+
+	_, index, confidence := matcher.Match(tags...)
+	if confidence == language.No {
+		return English
+	}
+	return Supported[index]
+}
+
+// Canonical return the canonical form of BCP 47 language tag (ex: "EN-us" is "en-US")
+func Canonical(locale string) (string, bool) {
+	tag, err := language.Parse(locale)
+	if err != nil {
+		return "", false
+	}
+	return tag.String(), true
+}
+
+// Chain return the locales to try in order for a text in the locale: the locale, its fallbacks,
+// its base language (ex: "ar" of "ar-EG") and english
+func Chain(locale string) []string {
+	chain := []string{locale}
+	add := func(locale string) {
+		for _, added := range chain {
+			if added == locale {
+				return
+			}
+		}
+		chain = append(chain, locale)
+	}
+
+	for _, fallback := range Fallbacks[locale] {
+		add(fallback)
+	}
+	if tag, err := language.Parse(locale); err == nil {
+		if base, confidence := tag.Base(); confidence != language.No {
+			add(base.String())
+			for _, fallback := range Fallbacks[base.String()] {
+				add(fallback)
+			}
+		}
+	}
+	add(English)
+
+	return chain
+}
+
+// WithLocale return copy of the context carrying the locale
+func WithLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, contextKey{}, locale)
+}
+
+// FromContext return the request locale resolved by Middleware, english by default
+func FromContext(ctx context.Context) string {
+	if locale, ok := ctx.Value(contextKey{}).(string); ok {
+		return locale
+	}
+	return English
+}
+
+// Parameter return the supported locale of "lang" query parameter value in any case (ex: "fr-ca" is "fr-CA"),
+// the bool result is false when it is not supported
+func Parameter(value string) (string, bool) {
+	locale, ok := Canonical(value)
+	if !ok || !IsSupported(locale) {
+		return "", false
+	}
+	return locale, true
+}
+
+// Middleware resolve the request locale from "lang" query parameter or Accept-Language header
+// and put it on the context, the response Content-Language tells the selected locale
+func Middleware(next http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		locale, ok := Parameter(r.URL.Query().Get("lang"))
+		if !ok {
+			locale = Negotiate(r.Header.Get("Accept-Language"))
+		}
+
+		w.Header().Set("Content-Language", locale)
+		w.Header().Add("Vary", "Accept-Language")
+		next.ServeHTTP(w, r.WithContext(WithLocale(r.Context(), locale)))
+	}
+
+	return http.HandlerFunc(fn)
+}
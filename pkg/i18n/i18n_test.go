@@ -0,0 +1,130 @@
+package i18n
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+// configure set the locales for the test, restoring the built-in ones after it
+func configure(t *testing.T, supported []string, fallbacks map[string][]string) {
+	t.Helper()
+	Configure(supported, fallbacks)
+	t.Cleanup(func() { Configure([]string{English, Arabic}, map[string][]string{}) })
+}
+
+func TestNegotiate(t *testing.T) {
+	configure(t, []string{English, Arabic, "fr", "fr-CA"}, nil)
+
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"", English},
+		{"ar-EG,ar;q=0.9,en;q=0.8", Arabic},
+		{"en;q=0.5,ar;q=0.4", English},
+		{"en;q=0.1,ar;q=0.9", Arabic},
+		{"fr;q=0.2, ar ; q=0.7, en;q=0.5", Arabic},
+		{"ar;q=0,fr", "fr"},
+		{"*", English},
+		{"fr-CA,fr;q=0.9", "fr-CA"},
+		{"fr-BE", "fr"},
+		{"de,fr;q=0.5", "fr"},
+		{"de-DE", English},
+		{"not a header;;;", English},
+	}
+
+	for _, tt := range tests {
+		if got := Negotiate(tt.header); got != tt.want {
+			t.Errorf("Negotiate(%q) = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestNegotiateBuiltInLocales(t *testing.T) {
+	if got := Negotiate("fr-CA,fr;q=0.9"); got != English {
+		t.Errorf("Negotiate of unsupported locale = %q, want english", got)
+	}
+	if got := Negotiate("ar-EG"); got != Arabic {
+		t.Errorf("Negotiate(ar-EG) = %q, want arabic", got)
+	}
+}
+
+func TestChain(t *testing.T) {
+	configure(t, []string{English, "fr", "fr-CA"}, map[string][]string{
+		"fr-CA": {"fr-FR"},
+		"pt-BR": {"pt-PT", "pt"},
+		"fr":    {"ar"},
+	})
+
+	tests := []struct {
+		locale string
+		want   []string
+	}{
+		{English, []string{English}},
+		{Arabic, []string{Arabic, English}},
+		{"ar-EG", []string{"ar-EG", Arabic, English}},
+		{"fr-CA", []string{"fr-CA", "fr-FR", "fr", Arabic, English}},
+		{"pt-BR", []string{"pt-BR", "pt-PT", "pt", English}},
+	}
+
+	for _, tt := range tests {
+		if got := Chain(tt.locale); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Chain(%q) = %v, want %v", tt.locale, got, tt.want)
+		}
+	}
+}
+
+func TestT(t *testing.T) {
+	configure(t, []string{English, Arabic, "fr-CA"}, map[string][]string{"fr-CA": {Arabic}})
+
+	if got := T(Arabic, "error.not_found"); got != "العنصر المطلوب غير موجود" {
+		t.Errorf("T(ar) = %q", got)
+	}
+	if got := T("fr-CA", "error.not_found"); got != "العنصر المطلوب غير موجود" {
+		t.Errorf("T(fr-CA) = %q, want the arabic fallback", got)
+	}
+	if got := T("de", "sms.phone_otp", "123456"); got != "Your TexOrbit verification code is 123456" {
+		t.Errorf("T(de) = %q, want english", got)
+	}
+	if got := T(English, "unknown.key"); got != "unknown.key" {
+		t.Errorf("T of unknown key = %q, want the key", got)
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	configure(t, []string{English, Arabic, "fr-CA"}, nil)
+
+	tests := []struct {
+		name   string
+		lang   string
+		header string
+		want   string
+	}{
+		{"query parameter", "ar", "en", Arabic},
+		{"query parameter any case", "FR-ca", "", "fr-CA"},
+		{"unsupported query parameter", "de", "ar", Arabic},
+		{"invalid query parameter", "!!", "", English},
+		{"accept language", "", "fr-CA", "fr-CA"},
+		{"default", "", "", English},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = FromContext(r.Context())
+			}))
+
+			r := httptest.NewRequest(http.MethodGet, "/?lang="+tt.lang, nil)
+			r.Header.Set("Accept-Language", tt.header)
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			if got != tt.want || w.Header().Get("Content-Language") != tt.want {
+				t.Errorf("locale = %q (Content-Language %q), want %q", got, w.Header().Get("Content-Language"), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+	"net/http"
+)
+
+var (
+	ErrUnsupportedFormat = errors.New("unsupported image format, use jpeg, png or gif")
+	ErrTooLarge          = errors.New("image dimensions are too large")
+)
+
+// MaxPixels limit decoded images size, protecting the server from decompression bombs
+const MaxPixels = 25_000_000
+
+// JPEGQuality of the generated thumbnails
+const JPEGQuality = 85
+
+var supportedTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+}
+
+// Decode sniff the content type from the data itself (ignoring client supplied type), check the image dimensions
+// before decoding it, and return it upright according to its EXIF orientation
+func Decode(data []byte) (image.Image, error) {
+	if !supportedTypes[http.DetectContentType(data)] {
+		return nil, ErrUnsupportedFormat
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedFormat
+	}
+	if config.Width <= 0 || config.Height <= 0 || config.Width*config.Height > MaxPixels {
+		return nil, ErrTooLarge
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedFormat
+	}
+
+	return orient(toRGBA(img), jpegOrientation(data)), nil
+}
+
+// Thumbnail crop the image center square and scale it to size x size pixels
+func Thumbnail(img image.Image, size int) *image.RGBA {
+	// toRGBA moves the image origin to (0, 0), so the crop is computed on its bounds
+	src := toRGBA(img)
+	bounds := src.Bounds()
+	side := min(bounds.Dx(), bounds.Dy())
+	x := (bounds.Dx() - side) / 2
+	y := (bounds.Dy() - side) / 2
+
+	return resize(src, image.Rect(x, y, x+side, y+side), size, size)
+}
+
+// EncodeJPEG encode the image as JPEG, the output contains pixels only without any metadata of the source (EXIF, GPS, ...)
+func EncodeJPEG(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: JPEGQuality}); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// toRGBA copy the image to RGBA flattened on white background, as JPEG has no transparency
+func toRGBA(img image.Image) *image.RGBA {
+	if rgba, ok := img.(*image.RGBA); ok && rgba.Bounds().Min == (image.Point{}) {
+		return rgba
+	}
+
+	bounds := img.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(dst, dst.Bounds(), img, bounds.Min, draw.Over)
+
+	return dst
+}
+
+// resize scale the src rectangle to width x height using area averaging when shrinking
+// and nearest pixel when enlarging
+func resize(src *image.RGBA, rect image.Rectangle, width, height int) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	scaleX := float64(rect.Dx()) / float64(width)
+	scaleY := float64(rect.Dy()) / float64(height)
+
+	for dy := 0; dy < height; dy++ {
+		y0 := rect.Min.Y + int(float64(dy)*scaleY)
+		y1 := max(rect.Min.Y+int(float64(dy+1)*scaleY), y0+1)
+
+		for dx := 0; dx < width; dx++ {
+			x0 := rect.Min.X + int(float64(dx)*scaleX)
+			x1 := max(rect.Min.X+int(float64(dx+1)*scaleX), x0+1)
+
+			var r, g, b, a, n uint32
+			for y := y0; y < y1; y++ {
+				offset := src.PixOffset(x0, y)
+				for x := x0; x < x1; x++ {
+					r += uint32(src.Pix[offset])
+					g += uint32(src.Pix[offset+1])
+					b += uint32(src.Pix[offset+2])
+					a += uint32(src.Pix[offset+3])
+					offset += 4
+					n++
+				}
+			}
+
+			offset := dst.PixOffset(dx, dy)
+			dst.Pix[offset] = uint8(r / n)
+			dst.Pix[offset+1] = uint8(g / n)
+			dst.Pix[offset+2] = uint8(b / n)
+			dst.Pix[offset+3] = uint8(a / n)
+		}
+	}
+
+	return dst
+}
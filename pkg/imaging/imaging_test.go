@@ -0,0 +1,264 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+// filled return a width x height image of a single color
+func filled(width, height int, c color.RGBA) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for i := 0; i < len(img.Pix); i += 4 {
+		img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = c.R, c.G, c.B, c.A
+	}
+	return img
+}
+
+// withOrientation insert an EXIF segment holding the orientation tag after the JPEG start marker
+func withOrientation(data []byte, orientation uint16, order binary.ByteOrder) []byte {
+	tiff := make([]byte, 8+2+12+4)
+	if order == binary.LittleEndian {
+		copy(tiff, "II")
+	} else {
+		copy(tiff, "MM")
+	}
+	order.PutUint16(tiff[2:], 42)
+	order.PutUint32(tiff[4:], 8)
+	order.PutUint16(tiff[8:], 1)
+	order.PutUint16(tiff[10:], 0x0112)
+	order.PutUint16(tiff[12:], 3)
+	order.PutUint32(tiff[14:], 1)
+	order.PutUint16(tiff[18:], orientation)
+
+	segment := append([]byte("Exif\x00\x00"), tiff...)
+	app1 := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(app1[2:], uint16(len(segment)+2))
+
+	out := append([]byte{}, data[:2]...)
+	out = append(out, app1...)
+	out = append(out, segment...)
+	return append(out, data[2:]...)
+}
+
+func encodeJPEG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestThumbnailSize(t *testing.T) {
+	tests := []struct {
+		name          string
+		width, height int
+		size          int
+	}{
+		{"shrink landscape", 400, 300, 128},
+		{"shrink portrait", 300, 400, 128},
+		{"shrink square", 512, 512, 64},
+		{"enlarge", 20, 10, 128},
+		{"not a multiple", 333, 777, 100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			thumb := Thumbnail(filled(tt.width, tt.height, color.RGBA{10, 20, 30, 255}), tt.size)
+			if got := thumb.Bounds(); got != image.Rect(0, 0, tt.size, tt.size) {
+				t.Fatalf("bounds = %v, want %dx%d", got, tt.size, tt.size)
+			}
+			if got := thumb.RGBAAt(tt.size-1, tt.size-1); got != (color.RGBA{10, 20, 30, 255}) {
+				t.Errorf("corner pixel = %v, want the source color", got)
+			}
+		})
+	}
+}
+
+func TestThumbnailCropsCenter(t *testing.T) {
+	// red borders left and right of a 100x100 green center, the square crop keeps the green only
+	img := filled(300, 100, color.RGBA{255, 0, 0, 255})
+	for y := 0; y < 100; y++ {
+		for x := 100; x < 200; x++ {
+			img.SetRGBA(x, y, color.RGBA{0, 255, 0, 255})
+		}
+	}
+
+	thumb := Thumbnail(img, 10)
+	for y := 0; y < 10; y++ {
+		for x := 0; x < 10; x++ {
+			if got := thumb.RGBAAt(x, y); got != (color.RGBA{0, 255, 0, 255}) {
+				t.Fatalf("pixel (%d, %d) = %v, want green", x, y, got)
+			}
+		}
+	}
+}
+
+func TestThumbnailAveragesPixels(t *testing.T) {
+	// black and white columns shrunk to one pixel average to gray
+	img := filled(2, 2, color.RGBA{0, 0, 0, 255})
+	img.SetRGBA(1, 0, color.RGBA{255, 255, 255, 255})
+	img.SetRGBA(1, 1, color.RGBA{255, 255, 255, 255})
+
+	if got := Thumbnail(img, 1).RGBAAt(0, 0); got != (color.RGBA{127, 127, 127, 255}) {
+		t.Errorf("pixel = %v, want gray", got)
+	}
+}
+
+func TestThumbnailNonZeroBounds(t *testing.T) {
+	img := filled(40, 40, color.RGBA{0, 0, 255, 255}).SubImage(image.Rect(10, 10, 30, 20))
+
+	thumb := Thumbnail(img, 5)
+	if thumb.Bounds() != image.Rect(0, 0, 5, 5) || thumb.RGBAAt(4, 4) != (color.RGBA{0, 0, 255, 255}) {
+		t.Errorf("thumbnail of sub image = %v %v", thumb.Bounds(), thumb.RGBAAt(4, 4))
+	}
+}
+
+func TestOrient(t *testing.T) {
+	// 3x2 source with distinct pixels, a b c on the first row and d e f on the second
+	src := image.NewRGBA(image.Rect(0, 0, 3, 2))
+	for i := 0; i < 6; i++ {
+		src.SetRGBA(i%3, i/3, color.RGBA{uint8('a' + i), 0, 0, 255})
+	}
+
+	tests := []struct {
+		orientation int
+		want        []string
+	}{
+		{1, []string{"abc", "def"}},
+		{2, []string{"cba", "fed"}},
+		{3, []string{"fed", "cba"}},
+		{4, []string{"def", "abc"}},
+		{5, []string{"ad", "be", "cf"}},
+		{6, []string{"da", "eb", "fc"}},
+		{7, []string{"fc", "eb", "da"}},
+		{8, []string{"cf", "be", "ad"}},
+		{9, []string{"abc", "def"}},
+	}
+
+	for _, tt := range tests {
+		dst := orient(src, tt.orientation)
+
+		var got []string
+		for y := 0; y < dst.Bounds().Dy(); y++ {
+			var row []byte
+			for x := 0; x < dst.Bounds().Dx(); x++ {
+				row = append(row, dst.RGBAAt(x, y).R)
+			}
+			got = append(got, string(row))
+		}
+
+		if len(got) != len(tt.want) {
+			t.Errorf("orient(%d) = %v, want %v", tt.orientation, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("orient(%d) = %v, want %v", tt.orientation, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestJPEGOrientation(t *testing.T) {
+	data := encodeJPEG(t, filled(4, 4, color.RGBA{255, 255, 255, 255}))
+
+	tests := []struct {
+		name string
+		data []byte
+		want int
+	}{
+		{"no exif", data, 1},
+		{"little endian", withOrientation(data, 6, binary.LittleEndian), 6},
+		{"big endian", withOrientation(data, 8, binary.BigEndian), 8},
+		{"invalid value", withOrientation(data, 12, binary.BigEndian), 1},
+		{"not jpeg", []byte("GIF89a"), 1},
+		{"truncated", withOrientation(data, 6, binary.LittleEndian)[:20], 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := jpegOrientation(tt.data); got != tt.want {
+				t.Errorf("jpegOrientation = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecode(t *testing.T) {
+	var pngData, gifData bytes.Buffer
+	_ = png.Encode(&pngData, filled(6, 4, color.RGBA{0, 0, 0, 0}))
+	_ = gif.Encode(&gifData, filled(6, 4, color.RGBA{0, 0, 0, 255}), nil)
+
+	// the logical screen of the GIF claims 10000x10000 pixels, rejected before decoding
+	bomb := append([]byte{}, gifData.Bytes()...)
+	binary.LittleEndian.PutUint16(bomb[6:], 10000)
+	binary.LittleEndian.PutUint16(bomb[8:], 10000)
+
+	jpegData := encodeJPEG(t, filled(6, 4, color.RGBA{255, 0, 0, 255}))
+
+	tests := []struct {
+		name          string
+		data          []byte
+		width, height int
+		err           error
+	}{
+		{"jpeg", jpegData, 6, 4, nil},
+		{"rotated jpeg", withOrientation(jpegData, 6, binary.BigEndian), 4, 6, nil},
+		{"png", pngData.Bytes(), 6, 4, nil},
+		{"gif", gifData.Bytes(), 6, 4, nil},
+		{"too large", bomb, 0, 0, ErrTooLarge},
+		{"text", []byte("<svg xmlns='http://www.w3.org/2000/svg'/>"), 0, 0, ErrUnsupportedFormat},
+		{"truncated png", pngData.Bytes()[:40], 0, 0, ErrUnsupportedFormat},
+		{"empty", nil, 0, 0, ErrUnsupportedFormat},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img, err := Decode(tt.data)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Decode error = %v, want %v", err, tt.err)
+			}
+			if err == nil && img.Bounds() != image.Rect(0, 0, tt.width, tt.height) {
+				t.Errorf("bounds = %v, want %dx%d", img.Bounds(), tt.width, tt.height)
+			}
+		})
+	}
+}
+
+func TestDecodeFlattensTransparency(t *testing.T) {
+	var data bytes.Buffer
+	_ = png.Encode(&data, filled(2, 2, color.RGBA{0, 0, 0, 0}))
+
+	img, err := Decode(data.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := color.RGBAModel.Convert(img.At(0, 0)); got != (color.RGBA{255, 255, 255, 255}) {
+		t.Errorf("transparent pixel = %v, want white", got)
+	}
+}
+
+func TestEncodeJPEGStripsMetadata(t *testing.T) {
+	src := withOrientation(encodeJPEG(t, filled(4, 4, color.RGBA{255, 255, 255, 255})), 6, binary.BigEndian)
+	img, err := Decode(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := EncodeJPEG(img)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(out, []byte("Exif")) {
+		t.Errorf("encoded JPEG still contains EXIF metadata")
+	}
+}
@@ -0,0 +1,199 @@
+package jwtkeys
+
+import (
+	"errors"
+	"fmt"
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jws"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// KeyRing sign tokens with the current key and verify tokens signed by any of the loaded keys,
+// the key used is selected by the token "kid" header so keys can be rotated without logging users out
+type KeyRing struct {
+	signer     *jwtauth.JWTAuth
+	verifiers  map[string]*jwtauth.JWTAuth
+	publicKeys jwk.Set
+	// tokenType "typ" claim of the ring tokens, keep tokens of one ring from being accepted by another ring
+	tokenType string
+}
+
+// NewSecret create key ring with single HS256 shared secret, kept for development setups without key files
+func NewSecret(secret []byte) *KeyRing {
+	ja := jwtauth.New("HS256", secret, nil)
+
+	return &KeyRing{
+		signer:     ja,
+		verifiers:  map[string]*jwtauth.JWTAuth{"": ja},
+		publicKeys: jwk.NewSet(),
+	}
+}
+
+// Load read PEM keys from the given files and directories (*.pem files), key id is the file name without extension.
+// current kid select the signing key, when empty the first loaded private key is used, directory files are loaded
+// in reverse name order so naming keys by date make the newest key current.
+// public keys only used to verify tokens signed before rotation.
+func Load(paths []string, currentKid string) (*KeyRing, error) {
+	ring := &KeyRing{
+		verifiers:  map[string]*jwtauth.JWTAuth{},
+		publicKeys: jwk.NewSet(),
+	}
+
+	files, err := keyFiles(paths)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, file := range files {
+		kid := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+		if _, exist := ring.verifiers[kid]; exist {
+			return nil, fmt.Errorf("duplicated jwt key id %q", kid)
+		}
+
+		private, public, alg, err := readKey(file, kid)
+		if err != nil {
+			return nil, err
+		}
+
+		ja := jwtauth.New(alg.String(), private, public)
+		ring.verifiers[kid] = ja
+		if err := ring.publicKeys.AddKey(public); err != nil {
+			return nil, err
+		}
+
+		if private != nil && ring.signer == nil && (currentKid == "" || currentKid == kid) {
+			ring.signer = ja
+		}
+	}
+
+	if ring.signer == nil {
+		if currentKid != "" {
+			return nil, fmt.Errorf("jwt signing key %q not found", currentKid)
+		}
+		return nil, errors.New("no jwt private key found")
+	}
+
+	return ring, nil
+}
+
+// SetType set the "typ" claim added to every token signed by the ring and required on every verified token,
+// so refresh tokens are rejected as access tokens even when both rings load the same keys
+func (k *KeyRing) SetType(tokenType string) {
+	k.tokenType = tokenType
+}
+
+// Encode sign the claims with the current key
+func (k *KeyRing) Encode(claims map[string]interface{}) (jwt.Token, string, error) {
+	if k.tokenType != "" {
+		typed := make(map[string]interface{}, len(claims)+1)
+		for name, value := range claims {
+			typed[name] = value
+		}
+		typed["typ"] = k.tokenType
+		claims = typed
+	}
+
+	return k.signer.Encode(claims)
+}
+
+// Verify decode the token with the key matching its "kid" header and validate its claims
+func (k *KeyRing) Verify(tokenString string) (jwt.Token, error) {
+	msg, err := jws.ParseString(tokenString)
+	if err != nil || len(msg.Signatures()) != 1 {
+		return nil, jwtauth.ErrUnauthorized
+	}
+
+	ja, ok := k.verifiers[msg.Signatures()[0].ProtectedHeaders().KeyID()]
+	if !ok {
+		return nil, jwtauth.ErrUnauthorized
+	}
+
+	token, err := jwtauth.VerifyToken(ja, tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	if k.tokenType != "" {
+		if typ, _ := token.Get("typ"); typ != k.tokenType {
+			return nil, jwtauth.ErrUnauthorized
+		}
+	}
+
+	return token, nil
+}
+
+// PublicKeys return the public keys of the ring to be published as JWKS
+func (k *KeyRing) PublicKeys() jwk.Set {
+	return k.publicKeys
+}
+
+func keyFiles(paths []string) ([]string, error) {
+	var files []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+
+		matches, err := filepath.Glob(filepath.Join(path, "*.pem"))
+		if err != nil {
+			return nil, err
+		}
+		sort.Sort(sort.Reverse(sort.StringSlice(matches)))
+		files = append(files, matches...)
+	}
+
+	return files, nil
+}
+
+func readKey(file, kid string) (private jwk.Key, public jwk.Key, alg jwa.SignatureAlgorithm, err error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	key, err := jwk.ParseKey(data, jwk.WithPEM(true))
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("jwt key %s: %w", file, err)
+	}
+
+	switch key.KeyType() {
+	case jwa.RSA:
+		alg = jwa.RS256
+	case jwa.OKP:
+		alg = jwa.EdDSA
+	default:
+		return nil, nil, "", fmt.Errorf("jwt key %s: unsupported key type %s", file, key.KeyType())
+	}
+
+	if err = key.Set(jwk.KeyIDKey, kid); err != nil {
+		return nil, nil, "", err
+	}
+	if err = key.Set(jwk.AlgorithmKey, alg); err != nil {
+		return nil, nil, "", err
+	}
+
+	if public, err = key.PublicKey(); err != nil {
+		return nil, nil, "", err
+	}
+	if err = public.Set(jwk.KeyUsageKey, jwk.ForSignature); err != nil {
+		return nil, nil, "", err
+	}
+
+	if isPrivate, _ := jwk.IsPrivateKey(key); isPrivate {
+		private = key
+	}
+
+	return private, public, alg, nil
+}
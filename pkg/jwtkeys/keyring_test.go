@@ -0,0 +1,325 @@
+package jwtkeys
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"github.com/go-chi/jwtauth/v5"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeKey write PEM encoded key of the kind ("ed25519", "rsa" or "public" ed25519) as <kid>.pem in dir,
+// it returns the ed25519 private key so its public part can be written after rotation
+func writeKey(t *testing.T, dir, kid, kind string, private ed25519.PrivateKey) ed25519.PrivateKey {
+	t.Helper()
+
+	var block *pem.Block
+	switch kind {
+	case "rsa":
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			t.Fatal(err)
+		}
+		block = &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}
+	case "public":
+		der, err := x509.MarshalPKIXPublicKey(private.Public())
+		if err != nil {
+			t.Fatal(err)
+		}
+		block = &pem.Block{Type: "PUBLIC KEY", Bytes: der}
+	default:
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		der, err := x509.MarshalPKCS8PrivateKey(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		block, private = &pem.Block{Type: "PRIVATE KEY", Bytes: der}, key
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, kid+".pem"), pem.EncodeToMemory(block), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return private
+}
+
+func sign(t *testing.T, ring *KeyRing, claims map[string]interface{}) string {
+	t.Helper()
+	_, token, err := ring.Encode(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestKeyRingRotation(t *testing.T) {
+	dir := t.TempDir()
+	oldKey := writeKey(t, dir, "2024-01", "ed25519", nil)
+
+	before, err := Load([]string{dir}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldToken := sign(t, before, map[string]interface{}{"sub": "user-1"})
+
+	// rotation: new key is added, named so it sorts last and becomes current
+	writeKey(t, dir, "2024-07", "ed25519", nil)
+	rotated, err := Load([]string{dir}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	newToken := sign(t, rotated, map[string]interface{}{"sub": "user-1"})
+
+	if token, err := rotated.Verify(oldToken); err != nil || token.Subject() != "user-1" {
+		t.Errorf("token signed before rotation: %v", err)
+	}
+	if token, err := rotated.Verify(newToken); err != nil || token.Subject() != "user-1" {
+		t.Errorf("token signed after rotation: %v", err)
+	}
+	if _, err := before.Verify(newToken); err == nil {
+		t.Errorf("servers without the new key accepted its token")
+	}
+	if n := rotated.PublicKeys().Len(); n != 2 {
+		t.Errorf("published %d public keys, want 2", n)
+	}
+
+	// retiring the old private key: its public key keeps verifying the old tokens until they expire
+	writeKey(t, dir, "2024-01", "public", oldKey)
+	retired, err := Load([]string{dir}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := retired.Verify(oldToken); err != nil {
+		t.Errorf("token of retired key: %v", err)
+	}
+	if token := sign(t, retired, map[string]interface{}{}); !verifiedBy(t, rotated, token) {
+		t.Errorf("retired ring does not sign with the new key")
+	}
+
+	// removing the old key invalidates its tokens
+	if err := os.Remove(filepath.Join(dir, "2024-01.pem")); err != nil {
+		t.Fatal(err)
+	}
+	removed, err := Load([]string{dir}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := removed.Verify(oldToken); err == nil {
+		t.Errorf("token of removed key accepted")
+	}
+}
+
+func verifiedBy(t *testing.T, ring *KeyRing, token string) bool {
+	t.Helper()
+	_, err := ring.Verify(token)
+	return err == nil
+}
+
+func TestLoadCurrentKid(t *testing.T) {
+	dir := t.TempDir()
+	writeKey(t, dir, "a", "rsa", nil)
+	writeKey(t, dir, "b", "ed25519", nil)
+
+	tests := []struct {
+		name    string
+		current string
+		err     bool
+	}{
+		{"newest by name", "", false},
+		{"selected rsa key", "a", false},
+		{"selected ed25519 key", "b", false},
+		{"unknown key", "c", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ring, err := Load([]string{dir}, tt.current)
+			if (err != nil) != tt.err {
+				t.Fatalf("Load error = %v, want error %v", err, tt.err)
+			}
+			if err != nil {
+				return
+			}
+
+			want := tt.current
+			if want == "" {
+				want = "b"
+			}
+			_, token, _ := ring.Encode(map[string]interface{}{})
+			only, _ := Load([]string{filepath.Join(dir, want+".pem")}, "")
+			if !verifiedBy(t, only, token) {
+				t.Errorf("token is not signed by key %q", want)
+			}
+		})
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	publicOnly := t.TempDir()
+	writeKey(t, publicOnly, "old", "public", writeKey(t, t.TempDir(), "old", "ed25519", nil))
+
+	invalid := t.TempDir()
+	if err := os.WriteFile(filepath.Join(invalid, "bad.pem"), []byte("not a key"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	duplicated := t.TempDir()
+	writeKey(t, duplicated, "same", "ed25519", nil)
+	other := t.TempDir()
+	writeKey(t, other, "same", "ed25519", nil)
+
+	tests := []struct {
+		name  string
+		paths []string
+	}{
+		{"public keys only", []string{publicOnly}},
+		{"invalid key", []string{invalid}},
+		{"duplicated kid", []string{duplicated, other}},
+		{"missing path", []string{filepath.Join(invalid, "missing")}},
+		{"empty directory", []string{t.TempDir()}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Load(tt.paths, ""); err == nil {
+				t.Errorf("Load succeeded, want error")
+			}
+		})
+	}
+}
+
+func TestVerifyRejects(t *testing.T) {
+	dir := t.TempDir()
+	writeKey(t, dir, "current", "ed25519", nil)
+	ring, err := Load([]string{dir}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// other key with the same kid, and HS256 token claiming the ring kid (algorithm confusion)
+	otherDir := t.TempDir()
+	writeKey(t, otherDir, "current", "ed25519", nil)
+	other, _ := Load([]string{otherDir}, "")
+	hmacToken := sign(t, NewSecret([]byte("secret")), map[string]interface{}{"sub": "user-1"})
+
+	valid := sign(t, ring, map[string]interface{}{"sub": "user-1"})
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"expired", sign(t, ring, map[string]interface{}{"sub": "user-1", "exp": time.Now().Add(-time.Minute).Unix()})},
+		{"not yet valid", sign(t, ring, map[string]interface{}{"sub": "user-1", "nbf": time.Now().Add(time.Hour).Unix()})},
+		{"signed by other key with same kid", sign(t, other, map[string]interface{}{"sub": "user-1"})},
+		{"hmac token without kid", hmacToken},
+		{"tampered", valid[:len(valid)-4] + "AAAA"},
+		{"not a token", "not-a-token"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ring.Verify(tt.token); err == nil {
+				t.Errorf("Verify accepted the token")
+			}
+		})
+	}
+}
+
+func TestTokenType(t *testing.T) {
+	// both rings load the same keys, only the "typ" claim tells their tokens apart
+	dir := t.TempDir()
+	writeKey(t, dir, "shared", "ed25519", nil)
+	access, _ := Load([]string{dir}, "")
+	access.SetType("access")
+	refresh, _ := Load([]string{dir}, "")
+	refresh.SetType("refresh")
+	untyped, _ := Load([]string{dir}, "")
+
+	claims := map[string]interface{}{"sub": "user-1"}
+	accessToken := sign(t, access, claims)
+	if _, ok := claims["typ"]; ok {
+		t.Error("Encode changed the caller claims")
+	}
+
+	tests := []struct {
+		name  string
+		ring  *KeyRing
+		token string
+		valid bool
+	}{
+		{"access token on access ring", access, accessToken, true},
+		{"refresh token on refresh ring", refresh, sign(t, refresh, claims), true},
+		{"refresh token on access ring", access, sign(t, refresh, claims), false},
+		{"access token on refresh ring", refresh, accessToken, false},
+		{"token without type", access, sign(t, untyped, claims), false},
+		{"token with forged type claim", access, sign(t, untyped, map[string]interface{}{"sub": "user-1", "typ": "refresh"}), false},
+		{"typed token on untyped ring", untyped, accessToken, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := tt.ring.Verify(tt.token)
+			if (err == nil) != tt.valid {
+				t.Fatalf("Verify() error = %v, want valid %v", err, tt.valid)
+			}
+			if tt.valid && tt.ring.tokenType != "" {
+				if typ, _ := token.Get("typ"); typ != tt.ring.tokenType {
+					t.Errorf("typ = %v, want %v", typ, tt.ring.tokenType)
+				}
+			}
+		})
+	}
+}
+
+func TestVerifierMiddleware(t *testing.T) {
+	dir := t.TempDir()
+	writeKey(t, dir, "current", "ed25519", nil)
+	ring, _ := Load([]string{dir}, "")
+	token := sign(t, ring, map[string]interface{}{"sub": "user-1"})
+
+	handler := Verifier(ring)(Authenticator(ring)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, claims, _ := jwtauth.FromContext(r.Context())
+		w.Header().Set("X-Subject", claims["sub"].(string))
+	})))
+
+	tests := []struct {
+		name   string
+		header string
+		cookie string
+		status int
+	}{
+		{"authorization header", "Bearer " + token, "", http.StatusOK},
+		{"jwt cookie", "", token, http.StatusOK},
+		{"no token", "", "", http.StatusUnauthorized},
+		{"invalid token", "Bearer " + token + "x", "", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				r.Header.Set("Authorization", tt.header)
+			}
+			if tt.cookie != "" {
+				r.AddCookie(&http.Cookie{Name: "jwt", Value: tt.cookie})
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			if w.Code != tt.status {
+				t.Errorf("status = %d, want %d", w.Code, tt.status)
+			}
+			if tt.status == http.StatusOK && w.Header().Get("X-Subject") != "user-1" {
+				t.Errorf("subject = %q, want user-1", w.Header().Get("X-Subject"))
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package jwtkeys
+
+import (
+	"github.com/bigusef/texorbit/pkg/util"
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"net/http"
+)
+
+// Verifier works like jwtauth.Verifier but select the verification key from the key ring,
+// verified token and error are stored in the context so jwtauth.FromContext keep working
+func Verifier(k *KeyRing) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			var token string
+			if token = jwtauth.TokenFromHeader(r); token == "" {
+				token = jwtauth.TokenFromCookie(r)
+			}
+
+			if token == "" {
+				ctx := jwtauth.NewContext(r.Context(), nil, jwtauth.ErrNoTokenFound)
+				next.ServeHTTP(w, r.WithContext(ctx))
+				return
+			}
+
+			t, err := k.Verify(token)
+			next.ServeHTTP(w, r.WithContext(jwtauth.NewContext(r.Context(), t, err)))
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+// Authenticator reject requests without valid token verified by Verifier,
+// it works like jwtauth.Authenticator but answers with problem details
+func Authenticator(k *KeyRing) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			token, _, err := jwtauth.FromContext(r.Context())
+			if err != nil {
+				util.ErrorResponseWriter(w, r, util.Unauthorized(err.Error()))
+				return
+			}
+
+			if token == nil || jwt.Validate(token) != nil {
+				util.ErrorResponseWriter(w, r, util.Unauthorized("invalid token"))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
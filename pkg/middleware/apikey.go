@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"context"
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// APIKey machine client authenticated by api key
+type APIKey struct {
+	ID        string
+	Scopes    []string
+	ExpiresAt time.Time
+}
+
+// APIKeyLookup return the api key matching the raw key sent by the client, or error when it is unknown or not usable
+type APIKeyLookup func(ctx context.Context, key string) (*APIKey, error)
+
+// APIKeyVerifier authenticate requests sent with "Authorization: ApiKey <key>" header, it should be placed after
+// the jwt verifier and replaces its result with token of the key claims, so authenticator and permissions middlewares
+// work the same for users and api keys. the key scopes are its permissions and "sub" claim is "apikey:<id>"
+func APIKeyVerifier(lookup APIKeyLookup) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			scheme, key, found := strings.Cut(r.Header.Get("Authorization"), " ")
+			if !found || !strings.EqualFold(scheme, "ApiKey") {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			apiKey, err := lookup(r.Context(), strings.TrimSpace(key))
+			if err != nil {
+				ctx := jwtauth.NewContext(r.Context(), nil, jwtauth.ErrUnauthorized)
+				next.ServeHTTP(w, r.WithContext(ctx))
+				return
+			}
+
+			token := jwt.New()
+			_ = token.Set(jwt.SubjectKey, "apikey:"+apiKey.ID)
+			_ = token.Set("perms", apiKey.Scopes)
+			_ = token.Set("staff", false)
+			if !apiKey.ExpiresAt.IsZero() {
+				_ = token.Set(jwt.ExpirationKey, apiKey.ExpiresAt)
+			}
+
+			next.ServeHTTP(w, r.WithContext(jwtauth.NewContext(r.Context(), token, nil)))
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
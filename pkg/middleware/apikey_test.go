@@ -0,0 +1,106 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"github.com/go-chi/jwtauth/v5"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestAPIKeyVerifier(t *testing.T) {
+	expires := time.Now().Add(time.Hour).Truncate(time.Second)
+	keys := map[string]*APIKey{
+		"txk_valid_secret":    {ID: "key-1", Scopes: []string{CityRead, CustomerRead}},
+		"txk_expiring_secret": {ID: "key-2", Scopes: []string{CityRead}, ExpiresAt: expires},
+	}
+	lookup := func(_ context.Context, key string) (*APIKey, error) {
+		if apiKey, ok := keys[key]; ok {
+			return apiKey, nil
+		}
+		return nil, errors.New("invalid api key")
+	}
+
+	tests := []struct {
+		name          string
+		authorization string
+		wantErr       error
+		wantSubject   string // empty when the request is left to the jwt verifier
+		wantExpires   time.Time
+	}{
+		{"api key", "ApiKey txk_valid_secret", nil, "apikey:key-1", time.Time{}},
+		{"scheme in any case", "apikey  txk_valid_secret ", nil, "apikey:key-1", time.Time{}},
+		{"expiring key", "ApiKey txk_expiring_secret", nil, "apikey:key-2", expires},
+		{"unknown key", "ApiKey txk_valid_wrong", jwtauth.ErrUnauthorized, "", time.Time{}},
+		{"bearer token left to jwt verifier", "Bearer eyJ.token", jwtauth.ErrNoTokenFound, "", time.Time{}},
+		{"no authorization", "", jwtauth.ErrNoTokenFound, "", time.Time{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotErr error
+			var gotClaims map[string]interface{}
+			handler := APIKeyVerifier(lookup)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, gotClaims, gotErr = jwtauth.FromContext(r.Context())
+			}))
+
+			r := httptest.NewRequest(http.MethodGet, "/cities", nil)
+			if tt.authorization != "" {
+				r.Header.Set("Authorization", tt.authorization)
+			}
+			// result of the jwt verifier placed before, replaced only for api keys
+			r = r.WithContext(jwtauth.NewContext(r.Context(), nil, jwtauth.ErrNoTokenFound))
+			handler.ServeHTTP(httptest.NewRecorder(), r)
+
+			if !errors.Is(gotErr, tt.wantErr) {
+				t.Fatalf("context error = %v, want %v", gotErr, tt.wantErr)
+			}
+			if tt.wantSubject == "" {
+				return
+			}
+
+			if gotClaims["sub"] != tt.wantSubject || gotClaims["staff"] != false {
+				t.Errorf("claims = %v, want subject %s and not staff", gotClaims, tt.wantSubject)
+			}
+			if !HasPermission(gotClaims, CityRead) || HasPermission(gotClaims, StaffManage) {
+				t.Errorf("perms = %v, want the key scopes only", gotClaims["perms"])
+			}
+			exp, _ := gotClaims["exp"].(time.Time)
+			if !exp.Equal(tt.wantExpires) {
+				t.Errorf("exp = %v, want %v", gotClaims["exp"], tt.wantExpires)
+			}
+		})
+	}
+}
+
+func TestAPIKeyPermissions(t *testing.T) {
+	lookup := func(_ context.Context, key string) (*APIKey, error) {
+		return &APIKey{ID: "key-1", Scopes: []string{CityRead}}, nil
+	}
+
+	tests := []struct {
+		permission string
+		want       int
+	}{
+		{CityRead, http.StatusNoContent},
+		{CityWrite, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.permission, func(t *testing.T) {
+			ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNoContent) })
+			handler := APIKeyVerifier(lookup)(RequirePermission(tt.permission)(ok))
+
+			r := httptest.NewRequest(http.MethodGet, "/cities", nil)
+			r.Header.Set("Authorization", "ApiKey txk_key_secret")
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
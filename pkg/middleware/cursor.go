@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// cursorSecret key signing the cursors, cursors can not be decoded until RegisterCursorSecret is called
+var cursorSecret []byte
+
+var errInvalidCursor = errors.New("invalid cursor")
+
+// RegisterCursorSecret set the key signing the page cursors
+func RegisterCursorSecret(secret []byte) {
+	cursorSecret = secret
+}
+
+// Cursor position of a keyset page, the sort keys of the row the page starts after
+// or, on backward pages, the row the page ends before
+type Cursor struct {
+	Keys   []string `json:"k"`
+	Before bool     `json:"b,omitempty"`
+}
+
+// pageParameters query parameters choosing the page or its presentation, the others filter the list
+var pageParameters = map[string]bool{"cursor": true, "limit": true, "offset": true, "count": true, "lang": true}
+
+// cursorFilter return the normalized filters of the list query (ex: "q=ali&status=active"), parameters sorted
+// by name and value with the page parameters and empty values left out
+func cursorFilter(query url.Values) string {
+	filter := url.Values{}
+	for key, values := range query {
+		if pageParameters[key] {
+			continue
+		}
+		for _, value := range values {
+			if value != "" {
+				filter[key] = append(filter[key], value)
+			}
+		}
+		sort.Strings(filter[key])
+	}
+	return filter.Encode()
+}
+
+// encodeCursor return the opaque cursor of the list path and filter, signed so clients can not forge keys
+// and bound to both so cursors of one list or filter are rejected by the others
+func encodeCursor(path, filter string, cursor Cursor) string {
+	data, _ := json.Marshal(cursor)
+	payload := base64.RawURLEncoding.EncodeToString(data)
+	return payload + "." + signCursor(path, filter, payload)
+}
+
+// decodeCursor return the cursor of the list path and filter, tampered or foreign cursors are rejected
+func decodeCursor(path, filter, value string) (*Cursor, error) {
+	payload, signature, ok := strings.Cut(value, ".")
+	if !ok || len(cursorSecret) == 0 || !hmac.Equal([]byte(signature), []byte(signCursor(path, filter, payload))) {
+		return nil, errInvalidCursor
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, errInvalidCursor
+	}
+
+	var cursor Cursor
+	if err := json.Unmarshal(data, &cursor); err != nil || len(cursor.Keys) == 0 {
+		return nil, errInvalidCursor
+	}
+	return &cursor, nil
+}
+
+func signCursor(path, filter, payload string) string {
+	mac := hmac.New(sha256.New, cursorSecret)
+	mac.Write([]byte(path + "\n" + filter + "\n" + payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
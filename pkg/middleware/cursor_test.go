@@ -0,0 +1,166 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// withCursorSecret set the cursor secret for the test, restoring the previous one after it
+func withCursorSecret(t *testing.T, secret string) {
+	t.Helper()
+	previous := cursorSecret
+	RegisterCursorSecret([]byte(secret))
+	t.Cleanup(func() { RegisterCursorSecret(previous) })
+}
+
+func TestCursorFilter(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"", ""},
+		{"limit=20&offset=40&count=none&cursor=abc&lang=ar", ""},
+		{"status=active&q=ali", "q=ali&status=active"},
+		{"q=ali&status=active&limit=5", "q=ali&status=active"},
+		{"status=&q=ali&joined_from=", "q=ali"},
+		{"status=suspended&status=active", "status=active&status=suspended"},
+		{"joined_to=2024-02-01T00:00:00Z&joined_from=2024-01-01T00:00:00Z",
+			"joined_from=2024-01-01T00%3A00%3A00Z&joined_to=2024-02-01T00%3A00%3A00Z"},
+	}
+
+	for _, tt := range tests {
+		query, _ := url.ParseQuery(tt.query)
+		if got := cursorFilter(query); got != tt.want {
+			t.Errorf("cursorFilter(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestDecodeCursor(t *testing.T) {
+	withCursorSecret(t, "test-secret")
+
+	cursor := Cursor{Keys: []string{"2024-01-31T10:00:00Z", "0b6f4c8e-5a43-4bd1-9a6e-0d1d2c7b1f00"}, Before: true}
+	value := encodeCursor("/user/", "q=ali&status=active", cursor)
+	payload, signature, _ := strings.Cut(value, ".")
+	forged := encodeCursor("/user/", "q=ali&status=active", Cursor{Keys: []string{"forged"}})
+	forgedPayload, _, _ := strings.Cut(forged, ".")
+
+	tests := []struct {
+		name   string
+		path   string
+		filter string
+		value  string
+		valid  bool
+	}{
+		{"same list and filter", "/user/", "q=ali&status=active", value, true},
+		{"other list", "/staff/", "q=ali&status=active", value, false},
+		{"other search", "/user/", "q=bob&status=active", value, false},
+		{"other status", "/user/", "q=ali&status=suspended", value, false},
+		{"filter removed", "/user/", "q=ali", value, false},
+		{"filter added", "/user/", "joined_from=2024-01-01&q=ali&status=active", value, false},
+		{"forged keys", "/user/", "q=ali&status=active", forgedPayload + "." + signature, false},
+		{"tampered signature", "/user/", "q=ali&status=active", payload + "." + strings.Repeat("A", len(signature)), false},
+		{"unsigned", "/user/", "q=ali&status=active", payload, false},
+		{"not base64", "/user/", "q=ali&status=active", "!!!." + signature, false},
+		{"empty", "/user/", "q=ali&status=active", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeCursor(tt.path, tt.filter, tt.value)
+			if !tt.valid {
+				if err == nil {
+					t.Fatalf("decodeCursor accepted the cursor: %+v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("decodeCursor: %v", err)
+			}
+			if !reflect.DeepEqual(*got, cursor) {
+				t.Errorf("decodeCursor = %+v, want %+v", *got, cursor)
+			}
+		})
+	}
+}
+
+func TestDecodeCursorSecret(t *testing.T) {
+	withCursorSecret(t, "first-secret")
+	value := encodeCursor("/city/", "", Cursor{Keys: []string{"42"}})
+
+	RegisterCursorSecret([]byte("second-secret"))
+	if _, err := decodeCursor("/city/", "", value); err == nil {
+		t.Errorf("cursor signed with other secret accepted")
+	}
+
+	RegisterCursorSecret(nil)
+	if _, err := decodeCursor("/city/", "", encodeCursor("/city/", "", Cursor{Keys: []string{"42"}})); err == nil {
+		t.Errorf("cursor accepted without secret")
+	}
+}
+
+func TestDecodeCursorWithoutKeys(t *testing.T) {
+	withCursorSecret(t, "test-secret")
+	if _, err := decodeCursor("/city/", "", encodeCursor("/city/", "", Cursor{})); err == nil {
+		t.Errorf("cursor without keys accepted")
+	}
+}
+
+// TestCursorPaginationLinks follow the next link of a filtered list, the cursor works with the filter
+// and page parameters of the link changed, and is rejected when the filter changes
+func TestCursorPaginationLinks(t *testing.T) {
+	withCursorSecret(t, "test-secret")
+
+	var page *Paginator
+	handler := CursorPagination(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page = r.Context().Value("pagination").(*Paginator)
+		page.SetLinks(w, []string{"1"}, []string{"20"}, true)
+	}))
+
+	serve := func(target string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+		return w
+	}
+
+	w := serve("/user/?q=ali&status=active&limit=20")
+	link := w.Header().Get("Link")
+	target := strings.TrimPrefix(strings.Split(link, ">")[0], "<")
+	next, err := url.Parse(target)
+	if err != nil || next.Query().Get("cursor") == "" {
+		t.Fatalf("next link = %q", link)
+	}
+
+	tests := []struct {
+		name  string
+		query func(url.Values)
+		valid bool
+	}{
+		{"as sent", func(url.Values) {}, true},
+		{"other limit and language", func(q url.Values) { q.Set("limit", "50"); q.Set("lang", "ar") }, true},
+		{"empty filter added", func(q url.Values) { q.Set("joined_to", "") }, true},
+		{"other search", func(q url.Values) { q.Set("q", "bob") }, false},
+		{"status removed", func(q url.Values) { q.Del("status") }, false},
+		{"date filter added", func(q url.Values) { q.Set("joined_from", "2024-01-01T00:00:00Z") }, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := next.Query()
+			tt.query(query)
+			page = nil
+
+			w := serve(next.Path + "?" + query.Encode())
+			if tt.valid && (w.Code != http.StatusOK || page == nil || page.Cursor == nil || page.Cursor.Keys[0] != "20") {
+				t.Errorf("status = %d, page = %+v, want the next page after 20", w.Code, page)
+			}
+			if !tt.valid && (w.Code != http.StatusBadRequest || page != nil) {
+				t.Errorf("status = %d, want the cursor rejected", w.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package middleware
+
+import (
+	"context"
+	"github.com/bigusef/texorbit/pkg/util"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/jwtauth/v5"
+	"log/slog"
+	"net/http"
+)
+
+// ImpersonationRequest describe request made with impersonation token, recorded for audit
+type ImpersonationRequest struct {
+	ImpersonationID string
+	Method          string
+	Path            string
+	Status          int
+}
+
+// ImpersonationRecorder persist impersonated requests
+type ImpersonationRecorder func(ctx context.Context, request ImpersonationRequest) error
+
+// Impersonator return the id of the staff member acting as the authenticated user from the token "act" claim,
+// empty when the request is not impersonated
+func Impersonator(ctx context.Context) string {
+	_, claims, err := jwtauth.FromContext(ctx)
+	if err != nil {
+		return ""
+	}
+
+	act, _ := claims["act"].(map[string]interface{})
+	sub, _ := act["sub"].(string)
+	return sub
+}
+
+// IsImpersonating check if the request is made by staff member acting as the user
+func IsImpersonating(ctx context.Context) bool {
+	return Impersonator(ctx) != ""
+}
+
+// Impersonation record every request made with impersonation token after it is served
+func Impersonation(record ImpersonationRecorder) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			if !IsImpersonating(r.Context()) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			next.ServeHTTP(ww, r)
+
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+
+			_, claims, _ := jwtauth.FromContext(r.Context())
+			sid, _ := claims["sid"].(string)
+			err := record(r.Context(), ImpersonationRequest{
+				ImpersonationID: sid,
+				Method:          r.Method,
+				Path:            r.URL.Path,
+				Status:          status,
+			})
+			if err != nil {
+				slog.Error("failed to record impersonated request", "impersonation", sid, "error", err)
+			}
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+// DenyImpersonation refuse sensitive actions when the request is made by staff member acting as the user
+func DenyImpersonation(next http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		if IsImpersonating(r.Context()) {
+			util.ErrorResponseWriter(w, r, util.Forbidden("this action is not allowed while impersonating"))
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	}
+
+	return http.HandlerFunc(fn)
+}
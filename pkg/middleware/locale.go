@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"github.com/bigusef/texorbit/pkg/i18n"
+	"github.com/go-chi/jwtauth/v5"
+	"net/http"
+)
+
+// PreferredLocale use the authenticated user preferred language (access token "lang" claim) instead of
+// Accept-Language, requests choosing the language with "lang" query parameter keep it
+func PreferredLocale(next http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		_, claims, _ := jwtauth.FromContext(r.Context())
+		locale, _ := claims["lang"].(string)
+
+		if _, ok := i18n.Parameter(r.URL.Query().Get("lang")); ok || !i18n.IsSupported(locale) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Set("Content-Language", locale)
+		next.ServeHTTP(w, r.WithContext(i18n.WithLocale(r.Context(), locale)))
+	}
+
+	return http.HandlerFunc(fn)
+}
@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"github.com/bigusef/texorbit/pkg/i18n"
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPreferredLocale(t *testing.T) {
+	i18n.Configure([]string{i18n.English, i18n.Arabic, "fr"}, map[string][]string{})
+	t.Cleanup(func() { i18n.Configure([]string{i18n.English, i18n.Arabic}, map[string][]string{}) })
+
+	tests := []struct {
+		name   string
+		lang   string // query parameter
+		header string // Accept-Language
+		claim  interface{}
+		want   string
+	}{
+		{"highest q-value wins", "", "en;q=0.1,ar;q=0.9", nil, i18n.Arabic},
+		{"q-values over header order", "", "fr;q=0.4,en;q=0.8,ar;q=0.6", nil, i18n.English},
+		{"regional tag matches its language", "", "fr-BE,en;q=0.5", nil, "fr"},
+		{"unsupported languages skipped", "", "de-DE,tr;q=0.9,ar;q=0.2", nil, i18n.Arabic},
+		{"invalid header is english", "", "ar;q=x", nil, i18n.English},
+		{"preferred language over header", "", "ar;q=0.9,en;q=0.8", "fr", "fr"},
+		{"query parameter over preferred language", "ar", "en", "fr", i18n.Arabic},
+		{"unsupported preferred language use header", "", "ar,en;q=0.5", "de", i18n.Arabic},
+		{"preferred language of other type ignored", "", "fr", 1, "fr"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			handler := i18n.Middleware(PreferredLocale(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = i18n.FromContext(r.Context())
+			})))
+
+			r := httptest.NewRequest(http.MethodGet, "/?lang="+tt.lang, nil)
+			r.Header.Set("Accept-Language", tt.header)
+			token := jwt.New()
+			if tt.claim != nil {
+				_ = token.Set("lang", tt.claim)
+			}
+			r = r.WithContext(jwtauth.NewContext(r.Context(), token, nil))
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			if got != tt.want || w.Header().Get("Content-Language") != tt.want {
+				t.Errorf("locale = %q (Content-Language %q), want %q", got, w.Header().Get("Content-Language"), tt.want)
+			}
+		})
+	}
+}
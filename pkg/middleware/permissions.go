@@ -1,25 +1,67 @@
 package middleware
 
 import (
+	"github.com/bigusef/texorbit/pkg/util"
 	"github.com/go-chi/jwtauth/v5"
 	"net/http"
 )
 
-func StaffPermission(next http.Handler) http.Handler {
-	fn := func(w http.ResponseWriter, r *http.Request) {
-		_, claims, err := jwtauth.FromContext(r.Context())
-		if err != nil {
-			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
-			return
+// Permissions codes stored in "permissions" table and granted to staff through their roles
+const (
+	CityRead            = "city:read"
+	CityWrite           = "city:write"
+	CityDelete          = "city:delete"
+	CustomerRead        = "customer:read"
+	CustomerWrite       = "customer:write"
+	CustomerImpersonate = "customer:impersonate"
+	StaffRead           = "staff:read"
+	StaffManage         = "staff:manage"
+	RoleManage          = "role:manage"
+	SecurityManage      = "security:manage"
+	ApiKeyManage        = "apikey:manage"
+	TranslationManage   = "translation:manage"
+)
+
+// HasPermission check if the access token claims grant the given permission
+func HasPermission(claims map[string]interface{}, permission string) bool {
+	switch perms := claims["perms"].(type) {
+	case []interface{}:
+		for _, p := range perms {
+			if p == permission {
+				return true
+			}
+		}
+	case []string:
+		for _, p := range perms {
+			if p == permission {
+				return true
+			}
 		}
+	}
 
-		if isStaff := claims["staff"].(bool); !isStaff {
-			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
-			return
+	return false
+}
+
+// RequirePermission allow the request only when the access token grants all the given permissions
+func RequirePermission(permissions ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			_, claims, err := jwtauth.FromContext(r.Context())
+			if err != nil {
+				util.ErrorResponseWriter(w, r, util.Unauthorized("authentication required"))
+				return
+			}
+
+			for _, permission := range permissions {
+				if !HasPermission(claims, permission) {
+					util.ErrorResponseWriter(w, r, util.Forbidden("access denied"))
+					return
+				}
+			}
+
+			next.ServeHTTP(w, r)
 		}
 
-		next.ServeHTTP(w, r)
+		return http.HandlerFunc(fn)
 	}
-
-	return http.HandlerFunc(fn)
 }
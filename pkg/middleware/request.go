@@ -2,38 +2,189 @@ package middleware
 
 import (
 	"context"
+	"fmt"
+	"github.com/bigusef/texorbit/pkg/util"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
+)
+
+// page size of list endpoints, larger limits are reduced to MaxPageSize
+const (
+	DefaultPageSize = 10
+	MaxPageSize     = 100
+)
+
+// total count modes of list endpoints, chosen by "count" query parameter
+const (
+	CountExact     = "exact"
+	CountEstimated = "estimated"
+	CountNone      = "none"
 )
 
 // Paginator contains limit and offset values extracted from query parameters
 type Paginator struct {
 	Limit  int64
 	Offset int64
+	// Cursor position of keyset pages, nil on offset pages
+	Cursor *Cursor
+	// Count total count mode, exact by default on offset pages and none on keyset pages
+	Count string
+
+	path  string
+	query url.Values
 }
 
 func Pagination(next http.Handler) http.Handler {
 	fn := func(w http.ResponseWriter, r *http.Request) {
-		limitStr := r.URL.Query().Get("limit")
-		offsetStr := r.URL.Query().Get("offset")
+		ctx := context.WithValue(r.Context(), "pagination", newPaginator(r))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	}
+
+	return http.HandlerFunc(fn)
+}
+
+// CursorPagination works like Pagination for lists supporting keyset pages, the page
+// starts at the signed "cursor" query parameter when it is sent instead of the offset
+func CursorPagination(next http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		page := newPaginator(r)
+		query := r.URL.Query()
 
-		limit, err := strconv.Atoi(limitStr)
-		if err != nil || limit <= 0 {
-			limit = 10 // Default limit if invalid or missing
+		if value := query.Get("cursor"); value != "" {
+			cursor, err := decodeCursor(page.path, cursorFilter(page.query), value)
+			if err != nil {
+				util.ErrorResponseWriter(w, r, util.Invalid(map[string]string{"cursor": "invalid"}))
+				return
+			}
+			page.Cursor, page.Offset, page.Count = cursor, 0, CountNone
 		}
 
-		offset, err := strconv.Atoi(offsetStr)
-		if err != nil || offset < 0 {
-			offset = 0 // Default offset if invalid or missing
+		switch count := query.Get("count"); count {
+		case "":
+		case CountExact, CountEstimated, CountNone:
+			page.Count = count
+		default:
+			util.ErrorResponseWriter(w, r, util.Invalid(map[string]string{"count": "oneof"}))
+			return
 		}
 
-		ctx := context.WithValue(r.Context(), "pagination", &Paginator{
-			Limit:  int64(limit),
-			Offset: int64(offset),
-		})
-
+		ctx := context.WithValue(r.Context(), "pagination", page)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	}
 
 	return http.HandlerFunc(fn)
 }
+
+func newPaginator(r *http.Request) *Paginator {
+	limitStr := r.URL.Query().Get("limit")
+	offsetStr := r.URL.Query().Get("offset")
+
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit <= 0 {
+		limit = DefaultPageSize // Default limit if invalid or missing
+	}
+	if limit > MaxPageSize {
+		limit = MaxPageSize
+	}
+
+	offset, err := strconv.Atoi(offsetStr)
+	if err != nil || offset < 0 {
+		offset = 0 // Default offset if invalid or missing
+	}
+
+	return &Paginator{
+		Limit:  int64(limit),
+		Offset: int64(offset),
+		Count:  CountExact,
+		path:   r.URL.Path,
+		query:  r.URL.Query(),
+	}
+}
+
+// Fetch number of rows to query on keyset pages, one more than the limit to know whether the list goes on
+func (p *Paginator) Fetch() int64 {
+	return p.Limit + 1
+}
+
+// Backward report whether the page is read backward, its rows are queried in reverse order
+func (p *Paginator) Backward() bool {
+	return p.Cursor != nil && p.Cursor.Before
+}
+
+// Page drop the extra row queried by Fetch and restore the order of backward pages,
+// it reports whether more rows follow the page in the direction it was read
+func Page[T any](p *Paginator, rows []T) ([]T, bool) {
+	more := int64(len(rows)) > p.Limit
+	if more {
+		rows = rows[:p.Limit]
+	}
+
+	if p.Backward() {
+		for i, j := 0, len(rows)-1; i < j; i, j = i+1, j-1 {
+			rows[i], rows[j] = rows[j], rows[i]
+		}
+	}
+	return rows, more
+}
+
+// SetLinks set Link header with "next" and "prev" pages given the sort keys of the first and last
+// rows of the page, empty pages have no links
+func (p *Paginator) SetLinks(w http.ResponseWriter, first, last []string, more bool) {
+	if len(first) == 0 || len(last) == 0 {
+		return
+	}
+
+	var links []string
+	if p.Backward() || more {
+		links = append(links, p.link(Cursor{Keys: last}, "next"))
+	}
+	if p.Backward() && more || !p.Backward() && (p.Cursor != nil || p.Offset > 0) {
+		links = append(links, p.link(Cursor{Keys: first, Before: true}, "prev"))
+	}
+
+	if len(links) > 0 {
+		w.Header().Set("Link", strings.Join(links, ", "))
+	}
+}
+
+func (p *Paginator) link(cursor Cursor, rel string) string {
+	query := url.Values{}
+	for key, values := range p.query {
+		query[key] = values
+	}
+	query.Del("offset")
+	query.Set("cursor", encodeCursor(p.path, cursorFilter(p.query), cursor))
+
+	return fmt.Sprintf(`<%s?%s>; rel="%s"`, p.path, query.Encode(), rel)
+}
+
+// AllowContentType works like chi AllowContentType, requests with body of other content types
+// are rejected with 415 problem details
+func AllowContentType(contentTypes ...string) func(http.Handler) http.Handler {
+	allowed := make(map[string]bool, len(contentTypes))
+	for _, contentType := range contentTypes {
+		allowed[strings.ToLower(contentType)] = true
+	}
+
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			if r.ContentLength == 0 {
+				// skip check for empty content body
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			contentType := strings.ToLower(strings.TrimSpace(strings.Split(r.Header.Get("Content-Type"), ";")[0]))
+			if !allowed[contentType] {
+				util.ErrorResponseWriter(w, r, util.NewError(http.StatusUnsupportedMediaType, util.CodeUnsupportedMedia, "unsupported content type"))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
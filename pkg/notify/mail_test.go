@@ -0,0 +1,195 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"io"
+	"mime"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"testing"
+)
+
+// smtpSession what the stand-in SMTP server received from one client
+type smtpSession struct {
+	auth string
+	from string
+	to   string
+	data []byte
+}
+
+// smtpServer in-process SMTP stand-in, it advertises AUTH PLAIN when credentials are set
+// and rejects RCPT for the reject address
+type smtpServer struct {
+	listener net.Listener
+	username string
+	password string
+	reject   string
+	sessions chan smtpSession
+}
+
+func newSMTPServer(t *testing.T, username, password, reject string) *smtpServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	server := &smtpServer{listener: listener, username: username, password: password, reject: reject, sessions: make(chan smtpSession, 1)}
+	go server.serve()
+	return server
+}
+
+func (s *smtpServer) mailer() *SMTPMailer {
+	_, port, _ := net.SplitHostPort(s.listener.Addr().String())
+	return &SMTPMailer{Host: "127.0.0.1", Port: port, Username: s.username, Password: s.password, From: "no-reply@texorbit.com"}
+}
+
+func (s *smtpServer) serve() {
+	conn, err := s.listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	tp := textproto.NewConn(conn)
+	var session smtpSession
+	defer func() { s.sessions <- session }()
+
+	_ = tp.PrintfLine("220 localhost ESMTP stand-in")
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		verb, arg, _ := strings.Cut(line, " ")
+
+		switch strings.ToUpper(verb) {
+		case "EHLO", "HELO":
+			if s.username != "" {
+				_ = tp.PrintfLine("250-localhost")
+				_ = tp.PrintfLine("250 AUTH PLAIN")
+			} else {
+				_ = tp.PrintfLine("250 localhost")
+			}
+		case "AUTH":
+			mechanism, initial, _ := strings.Cut(arg, " ")
+			credentials, _ := base64.StdEncoding.DecodeString(initial)
+			session.auth = string(credentials)
+			if mechanism == "PLAIN" && session.auth == "\x00"+s.username+"\x00"+s.password {
+				_ = tp.PrintfLine("235 2.7.0 Authentication successful")
+			} else {
+				_ = tp.PrintfLine("535 5.7.8 Authentication credentials invalid")
+			}
+		case "MAIL":
+			session.from = strings.Trim(strings.TrimPrefix(arg, "FROM:"), "<>")
+			_ = tp.PrintfLine("250 2.1.0 Ok")
+		case "RCPT":
+			to := strings.Trim(strings.TrimPrefix(arg, "TO:"), "<>")
+			if to == s.reject {
+				_ = tp.PrintfLine("550 5.1.1 Recipient address rejected")
+				continue
+			}
+			session.to = to
+			_ = tp.PrintfLine("250 2.1.5 Ok")
+		case "DATA":
+			_ = tp.PrintfLine("354 End data with <CR><LF>.<CR><LF>")
+			if session.data, err = tp.ReadDotBytes(); err != nil {
+				return
+			}
+			_ = tp.PrintfLine("250 2.0.0 Ok: queued")
+		case "QUIT":
+			_ = tp.PrintfLine("221 2.0.0 Bye")
+			return
+		default:
+			_ = tp.PrintfLine("250 Ok")
+		}
+	}
+}
+
+func TestSMTPMailer(t *testing.T) {
+	msg := Message{To: "user@example.com", Subject: "رابط تسجيل الدخول", Body: "Use the link below:\nhttps://app.texorbit.com/auth/magic?token=abc\n"}
+
+	tests := []struct {
+		name     string
+		username string
+		password string
+		login    string // password sent by the mailer, the server password when empty
+		reject   string
+		wantErr  bool
+	}{
+		{"without authentication", "", "", "", "", false},
+		{"with authentication", "mailer", "secret", "", "", false},
+		{"wrong password", "mailer", "secret", "wrong", "", true},
+		{"rejected recipient", "", "", "", "user@example.com", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newSMTPServer(t, tt.username, tt.password, tt.reject)
+			mailer := server.mailer()
+			if tt.login != "" {
+				mailer.Password = tt.login
+			}
+
+			err := mailer.Send(context.Background(), msg)
+			session := <-server.sessions
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Send succeeded")
+				}
+				if session.data != nil {
+					t.Error("message delivered after failure")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Send() error = %v", err)
+			}
+
+			if tt.username != "" && session.auth != "\x00mailer\x00secret" {
+				t.Errorf("auth = %q, want plain credentials", session.auth)
+			}
+			if session.from != "no-reply@texorbit.com" || session.to != msg.To {
+				t.Errorf("envelope = %q -> %q", session.from, session.to)
+			}
+
+			parsed, err := mail.ReadMessage(bytes.NewReader(session.data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			subject, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+			if err != nil || subject != msg.Subject {
+				t.Errorf("subject = %q, %v, want %q", subject, err, msg.Subject)
+			}
+			if parsed.Header.Get("To") != msg.To || parsed.Header.Get("From") != "no-reply@texorbit.com" {
+				t.Errorf("headers = %v", parsed.Header)
+			}
+			if parsed.Header.Get("Content-Type") != "text/plain; charset=UTF-8" {
+				t.Errorf("content type = %q", parsed.Header.Get("Content-Type"))
+			}
+
+			body, _ := io.ReadAll(parsed.Body)
+			if strings.ReplaceAll(string(body), "\r\n", "\n") != msg.Body {
+				t.Errorf("body = %q, want %q", body, msg.Body)
+			}
+		})
+	}
+}
+
+func TestSMTPMailerUnreachable(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, port, _ := net.SplitHostPort(listener.Addr().String())
+	listener.Close()
+
+	mailer := &SMTPMailer{Host: "127.0.0.1", Port: port, From: "no-reply@texorbit.com"}
+	if err := mailer.Send(context.Background(), Message{To: "user@example.com"}); err == nil {
+		t.Error("Send to closed port succeeded")
+	}
+}
@@ -0,0 +1,47 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// SMSSender deliver text messages to phone numbers in E.164 format
+type SMSSender interface {
+	Send(ctx context.Context, to, message string) error
+}
+
+// LogSMSSender write messages to the application log instead of sending them, for local development
+type LogSMSSender struct{}
+
+func (LogSMSSender) Send(_ context.Context, to, message string) error {
+	slog.Info("sms message", "to", to, "message", message)
+	return nil
+}
+
+// FileSMSSender append every message as JSON line to a file, so tests and QA can read the sent codes
+type FileSMSSender struct {
+	Path string
+
+	mu sync.Mutex
+}
+
+func (s *FileSMSSender) Send(_ context.Context, to, message string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, err := os.OpenFile(s.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return json.NewEncoder(file).Encode(map[string]string{
+		"time":    time.Now().Format(time.RFC3339),
+		"to":      to,
+		"message": message,
+	})
+}
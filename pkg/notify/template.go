@@ -0,0 +1,38 @@
+package notify
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"github.com/bigusef/texorbit/pkg/i18n"
+	"strings"
+	"text/template"
+)
+
+//go:embed templates/*.tmpl
+var templateFiles embed.FS
+
+var templates = template.Must(template.ParseFS(templateFiles, "templates/*.tmpl"))
+
+// Render build message subject and body from "<name>.<lang>.tmpl" template, falling back through
+// the locale chain (ex: fr-CA, fr, en) when the template is not translated to the requested language
+func Render(name, lang string, data interface{}) (subject string, body string, err error) {
+	var tmpl *template.Template
+	for _, locale := range i18n.Chain(lang) {
+		if tmpl = templates.Lookup(fmt.Sprintf("%s.%s.tmpl", name, locale)); tmpl != nil {
+			break
+		}
+	}
+	if tmpl == nil {
+		return "", "", fmt.Errorf("notify: template %q not found", name)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", "", err
+	}
+
+	// first line of the template is the subject, and the rest is the body
+	subject, body, _ = strings.Cut(buf.String(), "\n")
+	return strings.TrimSpace(subject), strings.TrimSpace(body) + "\n", nil
+}
@@ -0,0 +1,57 @@
+package notify
+
+import (
+	"github.com/bigusef/texorbit/pkg/i18n"
+	"strings"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	// french is supported without its own templates, canadian french fall back to arabic then french
+	i18n.Configure([]string{i18n.English, i18n.Arabic, "fr", "fr-CA"}, map[string][]string{"fr-CA": {i18n.Arabic}})
+	t.Cleanup(func() { i18n.Configure([]string{i18n.English, i18n.Arabic}, map[string][]string{}) })
+
+	data := map[string]interface{}{"Link": "https://app.texorbit.com/auth/magic?token=abc", "ExpiresIn": 15}
+
+	tests := []struct {
+		name        string
+		lang        string
+		wantSubject string
+		wantBody    string
+	}{
+		{"english", "en", "Your TexOrbit sign in link", "The link expires in 15 minutes"},
+		{"arabic", "ar", "رابط تسجيل الدخول إلى تكس أوربت", "تنتهي صلاحية الرابط خلال 15 دقيقة"},
+		{"regional arabic falls back to arabic", "ar-EG", "رابط تسجيل الدخول إلى تكس أوربت", "15 دقيقة"},
+		{"untranslated locale falls back to english", "fr", "Your TexOrbit sign in link", "15 minutes"},
+		{"configured fallback chain", "fr-CA", "رابط تسجيل الدخول إلى تكس أوربت", "15 دقيقة"},
+		{"empty locale falls back to english", "", "Your TexOrbit sign in link", "15 minutes"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			subject, body, err := Render("magic_link", tt.lang, data)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if subject != tt.wantSubject {
+				t.Errorf("subject = %q, want %q", subject, tt.wantSubject)
+			}
+			if !strings.Contains(body, tt.wantBody) || !strings.Contains(body, "https://app.texorbit.com/auth/magic?token=abc") {
+				t.Errorf("body = %q, want link and %q", body, tt.wantBody)
+			}
+			if strings.Contains(body, tt.wantSubject) {
+				t.Errorf("body repeats the subject line: %q", body)
+			}
+			if !strings.HasSuffix(body, "\n") || strings.HasSuffix(body, "\n\n") {
+				t.Errorf("body must end with single new line: %q", body)
+			}
+		})
+	}
+}
+
+func TestRenderUnknownTemplate(t *testing.T) {
+	if _, _, err := Render("password_reset", "en", nil); err == nil {
+		t.Error("Render of unknown template succeeded")
+	}
+}
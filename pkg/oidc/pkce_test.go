@@ -0,0 +1,43 @@
+package oidc
+
+import (
+	"encoding/base64"
+	"testing"
+)
+
+func TestCodeChallenge(t *testing.T) {
+	tests := []struct {
+		verifier string
+		want     string
+	}{
+		// RFC 7636 appendix B
+		{"dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk", "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"},
+		{"", "47DEQpj8HBSa-_TImW-5JCeuQeRkm5NMpJWZG3hSuFU"},
+	}
+
+	for _, tt := range tests {
+		if got := CodeChallenge(tt.verifier); got != tt.want {
+			t.Errorf("CodeChallenge(%q) = %s, want %s", tt.verifier, got, tt.want)
+		}
+	}
+}
+
+func TestRandomString(t *testing.T) {
+	seen := map[string]bool{}
+	for i := 0; i < 100; i++ {
+		value, err := RandomString(32)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// 32 bytes are 43 url safe characters, in the 43 to 128 characters range of PKCE verifiers
+		decoded, err := base64.RawURLEncoding.DecodeString(value)
+		if err != nil || len(decoded) != 32 || len(value) != 43 {
+			t.Fatalf("RandomString = %q, want 43 url safe characters", value)
+		}
+		if seen[value] {
+			t.Fatalf("RandomString repeated %q", value)
+		}
+		seen[value] = true
+	}
+}
@@ -0,0 +1,268 @@
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jws"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrInvalidIDToken = errors.New("invalid id token")
+
+// Config contains the client registration of one OpenID Connect provider
+type Config struct {
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
+
+// Identity is the verified user identity extracted from the provider ID token
+type Identity struct {
+	Provider      string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+	Picture       string
+}
+
+type discovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JwksURI               string `json:"jwks_uri"`
+}
+
+// Provider talks to a single OpenID Connect issuer using the authorization code flow with PKCE.
+// discovery document and signing keys are fetched lazily and cached, so the issuer does not need
+// to be reachable when the server starts.
+type Provider struct {
+	conf   Config
+	client *http.Client
+
+	mu            sync.Mutex
+	meta          *discovery
+	keys          jwk.Set
+	keysFetchedAt time.Time
+}
+
+// NewProvider creates provider from its configuration, if client is nil a default client with timeout will be used
+func NewProvider(conf Config, client *http.Client) *Provider {
+	if client == nil {
+		client = &http.Client{Timeout: time.Second * 10}
+	}
+
+	if len(conf.Scopes) == 0 {
+		conf.Scopes = []string{"openid", "email", "profile"}
+	}
+
+	return &Provider{conf: conf, client: client}
+}
+
+func (p *Provider) Name() string {
+	return p.conf.Name
+}
+
+// AuthCodeURL build the provider authorization URL the client should be redirected to
+func (p *Provider) AuthCodeURL(ctx context.Context, state, nonce, codeVerifier string) (string, error) {
+	meta, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	authURL, err := url.Parse(meta.AuthorizationEndpoint)
+	if err != nil {
+		return "", err
+	}
+
+	query := authURL.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", p.conf.ClientID)
+	query.Set("redirect_uri", p.conf.RedirectURL)
+	query.Set("scope", strings.Join(p.conf.Scopes, " "))
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", CodeChallenge(codeVerifier))
+	query.Set("code_challenge_method", "S256")
+	authURL.RawQuery = query.Encode()
+
+	return authURL.String(), nil
+}
+
+// Exchange trade the authorization code for tokens and return the identity from the verified ID token
+func (p *Provider) Exchange(ctx context.Context, code, codeVerifier, nonce string) (*Identity, error) {
+	meta, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.conf.RedirectURL)
+	form.Set("client_id", p.conf.ClientID)
+	form.Set("client_secret", p.conf.ClientSecret)
+	form.Set("code_verifier", codeVerifier)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, meta.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("oidc %s: token endpoint returned status %d", p.conf.Name, resp.StatusCode)
+	}
+
+	var tokens struct {
+		IDToken string `json:"id_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tokens); err != nil {
+		return nil, err
+	}
+
+	if tokens.IDToken == "" {
+		return nil, fmt.Errorf("oidc %s: token response without id_token", p.conf.Name)
+	}
+
+	return p.VerifyIDToken(ctx, tokens.IDToken, nonce)
+}
+
+// VerifyIDToken validate signature, issuer, audience, expiry and nonce of the raw ID token
+func (p *Provider) VerifyIDToken(ctx context.Context, rawToken, nonce string) (*Identity, error) {
+	meta, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := p.parseIDToken(ctx, meta, rawToken, false)
+	if err != nil {
+		// the provider may have rotated its signing keys, so reload them once and retry
+		if token, err = p.parseIDToken(ctx, meta, rawToken, true); err != nil {
+			return nil, errors.Join(ErrInvalidIDToken, err)
+		}
+	}
+
+	if claimNonce, _ := token.Get("nonce"); claimNonce != nonce {
+		return nil, errors.Join(ErrInvalidIDToken, errors.New("nonce mismatch"))
+	}
+
+	identity := &Identity{
+		Provider: p.conf.Name,
+		Subject:  token.Subject(),
+	}
+	if v, ok := token.Get("email"); ok {
+		identity.Email, _ = v.(string)
+	}
+	if v, ok := token.Get("email_verified"); ok {
+		// some providers send this claim as string
+		switch verified := v.(type) {
+		case bool:
+			identity.EmailVerified = verified
+		case string:
+			identity.EmailVerified = verified == "true"
+		}
+	}
+	if v, ok := token.Get("name"); ok {
+		identity.Name, _ = v.(string)
+	}
+	if v, ok := token.Get("picture"); ok {
+		identity.Picture, _ = v.(string)
+	}
+
+	if identity.Email == "" {
+		return nil, errors.Join(ErrInvalidIDToken, errors.New("missing email claim"))
+	}
+
+	return identity, nil
+}
+
+func (p *Provider) parseIDToken(ctx context.Context, meta *discovery, rawToken string, refresh bool) (jwt.Token, error) {
+	keys, err := p.signingKeys(ctx, meta, refresh)
+	if err != nil {
+		return nil, err
+	}
+
+	return jwt.Parse(
+		[]byte(rawToken),
+		jwt.WithKeySet(keys, jws.WithInferAlgorithmFromKey(true)),
+		jwt.WithValidate(true),
+		jwt.WithIssuer(meta.Issuer),
+		jwt.WithAudience(p.conf.ClientID),
+		jwt.WithAcceptableSkew(time.Minute),
+	)
+}
+
+func (p *Provider) discover(ctx context.Context) (*discovery, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.meta != nil {
+		return p.meta, nil
+	}
+
+	wellKnown := strings.TrimSuffix(p.conf.Issuer, "/") + "/.well-known/openid-configuration"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, wellKnown, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("oidc %s: discovery returned status %d", p.conf.Name, resp.StatusCode)
+	}
+
+	var meta discovery
+	if err := json.NewDecoder(resp.Body).Decode(&meta); err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSuffix(meta.Issuer, "/") != strings.TrimSuffix(p.conf.Issuer, "/") {
+		return nil, fmt.Errorf("oidc %s: issuer mismatch %q", p.conf.Name, meta.Issuer)
+	}
+
+	p.meta = &meta
+	return p.meta, nil
+}
+
+func (p *Provider) signingKeys(ctx context.Context, meta *discovery, refresh bool) (jwk.Set, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// avoid hammering the provider when refresh requested by invalid tokens
+	if p.keys != nil && (!refresh || time.Since(p.keysFetchedAt) < time.Minute) {
+		return p.keys, nil
+	}
+
+	keys, err := jwk.Fetch(ctx, meta.JwksURI, jwk.WithHTTPClient(p.client))
+	if err != nil {
+		return nil, err
+	}
+
+	p.keys = keys
+	p.keysFetchedAt = time.Now()
+	return p.keys, nil
+}
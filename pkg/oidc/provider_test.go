@@ -0,0 +1,336 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeIssuer OpenID provider serving discovery, keys and the token endpoint, authorization codes
+// are issued with the PKCE challenge and the ID token they are exchanged for
+type fakeIssuer struct {
+	t      *testing.T
+	server *httptest.Server
+
+	mu    sync.Mutex
+	keys  []jwk.Key
+	codes map[string]issuedCode
+}
+
+type issuedCode struct {
+	challenge string
+	idToken   string
+}
+
+func newFakeIssuer(t *testing.T) *fakeIssuer {
+	t.Helper()
+	issuer := &fakeIssuer{t: t, codes: map[string]issuedCode{}}
+	issuer.rotate("key-1")
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(discovery{
+			Issuer:                issuer.server.URL,
+			AuthorizationEndpoint: issuer.server.URL + "/authorize",
+			TokenEndpoint:         issuer.server.URL + "/token",
+			JwksURI:               issuer.server.URL + "/keys",
+		})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		issuer.mu.Lock()
+		defer issuer.mu.Unlock()
+
+		set := jwk.NewSet()
+		for _, key := range issuer.keys {
+			public, _ := key.PublicKey()
+			_ = set.AddKey(public)
+		}
+		_ = json.NewEncoder(w).Encode(set)
+	})
+	mux.HandleFunc("/token", issuer.token)
+
+	issuer.server = httptest.NewServer(mux)
+	t.Cleanup(issuer.server.Close)
+	return issuer
+}
+
+// rotate add new signing key, the newest key signs the tokens
+func (f *fakeIssuer) rotate(kid string) {
+	raw, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		f.t.Fatal(err)
+	}
+	key, _ := jwk.FromRaw(raw)
+	_ = key.Set(jwk.KeyIDKey, kid)
+	_ = key.Set(jwk.AlgorithmKey, jwa.RS256)
+
+	f.mu.Lock()
+	f.keys = append(f.keys, key)
+	f.mu.Unlock()
+}
+
+// idToken sign ID token of the claims with the newest key, claims override the defaults
+func (f *fakeIssuer) idToken(claims map[string]interface{}) string {
+	token := jwt.New()
+	defaults := map[string]interface{}{
+		jwt.IssuerKey:     f.server.URL,
+		jwt.AudienceKey:   "client-id",
+		jwt.SubjectKey:    "subject-1",
+		jwt.IssuedAtKey:   time.Now(),
+		jwt.ExpirationKey: time.Now().Add(time.Hour),
+		"nonce":           "nonce-1",
+		"email":           "ali@example.com",
+		"email_verified":  true,
+		"name":            "Ali",
+		"picture":         "https://example.com/ali.png",
+	}
+	for key, value := range claims {
+		defaults[key] = value
+	}
+	for key, value := range defaults {
+		if value != nil {
+			_ = token.Set(key, value)
+		}
+	}
+
+	f.mu.Lock()
+	key := f.keys[len(f.keys)-1]
+	f.mu.Unlock()
+
+	signed, err := jwt.Sign(token, jwt.WithKey(jwa.RS256, key))
+	if err != nil {
+		f.t.Fatal(err)
+	}
+	return string(signed)
+}
+
+// authorize issue code for the PKCE challenge like the provider authorization endpoint
+func (f *fakeIssuer) authorize(authURL string, idToken string) string {
+	parsed, err := url.Parse(authURL)
+	if err != nil {
+		f.t.Fatal(err)
+	}
+	query := parsed.Query()
+	if query.Get("code_challenge_method") != "S256" {
+		f.t.Fatalf("code_challenge_method = %q, want S256", query.Get("code_challenge_method"))
+	}
+
+	code, _ := RandomString(16)
+	f.mu.Lock()
+	f.codes[code] = issuedCode{challenge: query.Get("code_challenge"), idToken: idToken}
+	f.mu.Unlock()
+	return code
+}
+
+func (f *fakeIssuer) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil || r.PostForm.Get("grant_type") != "authorization_code" ||
+		r.PostForm.Get("client_id") != "client-id" || r.PostForm.Get("client_secret") != "client-secret" {
+		http.Error(w, `{"error":"invalid_client"}`, http.StatusUnauthorized)
+		return
+	}
+
+	f.mu.Lock()
+	issued, ok := f.codes[r.PostForm.Get("code")]
+	delete(f.codes, r.PostForm.Get("code"))
+	f.mu.Unlock()
+
+	// RFC 7636 section 4.6, the verifier must hash to the challenge sent with the authorization request
+	if !ok || CodeChallenge(r.PostForm.Get("code_verifier")) != issued.challenge {
+		http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(map[string]string{"access_token": "access", "id_token": issued.idToken})
+}
+
+func (f *fakeIssuer) provider() *Provider {
+	return NewProvider(Config{
+		Name:         "fake",
+		Issuer:       f.server.URL,
+		ClientID:     "client-id",
+		ClientSecret: "client-secret",
+		RedirectURL:  "https://app.texorbit.com/auth/callback",
+	}, f.server.Client())
+}
+
+func TestAuthCodeURL(t *testing.T) {
+	issuer := newFakeIssuer(t)
+
+	authURL, err := issuer.provider().AuthCodeURL(context.Background(), "state-1", "nonce-1", "verifier")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, _ := url.Parse(authURL)
+	want := map[string]string{
+		"response_type":         "code",
+		"client_id":             "client-id",
+		"redirect_uri":          "https://app.texorbit.com/auth/callback",
+		"scope":                 "openid email profile",
+		"state":                 "state-1",
+		"nonce":                 "nonce-1",
+		"code_challenge":        CodeChallenge("verifier"),
+		"code_challenge_method": "S256",
+	}
+	for key, value := range want {
+		if got := parsed.Query().Get(key); got != value {
+			t.Errorf("%s = %q, want %q", key, got, value)
+		}
+	}
+	if parsed.Path != "/authorize" {
+		t.Errorf("authorization endpoint = %s", parsed.Path)
+	}
+}
+
+func TestExchangePKCE(t *testing.T) {
+	issuer := newFakeIssuer(t)
+	provider := issuer.provider()
+	ctx := context.Background()
+
+	verifier, _ := RandomString(32)
+	authURL, err := provider.AuthCodeURL(ctx, "state-1", "nonce-1", verifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		verifier string
+		nonce    string
+		valid    bool
+	}{
+		{"matching verifier", verifier, "nonce-1", true},
+		{"other verifier", "other-verifier-value", "nonce-1", false},
+		{"other nonce", verifier, "nonce-2", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code := issuer.authorize(authURL, issuer.idToken(nil))
+			identity, err := provider.Exchange(ctx, code, tt.verifier, tt.nonce)
+			if !tt.valid {
+				if err == nil {
+					t.Fatalf("Exchange = %+v, want error", identity)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			want := Identity{Provider: "fake", Subject: "subject-1", Email: "ali@example.com", EmailVerified: true,
+				Name: "Ali", Picture: "https://example.com/ali.png"}
+			if *identity != want {
+				t.Errorf("identity = %+v, want %+v", *identity, want)
+			}
+		})
+	}
+
+	// codes are single use
+	code := issuer.authorize(authURL, issuer.idToken(nil))
+	if _, err := provider.Exchange(ctx, code, verifier, "nonce-1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := provider.Exchange(ctx, code, verifier, "nonce-1"); err == nil {
+		t.Errorf("code exchanged twice")
+	}
+}
+
+func TestVerifyIDToken(t *testing.T) {
+	issuer := newFakeIssuer(t)
+	provider := issuer.provider()
+	ctx := context.Background()
+
+	// token signed by key unknown to the issuer
+	stranger := newFakeIssuer(t)
+	strangerToken := stranger.idToken(map[string]interface{}{jwt.IssuerKey: issuer.server.URL})
+
+	tests := []struct {
+		name   string
+		token  string
+		valid  bool
+		verify func(*Identity) bool
+	}{
+		{"valid", issuer.idToken(nil), true, nil},
+		{"email verified as string", issuer.idToken(map[string]interface{}{"email_verified": "true"}), true,
+			func(i *Identity) bool { return i.EmailVerified }},
+		{"email not verified", issuer.idToken(map[string]interface{}{"email_verified": false}), true,
+			func(i *Identity) bool { return !i.EmailVerified }},
+		{"audience list with client", issuer.idToken(map[string]interface{}{jwt.AudienceKey: []string{"other", "client-id"}}), true, nil},
+		{"expired", issuer.idToken(map[string]interface{}{jwt.ExpirationKey: time.Now().Add(-2 * time.Minute)}), false, nil},
+		{"expired within skew", issuer.idToken(map[string]interface{}{jwt.ExpirationKey: time.Now().Add(-30 * time.Second)}), true, nil},
+		{"other audience", issuer.idToken(map[string]interface{}{jwt.AudienceKey: "other-client"}), false, nil},
+		{"other issuer", issuer.idToken(map[string]interface{}{jwt.IssuerKey: "https://evil.example.com"}), false, nil},
+		{"other nonce", issuer.idToken(map[string]interface{}{"nonce": "nonce-2"}), false, nil},
+		{"missing nonce", issuer.idToken(map[string]interface{}{"nonce": nil}), false, nil},
+		{"missing email", issuer.idToken(map[string]interface{}{"email": nil}), false, nil},
+		{"unknown signing key", strangerToken, false, nil},
+		{"not a token", "not-a-token", false, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			identity, err := provider.VerifyIDToken(ctx, tt.token, "nonce-1")
+			if !tt.valid {
+				if !errors.Is(err, ErrInvalidIDToken) {
+					t.Fatalf("VerifyIDToken error = %v, want ErrInvalidIDToken", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("VerifyIDToken: %v", err)
+			}
+			if tt.verify != nil && !tt.verify(identity) {
+				t.Errorf("identity = %+v", *identity)
+			}
+		})
+	}
+}
+
+func TestVerifyIDTokenKeyRotation(t *testing.T) {
+	issuer := newFakeIssuer(t)
+	provider := issuer.provider()
+	ctx := context.Background()
+
+	if _, err := provider.VerifyIDToken(ctx, issuer.idToken(nil), "nonce-1"); err != nil {
+		t.Fatal(err)
+	}
+
+	// the provider rotates its key, tokens of the new key reload the cached keys once they are a minute old
+	issuer.rotate("key-2")
+	rotated := issuer.idToken(nil)
+	if _, err := provider.VerifyIDToken(ctx, rotated, "nonce-1"); err == nil {
+		t.Errorf("keys reloaded less than a minute after the last fetch")
+	}
+
+	provider.mu.Lock()
+	provider.keysFetchedAt = time.Now().Add(-2 * time.Minute)
+	provider.mu.Unlock()
+	if _, err := provider.VerifyIDToken(ctx, rotated, "nonce-1"); err != nil {
+		t.Errorf("token of rotated key: %v", err)
+	}
+}
+
+func TestDiscoveryIssuerMismatch(t *testing.T) {
+	// discovery document naming another issuer, tokens of that issuer must not be accepted
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(discovery{Issuer: "https://evil.example.com", AuthorizationEndpoint: "https://evil.example.com/authorize"})
+	}))
+	t.Cleanup(server.Close)
+
+	provider := NewProvider(Config{Name: "fake", Issuer: server.URL, ClientID: "client-id"}, server.Client())
+	if _, err := provider.AuthCodeURL(context.Background(), "state", "nonce", "verifier"); err == nil {
+		t.Errorf("AuthCodeURL succeeded with discovery of other issuer")
+	}
+}
@@ -0,0 +1,49 @@
+package otp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+const (
+	CodeLength     = 6
+	CodeTTL        = time.Minute * 5
+	MaxAttempts    = 5
+	ResendInterval = time.Minute
+	MaxPerHour     = 5
+)
+
+// GenerateCode return random numeric code with CodeLength digits
+func GenerateCode() (string, error) {
+	limit := new(big.Int).Exp(big.NewInt(10), big.NewInt(CodeLength), nil)
+	n, err := rand.Int(rand.Reader, limit)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%0*d", CodeLength, n), nil
+}
+
+// Hash return the stored form of the code, HMAC-SHA256 keyed by the server secret so stored codes can not be
+// brute forced from a database copy, bound to the target it was sent to (ex: phone number)
+func Hash(secret []byte, target, code string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(target + ":" + code))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// LegacyHash unkeyed hash of codes stored before Hash was keyed, only used to accept recovery codes issued before
+func LegacyHash(target, code string) string {
+	sum := sha256.Sum256([]byte(target + ":" + code))
+	return hex.EncodeToString(sum[:])
+}
+
+// Verify compare the code against the stored hash in constant time
+func Verify(secret []byte, hash, target, code string) bool {
+	return hmac.Equal([]byte(hash), []byte(Hash(secret, target, code)))
+}
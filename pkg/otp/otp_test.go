@@ -0,0 +1,70 @@
+package otp
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"testing"
+)
+
+func TestGenerateCode(t *testing.T) {
+	digits := regexp.MustCompile(`^[0-9]{6}$`)
+	seen := map[string]bool{}
+	for i := 0; i < 100; i++ {
+		code, err := GenerateCode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !digits.MatchString(code) {
+			t.Fatalf("GenerateCode() = %q, want %d digits", code, CodeLength)
+		}
+		seen[code] = true
+	}
+
+	if len(seen) < 90 {
+		t.Errorf("GenerateCode() repeated codes, %d unique of 100", len(seen))
+	}
+}
+
+func TestHash(t *testing.T) {
+	secret := []byte("server-secret")
+	hash := Hash(secret, "+201012345678", "123456")
+
+	unkeyed := sha256.Sum256([]byte("+201012345678:123456"))
+	if hash == hex.EncodeToString(unkeyed[:]) {
+		t.Fatal("Hash is not keyed by the secret")
+	}
+	if hash != Hash(secret, "+201012345678", "123456") {
+		t.Fatal("Hash is not deterministic")
+	}
+
+	tests := []struct {
+		name   string
+		secret []byte
+		target string
+		code   string
+		valid  bool
+	}{
+		{"same code", secret, "+201012345678", "123456", true},
+		{"wrong code", secret, "+201012345678", "123457", false},
+		{"other target", secret, "+201112345678", "123456", false},
+		{"other secret", []byte("other-secret"), "+201012345678", "123456", false},
+		{"empty code", secret, "+201012345678", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Verify(tt.secret, hash, tt.target, tt.code); got != tt.valid {
+				t.Errorf("Verify() = %v, want %v", got, tt.valid)
+			}
+		})
+	}
+}
+
+func TestLegacyHash(t *testing.T) {
+	// hashes stored before keying, sha256 of "<target>:<code>"
+	want := sha256.Sum256([]byte("user-1:abcd2345"))
+	if got := LegacyHash("user-1", "abcd2345"); got != hex.EncodeToString(want[:]) {
+		t.Errorf("LegacyHash() = %s, want %x", got, want)
+	}
+}
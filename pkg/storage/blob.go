@@ -0,0 +1,33 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+)
+
+var ErrInvalidKey = errors.New("invalid blob key")
+
+// BlobStore keep binary objects (ex: images) by key, objects are private and downloaded using signed URLs
+type BlobStore interface {
+	Put(ctx context.Context, key, contentType string, data []byte) error
+	Delete(ctx context.Context, key string) error
+	// SignedURL return URL allowing anyone to download the object until ttl passes
+	SignedURL(key string, ttl time.Duration) (string, error)
+}
+
+// validKey accept relative slash separated keys without empty, "." or ".." segments
+func validKey(key string) bool {
+	if key == "" || strings.HasPrefix(key, "/") {
+		return false
+	}
+
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || segment == "." || segment == ".." || strings.ContainsAny(segment, "\\\x00") {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,124 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestLocalStore(t *testing.T) (*LocalStore, *httptest.Server) {
+	t.Helper()
+
+	store := &LocalStore{Dir: t.TempDir(), BaseURL: "/blobs", Secret: []byte("test-secret")}
+	mux := http.NewServeMux()
+	mux.Handle("/blobs/", http.StripPrefix("/blobs", store.Handler()))
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	if err := store.Put(context.Background(), "avatars/a/medium.jpg", "image/jpeg", []byte("image data")); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	return store, server
+}
+
+func get(t *testing.T, server *httptest.Server, link string) (int, string) {
+	t.Helper()
+
+	resp, err := http.Get(server.URL + link)
+	if err != nil {
+		t.Fatalf("GET %s: %v", link, err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(body)
+}
+
+func TestLocalStoreSignedURL(t *testing.T) {
+	store, server := newTestLocalStore(t)
+
+	link, err := store.SignedURL("avatars/a/medium.jpg", time.Minute)
+	if err != nil {
+		t.Fatalf("SignedURL: %v", err)
+	}
+
+	status, body := get(t, server, link)
+	if status != http.StatusOK || body != "image data" {
+		t.Fatalf("signed link = %d %q, want 200 %q", status, body, "image data")
+	}
+}
+
+func TestLocalStoreRejectsInvalidLinks(t *testing.T) {
+	store, server := newTestLocalStore(t)
+	if err := store.Put(context.Background(), "avatars/b/medium.jpg", "image/jpeg", []byte("other")); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	valid, _ := store.SignedURL("avatars/a/medium.jpg", time.Minute)
+	expired, _ := store.SignedURL("avatars/a/medium.jpg", -time.Minute)
+
+	parsed, _ := url.Parse(valid)
+	query := parsed.Query()
+
+	tampered := url.Values{"expires": {query.Get("expires")}, "signature": {strings.Repeat("0", 64)}}
+	extended := url.Values{"expires": {"9999999999"}, "signature": {query.Get("signature")}}
+
+	tests := []struct {
+		name string
+		link string
+	}{
+		{"expired", expired},
+		{"tampered signature", parsed.Path + "?" + tampered.Encode()},
+		{"extended expiry", parsed.Path + "?" + extended.Encode()},
+		{"other key", "/blobs/avatars/b/medium.jpg?" + parsed.RawQuery},
+		{"unsigned", parsed.Path},
+		{"other secret", func() string {
+			other := &LocalStore{BaseURL: "/blobs", Secret: []byte("other-secret")}
+			link, _ := other.SignedURL("avatars/a/medium.jpg", time.Minute)
+			return link
+		}()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if status, _ := get(t, server, tt.link); status != http.StatusForbidden {
+				t.Errorf("status = %d, want %d", status, http.StatusForbidden)
+			}
+		})
+	}
+}
+
+func TestLocalStoreInvalidKeys(t *testing.T) {
+	store := &LocalStore{Dir: t.TempDir(), BaseURL: "/blobs", Secret: []byte("test-secret")}
+
+	for _, key := range []string{"", "/etc/passwd", "../secret", "a/../../b", "a//b", "a/./b", `a\b`} {
+		if err := store.Put(context.Background(), key, "text/plain", []byte("x")); err != ErrInvalidKey {
+			t.Errorf("Put(%q) = %v, want ErrInvalidKey", key, err)
+		}
+		if _, err := store.SignedURL(key, time.Minute); err != ErrInvalidKey {
+			t.Errorf("SignedURL(%q) = %v, want ErrInvalidKey", key, err)
+		}
+	}
+}
+
+func TestLocalStoreDelete(t *testing.T) {
+	store, server := newTestLocalStore(t)
+	link, _ := store.SignedURL("avatars/a/medium.jpg", time.Minute)
+
+	if err := store.Delete(context.Background(), "avatars/a/medium.jpg"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	// deleting missing object is not an error
+	if err := store.Delete(context.Background(), "avatars/a/medium.jpg"); err != nil {
+		t.Fatalf("Delete missing: %v", err)
+	}
+
+	if status, _ := get(t, server, link); status != http.StatusNotFound {
+		t.Errorf("deleted object status = %d, want %d", status, http.StatusNotFound)
+	}
+}
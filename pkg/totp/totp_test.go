@@ -0,0 +1,113 @@
+package totp
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// secret of RFC 6238 appendix B test vectors, ASCII "12345678901234567890" in base32
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestCodeRFC6238(t *testing.T) {
+	// the RFC lists 8 digits codes, 6 digits codes are their last digits
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+
+	for _, tt := range tests {
+		got, err := Code(rfcSecret, Counter(time.Unix(tt.unix, 0)))
+		if err != nil {
+			t.Fatalf("Code at %d: %v", tt.unix, err)
+		}
+		if got != tt.want {
+			t.Errorf("Code at %d = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestCodeLowercaseSecret(t *testing.T) {
+	got, err := Code(strings.ToLower(rfcSecret), Counter(time.Unix(59, 0)))
+	if err != nil || got != "287082" {
+		t.Errorf("Code with lowercase secret = %s, %v, want 287082", got, err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	current := Counter(now)
+	code := func(counter int64) string {
+		c, _ := Code(rfcSecret, counter)
+		return c
+	}
+
+	tests := []struct {
+		name    string
+		secret  string
+		code    string
+		want    bool
+		counter int64
+	}{
+		{"current step", rfcSecret, code(current), true, current},
+		{"previous step within skew", rfcSecret, code(current - 1), true, current - 1},
+		{"next step within skew", rfcSecret, code(current + 1), true, current + 1},
+		{"expired step", rfcSecret, code(current - 2), false, 0},
+		{"future step", rfcSecret, code(current + 2), false, 0},
+		{"wrong code", rfcSecret, "000000", false, 0},
+		{"short code", rfcSecret, code(current)[:5], false, 0},
+		{"long code", rfcSecret, code(current) + "0", false, 0},
+		{"empty code", rfcSecret, "", false, 0},
+		{"invalid secret", "not base32!", code(current), false, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			counter, ok := Validate(tt.secret, tt.code, now)
+			if ok != tt.want || counter != tt.counter {
+				t.Errorf("Validate = %d, %v, want %d, %v", counter, ok, tt.counter, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	first, err := GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, _ := GenerateSecret()
+
+	// 160 bits are 32 base32 characters
+	if len(first) != 32 || first == second {
+		t.Errorf("GenerateSecret = %q and %q, want distinct 32 characters secrets", first, second)
+	}
+	if _, err := Code(first, 1); err != nil {
+		t.Errorf("generated secret is not valid base32: %v", err)
+	}
+}
+
+func TestProvisioningURI(t *testing.T) {
+	uri, err := url.Parse(ProvisioningURI(rfcSecret, "TexOrbit", "ali@example.com"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if uri.Scheme != "otpauth" || uri.Host != "totp" || uri.Path != "/TexOrbit:ali@example.com" {
+		t.Errorf("uri = %s", uri)
+	}
+	query := uri.Query()
+	for key, want := range map[string]string{"secret": rfcSecret, "issuer": "TexOrbit", "algorithm": "SHA1", "digits": "6", "period": "30"} {
+		if got := query.Get(key); got != want {
+			t.Errorf("%s = %q, want %q", key, got, want)
+		}
+	}
+}
@@ -0,0 +1,9 @@
+package util
+
+import "strings"
+
+// NormalizeEmail return the email address in the form stored in users.email, addresses are
+// compared case-insensitively so the same person can not get two accounts
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
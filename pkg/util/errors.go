@@ -0,0 +1,232 @@
+package util
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/bigusef/texorbit/pkg/i18n"
+	"github.com/go-playground/validator/v10"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// machine-readable error codes sent in the problem details "code" member
+const (
+	CodeBadRequest       = "bad_request"
+	CodeValidation       = "validation_failed"
+	CodeUnauthorized     = "unauthorized"
+	CodeForbidden        = "forbidden"
+	CodeNotFound         = "not_found"
+	CodeMethodNotAllowed = "method_not_allowed"
+	CodeConflict         = "conflict"
+	CodeAlreadyExists    = "already_exists"
+	CodeReferenced       = "referenced"
+	CodeTooManyRequests  = "too_many_requests"
+	CodeInternal         = "internal"
+	CodeUnavailable      = "unavailable"
+	CodePayloadTooLarge  = "payload_too_large"
+	CodeUnsupportedMedia = "unsupported_media_type"
+)
+
+// Error application error written to clients as RFC 7807 problem details
+type Error struct {
+	Status int
+	Code   string
+	// Detail explanation of this occurrence, the catalogue message of the code in the request language when empty
+	Detail string
+	// Fields validation error of every invalid field
+	Fields map[string]string
+	// Err cause of the error, logged and never sent to clients
+	Err error
+
+	// fieldErrors validator errors by their field in Fields, translated to the request language
+	fieldErrors map[string]validator.FieldError
+}
+
+func (e *Error) Error() string {
+	message := e.Detail
+	if message == "" {
+		message = e.Code
+	}
+	if e.Err != nil {
+		return message + ": " + e.Err.Error()
+	}
+	return message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// problem RFC 7807 problem details document with the code and fields extension members
+type problem struct {
+	Type   string            `json:"type"`
+	Title  string            `json:"title"`
+	Status int               `json:"status"`
+	Detail string            `json:"detail,omitempty"`
+	Code   string            `json:"code"`
+	Errors map[string]string `json:"errors,omitempty"`
+	// Messages human-readable error of every invalid field in the request language
+	Messages map[string]string `json:"messages,omitempty"`
+}
+
+func NewError(status int, code, detail string) *Error {
+	return &Error{Status: status, Code: code, Detail: detail}
+}
+
+func BadRequest(detail string) *Error {
+	return NewError(http.StatusBadRequest, CodeBadRequest, detail)
+}
+
+func Unauthorized(detail string) *Error {
+	return NewError(http.StatusUnauthorized, CodeUnauthorized, detail)
+}
+
+func Forbidden(detail string) *Error {
+	return NewError(http.StatusForbidden, CodeForbidden, detail)
+}
+
+func NotFound(detail string) *Error {
+	return NewError(http.StatusNotFound, CodeNotFound, detail)
+}
+
+func Conflict(detail string) *Error {
+	return NewError(http.StatusConflict, CodeConflict, detail)
+}
+
+func TooManyRequests(detail string) *Error {
+	return NewError(http.StatusTooManyRequests, CodeTooManyRequests, detail)
+}
+
+// Internal unexpected error, the cause is logged and clients get a generic message
+func Internal(err error) *Error {
+	return &Error{Status: http.StatusInternalServerError, Code: CodeInternal, Err: err}
+}
+
+// Invalid validation error of the given fields, the field value is the failed rule (ex: required, email)
+func Invalid(fields map[string]string) *Error {
+	return &Error{Status: http.StatusBadRequest, Code: CodeValidation, Fields: fields}
+}
+
+// ValidationFailed convert validator errors to validation error
+func ValidationFailed(err error) *Error {
+	var errs validator.ValidationErrors
+	if !errors.As(err, &errs) {
+		return BadRequest(err.Error())
+	}
+
+	appErr := Invalid(make(map[string]string, len(errs)))
+	appErr.fieldErrors = make(map[string]validator.FieldError, len(errs))
+	for _, fieldErr := range errs {
+		appErr.Fields[fieldErr.Field()] = fieldErr.Tag()
+		appErr.fieldErrors[fieldErr.Field()] = fieldErr
+	}
+	return appErr
+}
+
+// Merge add the field errors of the other validation error renamed by field (ex: fee is features.0.fee),
+// the validator errors keep their messages under the new names
+func (e *Error) Merge(other *Error, field func(name string) string) {
+	if e.Fields == nil {
+		e.Fields = make(map[string]string, len(other.Fields))
+	}
+	for name, tag := range other.Fields {
+		e.Fields[field(name)] = tag
+	}
+
+	for name, fieldErr := range other.fieldErrors {
+		if e.fieldErrors == nil {
+			e.fieldErrors = make(map[string]validator.FieldError, len(other.fieldErrors))
+		}
+		e.fieldErrors[field(name)] = fieldErr
+	}
+}
+
+// AsError convert any error to application error, pgx.ErrNoRows becomes not found,
+// postgres constraint violations become conflict or validation errors and the rest are internal errors
+func AsError(err error) *Error {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return &Error{Status: http.StatusNotFound, Code: CodeNotFound, Err: err}
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case "23505": // unique_violation
+			field := constraintField(pgErr.TableName, pgErr.ConstraintName, "_key", "_idx")
+			return &Error{
+				Status: http.StatusConflict,
+				Code:   CodeAlreadyExists,
+				Fields: map[string]string{field: "unique"},
+				Err:    err,
+			}
+		case "23503": // foreign_key_violation
+			// deleting or updating row still used by other rows
+			if strings.Contains(pgErr.Detail, "is still referenced") {
+				return &Error{Status: http.StatusConflict, Code: CodeReferenced, Err: err}
+			}
+			field := constraintField(pgErr.TableName, pgErr.ConstraintName, "_fkey")
+			return &Error{
+				Status: http.StatusBadRequest,
+				Code:   CodeValidation,
+				Fields: map[string]string{field: "exists"},
+				Err:    err,
+			}
+		case "23502": // not_null_violation
+			return &Error{
+				Status: http.StatusBadRequest,
+				Code:   CodeValidation,
+				Fields: map[string]string{pgErr.ColumnName: "required"},
+				Err:    err,
+			}
+		case "23514", "22001", "22P02": // check_violation, string_data_right_truncation, invalid_text_representation
+			return &Error{Status: http.StatusBadRequest, Code: CodeValidation, Err: err}
+		}
+	}
+
+	return Internal(err)
+}
+
+// constraintField return the column of postgres default constraint names (ex: users_email_key is email)
+func constraintField(table, constraint string, suffixes ...string) string {
+	field := strings.TrimPrefix(constraint, table+"_")
+	for _, suffix := range suffixes {
+		field = strings.TrimSuffix(field, suffix)
+	}
+	return field
+}
+
+// ErrorResponseWriter write the error as application/problem+json, errors other than *Error are converted by AsError
+// and field errors get messages in the request language
+func ErrorResponseWriter(w http.ResponseWriter, r *http.Request, err error) {
+	appErr := AsError(err)
+	if appErr.Status >= http.StatusInternalServerError && appErr.Err != nil {
+		slog.Error("request failed", "status", appErr.Status, "error", appErr.Err)
+	}
+
+	detail := appErr.Detail
+	if detail == "" && i18n.Has("error."+appErr.Code) {
+		detail = i18n.T(i18n.FromContext(r.Context()), "error."+appErr.Code)
+	}
+
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(appErr.Status)
+
+	_ = json.NewEncoder(w).Encode(problem{
+		Type:     "about:blank",
+		Title:    http.StatusText(appErr.Status),
+		Status:   appErr.Status,
+		Detail:   detail,
+		Code:     appErr.Code,
+		Errors:   appErr.Fields,
+		Messages: fieldMessages(r, appErr),
+	})
+}
@@ -0,0 +1,125 @@
+package util
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/bigusef/texorbit/pkg/i18n"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestAsError(t *testing.T) {
+	conflict := Conflict("addresses limit reached")
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+		wantFields map[string]string
+	}{
+		{"application error kept", conflict, http.StatusConflict, CodeConflict, nil},
+		{"wrapped application error", fmt.Errorf("create address: %w", conflict), http.StatusConflict, CodeConflict, nil},
+		{"no rows", pgx.ErrNoRows, http.StatusNotFound, CodeNotFound, nil},
+		{"wrapped no rows", fmt.Errorf("get city: %w", pgx.ErrNoRows), http.StatusNotFound, CodeNotFound, nil},
+		{
+			"unique violation",
+			&pgconn.PgError{Code: "23505", TableName: "users", ConstraintName: "users_email_key"},
+			http.StatusConflict, CodeAlreadyExists, map[string]string{"email": "unique"},
+		},
+		{
+			"unique index violation",
+			&pgconn.PgError{Code: "23505", TableName: "users", ConstraintName: "users_phone_number_idx"},
+			http.StatusConflict, CodeAlreadyExists, map[string]string{"phone_number": "unique"},
+		},
+		{
+			"missing referenced row",
+			&pgconn.PgError{Code: "23503", TableName: "user_addresses", ConstraintName: "user_addresses_city_id_fkey", Detail: `Key (city_id)=(999) is not present in table "cities".`},
+			http.StatusBadRequest, CodeValidation, map[string]string{"city_id": "exists"},
+		},
+		{
+			"row still referenced",
+			&pgconn.PgError{Code: "23503", TableName: "user_addresses", ConstraintName: "user_addresses_city_id_fkey", Detail: `Key (id)=(7) is still referenced from table "user_addresses".`},
+			http.StatusConflict, CodeReferenced, nil,
+		},
+		{"not null violation", &pgconn.PgError{Code: "23502", TableName: "users", ColumnName: "name"}, http.StatusBadRequest, CodeValidation, map[string]string{"name": "required"}},
+		{"check violation", &pgconn.PgError{Code: "23514", ConstraintName: "delivery_zones_fee_check"}, http.StatusBadRequest, CodeValidation, nil},
+		{"value too long", &pgconn.PgError{Code: "22001"}, http.StatusBadRequest, CodeValidation, nil},
+		{"invalid text representation", &pgconn.PgError{Code: "22P02"}, http.StatusBadRequest, CodeValidation, nil},
+		{"other postgres error", &pgconn.PgError{Code: "40001"}, http.StatusInternalServerError, CodeInternal, nil},
+		{"unexpected error", errors.New("connection refused"), http.StatusInternalServerError, CodeInternal, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := AsError(tt.err)
+			if got.Status != tt.wantStatus || got.Code != tt.wantCode {
+				t.Errorf("AsError() = %d %s, want %d %s", got.Status, got.Code, tt.wantStatus, tt.wantCode)
+			}
+			if !reflect.DeepEqual(got.Fields, tt.wantFields) {
+				t.Errorf("fields = %v, want %v", got.Fields, tt.wantFields)
+			}
+			if got != conflict && !errors.Is(got, tt.err) {
+				t.Errorf("AsError() does not wrap %v", tt.err)
+			}
+		})
+	}
+}
+
+func TestErrorResponseWriter(t *testing.T) {
+	tests := []struct {
+		name       string
+		locale     string
+		err        error
+		wantStatus int
+		wantDetail string
+		wantCode   string
+	}{
+		{"detail sent as is", i18n.English, NotFound("City not found"), http.StatusNotFound, "City not found", CodeNotFound},
+		{"catalogue message of the code", i18n.English, pgx.ErrNoRows, http.StatusNotFound, "resource not found", CodeNotFound},
+		{"catalogue message in arabic", i18n.Arabic, pgx.ErrNoRows, http.StatusNotFound, "العنصر المطلوب غير موجود", CodeNotFound},
+		{"internal cause hidden", i18n.English, errors.New("password authentication failed for user"), http.StatusInternalServerError, "internal server error", CodeInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r = r.WithContext(i18n.WithLocale(r.Context(), tt.locale))
+			w := httptest.NewRecorder()
+			ErrorResponseWriter(w, r, tt.err)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if contentType := w.Header().Get("Content-Type"); contentType != "application/problem+json" {
+				t.Errorf("content type = %q", contentType)
+			}
+
+			var got problem
+			if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+				t.Fatal(err)
+			}
+			if got.Status != tt.wantStatus || got.Code != tt.wantCode || got.Detail != tt.wantDetail || got.Title != http.StatusText(tt.wantStatus) {
+				t.Errorf("problem = %+v, want %d %s %q", got, tt.wantStatus, tt.wantCode, tt.wantDetail)
+			}
+		})
+	}
+}
+
+func TestErrorResponseWriterFields(t *testing.T) {
+	w := httptest.NewRecorder()
+	ErrorResponseWriter(w, httptest.NewRequest(http.MethodPost, "/", nil), &pgconn.PgError{Code: "23505", TableName: "users", ConstraintName: "users_email_key"})
+
+	var got problem
+	if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if w.Code != http.StatusConflict || got.Errors["email"] != "unique" {
+		t.Errorf("problem = %d %+v, want conflict on email", w.Code, got)
+	}
+}
@@ -0,0 +1,38 @@
+package util
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/jackc/pgx/v5"
+)
+
+var errEmptyPlan = errors.New("estimate: query plan has no rows estimate")
+
+// RowQuerier run single row queries, implemented by connection pools, connections and transactions
+type RowQuerier interface {
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+}
+
+// EstimateRows return the planner estimate of the rows of the query, an EXPLAIN (FORMAT JSON) statement.
+// it reads table statistics instead of scanning the rows so it is fast on large tables but may be stale
+func EstimateRows(ctx context.Context, conn RowQuerier, explain string, args ...interface{}) (int64, error) {
+	var raw []byte
+	if err := conn.QueryRow(ctx, explain, args...).Scan(&raw); err != nil {
+		return 0, err
+	}
+
+	var plans []struct {
+		Plan *struct {
+			Rows float64 `json:"Plan Rows"`
+		} `json:"Plan"`
+	}
+	if err := json.Unmarshal(raw, &plans); err != nil {
+		return 0, err
+	}
+	if len(plans) == 0 || plans[0].Plan == nil {
+		return 0, errEmptyPlan
+	}
+
+	return int64(plans[0].Plan.Rows), nil
+}
@@ -0,0 +1,65 @@
+package util
+
+import (
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"testing"
+)
+
+// planRow row of EXPLAIN (FORMAT JSON) result
+type planRow struct {
+	plan string
+	err  error
+}
+
+func (r planRow) Scan(dest ...interface{}) error {
+	if r.err != nil {
+		return r.err
+	}
+	*dest[0].(*[]byte) = []byte(r.plan)
+	return nil
+}
+
+type planQuerier struct {
+	row  planRow
+	sql  string
+	args []interface{}
+}
+
+func (q *planQuerier) QueryRow(_ context.Context, sql string, args ...interface{}) pgx.Row {
+	q.sql, q.args = sql, args
+	return q.row
+}
+
+func TestEstimateRows(t *testing.T) {
+	failed := errors.New("connection refused")
+
+	tests := []struct {
+		name string
+		row  planRow
+		want int64
+		err  bool
+	}{
+		{"estimate", planRow{plan: `[{"Plan": {"Node Type": "Seq Scan", "Plan Rows": 1250, "Total Cost": 22.5}}]`}, 1250, false},
+		{"fractional estimate", planRow{plan: `[{"Plan": {"Plan Rows": 7.6}}]`}, 7, false},
+		{"zero rows", planRow{plan: `[{"Plan": {"Plan Rows": 0}}]`}, 0, false},
+		{"empty plan list", planRow{plan: `[]`}, 0, true},
+		{"missing plan", planRow{plan: `[{}]`}, 0, true},
+		{"invalid json", planRow{plan: `Seq Scan on users`}, 0, true},
+		{"query error", planRow{err: failed}, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn := &planQuerier{row: tt.row}
+			got, err := EstimateRows(context.Background(), conn, "EXPLAIN (FORMAT JSON) SELECT 1 FROM users WHERE id = $1", 7)
+			if (err != nil) != tt.err || got != tt.want {
+				t.Fatalf("EstimateRows = %d, %v, want %d (error %v)", got, err, tt.want, tt.err)
+			}
+			if len(conn.args) != 1 || conn.args[0] != 7 {
+				t.Errorf("query args = %v, want [7]", conn.args)
+			}
+		})
+	}
+}
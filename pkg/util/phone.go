@@ -0,0 +1,44 @@
+package util
+
+import (
+	"errors"
+	"strings"
+)
+
+var ErrInvalidPhoneNumber = errors.New("invalid egyptian mobile number")
+
+// NormalizePhone convert egyptian mobile number written in any common local or international format
+// (01012345678, 201012345678, 00201012345678, +20 101 234 5678) to E.164 format (+201012345678)
+func NormalizePhone(number string) (string, error) {
+	replacer := strings.NewReplacer(" ", "", "-", "", "(", "", ")", "", ".", "")
+	number = replacer.Replace(strings.TrimSpace(number))
+
+	switch {
+	case strings.HasPrefix(number, "+20"):
+		number = number[3:]
+	case strings.HasPrefix(number, "0020"):
+		number = number[4:]
+	case strings.HasPrefix(number, "20") && len(number) == 12:
+		number = number[2:]
+	case strings.HasPrefix(number, "0"):
+		number = number[1:]
+	}
+
+	// national mobile number is 10 digits starting with operator prefix 10, 11, 12 or 15
+	if len(number) != 10 {
+		return "", ErrInvalidPhoneNumber
+	}
+
+	for _, c := range number {
+		if c < '0' || c > '9' {
+			return "", ErrInvalidPhoneNumber
+		}
+	}
+
+	switch number[:2] {
+	case "10", "11", "12", "15":
+		return "+20" + number, nil
+	default:
+		return "", ErrInvalidPhoneNumber
+	}
+}
@@ -0,0 +1,54 @@
+package util
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNormalizePhone(t *testing.T) {
+	tests := []struct {
+		number string
+		want   string
+	}{
+		{"01012345678", "+201012345678"},
+		{"01112345678", "+201112345678"},
+		{"01212345678", "+201212345678"},
+		{"01512345678", "+201512345678"},
+		{"201012345678", "+201012345678"},
+		{"00201012345678", "+201012345678"},
+		{"+201012345678", "+201012345678"},
+		{"+20 101 234 5678", "+201012345678"},
+		{" 010-1234-5678 ", "+201012345678"},
+		{"(010) 1234.5678", "+201012345678"},
+		{"1012345678", "+201012345678"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.number, func(t *testing.T) {
+			got, err := NormalizePhone(tt.number)
+			if err != nil || got != tt.want {
+				t.Errorf("NormalizePhone(%q) = %q, %v, want %q", tt.number, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizePhoneInvalid(t *testing.T) {
+	for _, number := range []string{
+		"",
+		"0101234567",     // short
+		"010123456789",   // long
+		"01312345678",    // unknown operator
+		"0221234567",     // landline
+		"+441012345678",  // other country
+		"0101234567a",    // letters
+		"+20101234567",   // short international
+		"+2001012345678", // trunk zero after country code
+	} {
+		t.Run(number, func(t *testing.T) {
+			if got, err := NormalizePhone(number); !errors.Is(err, ErrInvalidPhoneNumber) {
+				t.Errorf("NormalizePhone(%q) = %q, %v, want ErrInvalidPhoneNumber", number, got, err)
+			}
+		})
+	}
+}
@@ -22,3 +22,14 @@ func JsonListResponseWriter(w http.ResponseWriter, code int, payload interface{}
 
 	JsonResponseWriter(w, code, response)
 }
+
+// JsonPageResponseWriter works like JsonListResponseWriter for lists that may skip the total count,
+// the count is omitted when it is nil
+func JsonPageResponseWriter(w http.ResponseWriter, code int, payload interface{}, count *int64) {
+	response := struct {
+		Result interface{} `json:"result"`
+		Count  *int64      `json:"count,omitempty"`
+	}{payload, count}
+
+	JsonResponseWriter(w, code, response)
+}
@@ -0,0 +1,12 @@
+package util
+
+import "strings"
+
+// likeEscaper escape LIKE wildcards with postgres default escape character
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// ContainsPattern return LIKE pattern matching values containing the text, wildcards
+// in the text (ex: "100%") match themselves
+func ContainsPattern(text string) string {
+	return "%" + likeEscaper.Replace(text) + "%"
+}
@@ -0,0 +1,68 @@
+package util
+
+import (
+	"github.com/bigusef/texorbit/pkg/i18n"
+	ut "github.com/go-playground/universal-translator"
+	"net/http"
+	"strings"
+)
+
+// translator of validation messages, errors are sent without messages until RegisterTranslator is called
+var translator *ut.UniversalTranslator
+
+// RegisterTranslator set the translator of validation messages, its locales must have
+// "rule.<tag>" messages of the field errors used without the validator and may have
+// "field.<name>" labels replacing the json field names in the messages
+func RegisterTranslator(uni *ut.UniversalTranslator) {
+	translator = uni
+}
+
+// requestTranslator select the translator of the first registered locale of the request locale chain,
+// the fallback locale when none is registered
+func requestTranslator(r *http.Request) (ut.Translator, bool) {
+	if translator == nil {
+		return nil, false
+	}
+
+	trans, _ := translator.FindTranslator(i18n.Chain(i18n.FromContext(r.Context()))...)
+	return trans, true
+}
+
+// fieldMessages human-readable message of every invalid field in the request language
+func fieldMessages(r *http.Request, appErr *Error) map[string]string {
+	if len(appErr.Fields) == 0 {
+		return nil
+	}
+	trans, ok := requestTranslator(r)
+	if !ok {
+		return nil
+	}
+
+	messages := make(map[string]string, len(appErr.Fields))
+	for field, fieldErr := range appErr.fieldErrors {
+		messages[field] = strings.Replace(fieldErr.Translate(trans), fieldErr.Field(), fieldLabel(trans, field), 1)
+	}
+	for field, tag := range appErr.Fields {
+		if _, ok := messages[field]; ok {
+			continue
+		}
+		if message, err := trans.T("rule."+tag, fieldLabel(trans, field)); err == nil {
+			messages[field] = message
+		}
+	}
+
+	return messages
+}
+
+// fieldLabel label of the field, nested fields (ex: features.0.fee) use the label of their last name
+func fieldLabel(trans ut.Translator, field string) string {
+	if label, err := trans.T("field." + field); err == nil {
+		return label
+	}
+	if i := strings.LastIndex(field, "."); i >= 0 {
+		if label, err := trans.T("field." + field[i+1:]); err == nil {
+			return label
+		}
+	}
+	return field
+}
@@ -1,397 +1,572 @@
-INSERT INTO cities (name_ar, name_en, is_active)
-VALUES ('15 مايو', '15 May', false),
-       ('الازبكية', 'Al Azbakeyah', false),
-       ('البساتين', 'Al Basatin', false),
-       ('التبين', 'Tebin', false),
-       ('الخليفة', 'El-Khalifa', false),
-       ('الدراسة', 'El darrasa', false),
-       ('الدرب الاحمر', 'Aldarb Alahmar', false),
-       ('الزاوية الحمراء', 'Zawya al-Hamra', false),
-       ('الزيتون', 'El-Zaytoun', false),
-       ('الساحل', 'Sahel', false),
-       ('السلام', 'El Salam', false),
-       ('السيدة زينب', 'Sayeda Zeinab', false),
-       ('الشرابية', 'El Sharabeya', false),
-       ('مدينة الشروق', 'Shorouk', false),
-       ('الظاهر', 'El Daher', false),
-       ('العتبة', 'Ataba', false),
-       ('القاهرة الجديدة', 'New Cairo', false),
-       ('المرج', 'El Marg', false),
-       ('عزبة النخل', 'Ezbet el Nakhl', false),
-       ('المطرية', 'Matareya', false),
-       ('المعادى', 'Maadi', false),
-       ('المعصرة', 'Maasara', false),
-       ('المقطم', 'Mokattam', false),
-       ('المنيل', 'Manyal', false),
-       ('الموسكى', 'Mosky', false),
-       ('النزهة', 'Nozha', false),
-       ('الوايلى', 'Waily', false),
-       ('باب الشعرية', 'Bab al-Shereia', false),
-       ('بولاق', 'Bolaq', false),
-       ('جاردن سيتى', 'Garden City', false),
-       ('حدائق القبة', 'Hadayek El-Kobba', false),
-       ('حلوان', 'Helwan', false),
-       ('دار السلام', 'Dar Al Salam', false),
-       ('شبرا', 'Shubra', false),
-       ('طره', 'Tura', false),
-       ('عابدين', 'Abdeen', false),
-       ('عباسية', 'Abaseya', false),
-       ('عين شمس', 'Ain Shams', false),
-       ('مدينة نصر', 'Nasr City', false),
-       ('مصر الجديدة', 'New Heliopolis', false),
-       ('مصر القديمة', 'Masr Al Qadima', false),
-       ('منشية ناصر', 'Mansheya Nasir', false),
-       ('مدينة بدر', 'Badr City', false),
-       ('مدينة العبور', 'Obour City', false),
-       ('وسط البلد', 'Cairo Downtown', false),
-       ('الزمالك', 'Zamalek', false),
-       ('قصر النيل', 'Kasr El Nile', false),
-       ('الرحاب', 'Rehab', false),
-       ('القطامية', 'Katameya', false),
-       ('مدينتي', 'Madinty', false),
-       ('روض الفرج', 'Rod Alfarag', false),
-       ('شيراتون', 'Sheraton', false),
-       ('الجمالية', 'El-Gamaleya', false),
-       ('العاشر من رمضان', '10th of Ramadan City', false),
-       ('الحلمية', 'Helmeyat Alzaytoun', false),
-       ('النزهة الجديدة', 'New Nozha', false),
-       ('العاصمة الإدارية', 'Capital New', false),
-       ('الجيزة', 'Giza', false),
-       ('السادس من أكتوبر', 'Sixth of October', false),
-       ('الشيخ زايد', 'Cheikh Zayed', false),
-       ('الحوامدية', 'Hawamdiyah', false),
-       ('البدرشين', 'Al Badrasheen', false),
-       ('الصف', 'Saf', false),
-       ('أطفيح', 'Atfih', false),
-       ('العياط', 'Al Ayat', false),
-       ('الباويطي', 'Al-Bawaiti', false),
-       ('منشأة القناطر', 'ManshiyetAl Qanater', false),
-       ('أوسيم', 'Oaseem', false),
-       ('كرداسة', 'Kerdasa', false),
-       ('أبو النمرس', 'Abu Nomros', false),
-       ('كفر غطاطي', 'Kafr Ghati', false),
-       ('منشأة البكاري', 'Manshiyet Al Bakari', false),
-       ('الدقى', 'Dokki', false),
-       ('العجوزة', 'Agouza', false),
-       ('الهرم', 'Haram', false),
-       ('الوراق', 'Warraq', false),
-       ('امبابة', 'Imbaba', false),
-       ('بولاق الدكرور', 'Boulaq Dakrour', false),
-       ('الواحات البحرية', 'Al Wahat Al Baharia', false),
-       ('العمرانية', 'Omraneya', false),
-       ('المنيب', 'Moneeb', false),
-       ('بين السرايات', 'Bin Alsarayat', false),
-       ('الكيت كات', 'Kit Kat', false),
-       ('المهندسين', 'Mohandessin', false),
-       ('فيصل', 'Faisal', false),
-       ('أبو رواش', 'Abu Rawash', false),
-       ('حدائق الأهرام', 'Hadayek Alahram', false),
-       ('الحرانية', 'Haraneya', false),
-       ('حدائق اكتوبر', 'Hadayek October', false),
-       ('صفط اللبن', 'Saft Allaban', false),
-       ('القرية الذكية', 'Smart Village', false),
-       ('ارض اللواء', 'Ard Ellwaa', false),
-       ('ابو قير', 'Abu Qir', false),
-       ('الابراهيمية', 'Al Ibrahimeyah', false),
-       ('الأزاريطة', 'Azarita', false),
-       ('الانفوشى', 'Anfoushi', false),
-       ('الدخيلة', 'Dekheila', false),
-       ('السيوف', 'El Soyof', false),
-       ('العامرية', 'Ameria', false),
-       ('اللبان', 'El Labban', false),
-       ('المفروزة', 'Al Mafrouza', false),
-       ('المنتزه', 'El Montaza', false),
-       ('المنشية', 'Mansheya', false),
-       ('الناصرية', 'Naseria', false),
-       ('امبروزو', 'Ambrozo', false),
-       ('باب شرق', 'Bab Sharq', false),
-       ('برج العرب', 'Bourj Alarab', false),
-       ('ستانلى', 'Stanley', false),
-       ('سموحة', 'Smouha', false),
-       ('سيدى بشر', 'Sidi Bishr', false),
-       ('شدس', 'Shads', false),
-       ('غيط العنب', 'Gheet Alenab', false),
-       ('فلمينج', 'Fleming', false),
-       ('فيكتوريا', 'Victoria', false),
-       ('كامب شيزار', 'Camp Shizar', false),
-       ('كرموز', 'Karmooz', false),
-       ('محطة الرمل', 'Mahta Alraml', false),
-       ('مينا البصل', 'Mina El-Basal', false),
-       ('العصافرة', 'Asafra', false),
-       ('العجمي', 'Agamy', false),
-       ('بكوس', 'Bakos', false),
-       ('بولكلي', 'Boulkly', false),
-       ('كليوباترا', 'Cleopatra', false),
-       ('جليم', 'Glim', false),
-       ('المعمورة', 'Al Mamurah', false),
-       ('المندرة', 'Al Mandara', false),
-       ('محرم بك', 'Moharam Bek', false),
-       ('الشاطبي', 'Elshatby', false),
-       ('سيدي جابر', 'Sidi Gaber', false),
-       ('الساحل الشمالي', 'North Coast/sahel', false),
-       ('الحضرة', 'Alhadra', false),
-       ('العطارين', 'Alattarin', false),
-       ('سيدي كرير', 'Sidi Kerir', false),
-       ('الجمرك', 'Elgomrok', false),
-       ('المكس', 'Al Max', false),
-       ('مارينا', 'Marina', false),
-       ('المنصورة', 'Mansoura', false),
-       ('طلخا', 'Talkha', false),
-       ('ميت غمر', 'Mitt Ghamr', false),
-       ('دكرنس', 'Dekernes', false),
-       ('أجا', 'Aga', false),
-       ('منية النصر', 'Menia El Nasr', false),
-       ('السنبلاوين', 'Sinbillawin', false),
-       ('الكردي', 'El Kurdi', false),
-       ('بني عبيد', 'Bani Ubaid', false),
-       ('المنزلة', 'Al Manzala', false),
-       ('تمي الأمديد', 'tami alamdid', false),
-       ('الجمالية', 'aljamalia', false),
-       ('شربين', 'Sherbin', false),
-       ('المطرية', 'Mataria', false),
-       ('بلقاس', 'Belqas', false),
-       ('ميت سلسيل', 'Meet Salsil', false),
-       ('جمصة', 'Gamasa', false),
-       ('محلة دمنة', 'Mahalat Damana', false),
-       ('نبروه', 'Nabroh', false),
-       ('الغردقة', 'Hurghada', false),
-       ('رأس غارب', 'Ras Ghareb', false),
-       ('سفاجا', 'Safaga', false),
-       ('القصير', 'El Qusiar', false),
-       ('مرسى علم', 'Marsa Alam', false),
-       ('الشلاتين', 'Shalatin', false),
-       ('حلايب', 'Halaib', false),
-       ('الدهار', 'Aldahar', false),
-       ('دمنهور', 'Damanhour', false),
-       ('كفر الدوار', 'Kafr El Dawar', false),
-       ('رشيد', 'Rashid', false),
-       ('إدكو', 'Edco', false),
-       ('أبو المطامير', 'Abu al-Matamir', false),
-       ('أبو حمص', 'Abu Homs', false),
-       ('الدلنجات', 'Delengat', false),
-       ('المحمودية', 'Mahmoudiyah', false),
-       ('الرحمانية', 'Rahmaniyah', false),
-       ('إيتاي البارود', 'Itai Baroud', false),
-       ('حوش عيسى', 'Housh Eissa', false),
-       ('شبراخيت', 'Shubrakhit', false),
-       ('كوم حمادة', 'Kom Hamada', false),
-       ('بدر', 'Badr', false),
-       ('وادي النطرون', 'Wadi Natrun', false),
-       ('النوبارية الجديدة', 'New Nubaria', false),
-       ('النوبارية', 'Alnoubareya', false),
-       ('الفيوم', 'Fayoum', false),
-       ('الفيوم الجديدة', 'Fayoum El Gedida', false),
-       ('طامية', 'Tamiya', false),
-       ('سنورس', 'Snores', false),
-       ('إطسا', 'Etsa', false),
-       ('إبشواي', 'Epschway', false),
-       ('يوسف الصديق', 'Yusuf El Sediaq', false),
-       ('الحادقة', 'Hadqa', false),
-       ('اطسا', 'Atsa', false),
-       ('الجامعة', 'Algamaa', false),
-       ('السيالة', 'Sayala', false),
-       ('طنطا', 'Tanta', false),
-       ('المحلة الكبرى', 'Al Mahalla Al Kobra', false),
-       ('كفر الزيات', 'Kafr El Zayat', false),
-       ('زفتى', 'Zefta', false),
-       ('السنطة', 'El Santa', false),
-       ('قطور', 'Qutour', false),
-       ('بسيون', 'Basion', false),
-       ('سمنود', 'Samannoud', false),
-       ('الإسماعيلية', 'Ismailia', false),
-       ('فايد', 'Fayed', false),
-       ('القنطرة شرق', 'Qantara Sharq', false),
-       ('القنطرة غرب', 'Qantara Gharb', false),
-       ('التل الكبير', 'El Tal El Kabier', false),
-       ('أبو صوير', 'Abu Sawir', false),
-       ('القصاصين الجديدة', 'Kasasien El Gedida', false),
-       ('نفيشة', 'Nefesha', false),
-       ('الشيخ زايد', 'Sheikh Zayed', false),
-       ('شبين الكوم', 'Shbeen El Koom', false),
-       ('مدينة السادات', 'Sadat City', false),
-       ('منوف', 'Menouf', false),
-       ('سرس الليان', 'Sars El-Layan', false),
-       ('أشمون', 'Ashmon', false),
-       ('الباجور', 'Al Bagor', false),
-       ('قويسنا', 'Quesna', false),
-       ('بركة السبع', 'Berkat El Saba', false),
-       ('تلا', 'Tala', false),
-       ('الشهداء', 'Al Shohada', false),
-       ('المنيا', 'Minya', false),
-       ('المنيا الجديدة', 'Minya El Gedida', false),
-       ('العدوة', 'El Adwa', false),
-       ('مغاغة', 'Magagha', false),
-       ('بني مزار', 'Bani Mazar', false),
-       ('مطاي', 'Mattay', false),
-       ('سمالوط', 'Samalut', false),
-       ('المدينة الفكرية', 'Madinat El Fekria', false),
-       ('ملوي', 'Meloy', false),
-       ('دير مواس', 'Deir Mawas', false),
-       ('ابو قرقاص', 'Abu Qurqas', false),
-       ('ارض سلطان', 'Ard Sultan', false),
-       ('بنها', 'Banha', false),
-       ('قليوب', 'Qalyub', false),
-       ('شبرا الخيمة', 'Shubra Al Khaimah', false),
-       ('القناطر الخيرية', 'Al Qanater Charity', false),
-       ('الخانكة', 'Khanka', false),
-       ('كفر شكر', 'Kafr Shukr', false),
-       ('طوخ', 'Tukh', false),
-       ('قها', 'Qaha', false),
-       ('العبور', 'Obour', false),
-       ('الخصوص', 'Khosous', false),
-       ('شبين القناطر', 'Shibin Al Qanater', false),
-       ('مسطرد', 'Mostorod', false),
-       ('الخارجة', 'El Kharga', false),
-       ('باريس', 'Paris', false),
-       ('موط', 'Mout', false),
-       ('الفرافرة', 'Farafra', false),
-       ('بلاط', 'Balat', false),
-       ('الداخلة', 'Dakhla', false),
-       ('السويس', 'Suez', false),
-       ('الجناين', 'Alganayen', false),
-       ('عتاقة', 'Ataqah', false),
-       ('العين السخنة', 'Ain Sokhna', false),
-       ('فيصل', 'Faysal', false),
-       ('أسوان', 'Aswan', false),
-       ('أسوان الجديدة', 'Aswan El Gedida', false),
-       ('دراو', 'Drau', false),
-       ('كوم أمبو', 'Kom Ombo', false),
-       ('نصر النوبة', 'Nasr Al Nuba', false),
-       ('كلابشة', 'Kalabsha', false),
-       ('إدفو', 'Edfu', false),
-       ('الرديسية', 'Al-Radisiyah', false),
-       ('البصيلية', 'Al Basilia', false),
-       ('السباعية', 'Al Sibaeia', false),
-       ('ابوسمبل السياحية', 'Abo Simbl Al Siyahia', false),
-       ('مرسى علم', 'Marsa Alam', false),
-       ('أسيوط', 'Assiut', false),
-       ('أسيوط الجديدة', 'Assiut El Gedida', false),
-       ('ديروط', 'Dayrout', false),
-       ('منفلوط', 'Manfalut', false),
-       ('القوصية', 'Qusiya', false),
-       ('أبنوب', 'Abnoub', false),
-       ('أبو تيج', 'Abu Tig', false),
-       ('الغنايم', 'El Ghanaim', false),
-       ('ساحل سليم', 'Sahel Selim', false),
-       ('البداري', 'El Badari', false),
-       ('صدفا', 'Sidfa', false),
-       ('بني سويف', 'Bani Sweif', false),
-       ('بني سويف الجديدة', 'Beni Suef El Gedida', false),
-       ('الواسطى', 'Al Wasta', false),
-       ('ناصر', 'Naser', false),
-       ('إهناسيا', 'Ehnasia', false),
-       ('ببا', 'beba', false),
-       ('الفشن', 'Fashn', false),
-       ('سمسطا', 'Somasta', false),
-       ('الاباصيرى', 'Alabbaseri', false),
-       ('مقبل', 'Mokbel', false),
-       ('بورسعيد', 'PorSaid', false),
-       ('بورفؤاد', 'Port Fouad', false),
-       ('العرب', 'Alarab', false),
-       ('حى الزهور', 'Zohour', false),
-       ('حى الشرق', 'Alsharq', false),
-       ('حى الضواحى', 'Aldawahi', false),
-       ('حى المناخ', 'Almanakh', false),
-       ('حى مبارك', 'Mubarak', false),
-       ('دمياط', 'Damietta', false),
-       ('دمياط الجديدة', 'New Damietta', false),
-       ('رأس البر', 'Ras El Bar', false),
-       ('فارسكور', 'Faraskour', false),
-       ('الزرقا', 'Zarqa', false),
-       ('السرو', 'alsaru', false),
-       ('الروضة', 'alruwda', false),
-       ('كفر البطيخ', 'Kafr El-Batikh', false),
-       ('عزبة البرج', 'Azbet Al Burg', false),
-       ('ميت أبو غالب', 'Meet Abou Ghalib', false),
-       ('كفر سعد', 'Kafr Saad', false),
-       ('الزقازيق', 'Zagazig', false),
-       ('العاشر من رمضان', 'Al Ashr Men Ramadan', false),
-       ('منيا القمح', 'Minya Al Qamh', false),
-       ('بلبيس', 'Belbeis', false),
-       ('مشتول السوق', 'Mashtoul El Souq', false),
-       ('القنايات', 'Qenaiat', false),
-       ('أبو حماد', 'Abu Hammad', false),
-       ('القرين', 'El Qurain', false),
-       ('ههيا', 'Hehia', false),
-       ('أبو كبير', 'Abu Kabir', false),
-       ('فاقوس', 'Faccus', false),
-       ('الصالحية الجديدة', 'El Salihia El Gedida', false),
-       ('الإبراهيمية', 'Al Ibrahimiyah', false),
-       ('ديرب نجم', 'Deirb Negm', false),
-       ('كفر صقر', 'Kafr Saqr', false),
-       ('أولاد صقر', 'Awlad Saqr', false),
-       ('الحسينية', 'Husseiniya', false),
-       ('صان الحجر القبلية', 'san alhajar alqablia', false),
-       ('منشأة أبو عمر', 'Manshayat Abu Omar', false),
-       ('الطور', 'Al Toor', false),
-       ('شرم الشيخ', 'Sharm El-Shaikh', false),
-       ('دهب', 'Dahab', false),
-       ('نويبع', 'Nuweiba', false),
-       ('طابا', 'Taba', false),
-       ('سانت كاترين', 'Saint Catherine', false),
-       ('أبو رديس', 'Abu Redis', false),
-       ('أبو زنيمة', 'Abu Zenaima', false),
-       ('رأس سدر', 'Ras Sidr', false),
-       ('كفر الشيخ', 'Kafr El Sheikh', false),
-       ('وسط البلد كفر الشيخ', 'Kafr El Sheikh Downtown', false),
-       ('دسوق', 'Desouq', false),
-       ('فوه', 'Fooh', false),
-       ('مطوبس', 'Metobas', false),
-       ('برج البرلس', 'Burg Al Burullus', false),
-       ('بلطيم', 'Baltim', false),
-       ('مصيف بلطيم', 'Masief Baltim', false),
-       ('الحامول', 'Hamol', false),
-       ('بيلا', 'Bella', false),
-       ('الرياض', 'Riyadh', false),
-       ('سيدي سالم', 'Sidi Salm', false),
-       ('قلين', 'Qellen', false),
-       ('سيدي غازي', 'Sidi Ghazi', false),
-       ('مرسى مطروح', 'Marsa Matrouh', false),
-       ('الحمام', 'El Hamam', false),
-       ('العلمين', 'Alamein', false),
-       ('الضبعة', 'Dabaa', false),
-       ('النجيلة', 'Al-Nagila', false),
-       ('سيدي براني', 'Sidi Brani', false),
-       ('السلوم', 'Salloum', false),
-       ('سيوة', 'Siwa', false),
-       ('مارينا', 'Marina', false),
-       ('الساحل الشمالى', 'North Coast', false),
-       ('الأقصر', 'Luxor', false),
-       ('الأقصر الجديدة', 'New Luxor', false),
-       ('إسنا', 'Esna', false),
-       ('طيبة الجديدة', 'New Tiba', false),
-       ('الزينية', 'Al ziynia', false),
-       ('البياضية', 'Al Bayadieh', false),
-       ('القرنة', 'Al Qarna', false),
-       ('أرمنت', 'Armant', false),
-       ('الطود', 'Al Tud', false),
-       ('قنا', 'Qena', false),
-       ('قنا الجديدة', 'New Qena', false),
-       ('ابو طشت', 'Abu Tesht', false),
-       ('نجع حمادي', 'Nag Hammadi', false),
-       ('دشنا', 'Deshna', false),
-       ('الوقف', 'Alwaqf', false),
-       ('قفط', 'Qaft', false),
-       ('نقادة', 'Naqada', false),
-       ('فرشوط', 'Farshout', false),
-       ('قوص', 'Quos', false),
-       ('العريش', 'Arish', false),
-       ('الشيخ زويد', 'Sheikh Zowaid', false),
-       ('نخل', 'Nakhl', false),
-       ('رفح', 'Rafah', false),
-       ('بئر العبد', 'Bir al-Abed', false),
-       ('الحسنة', 'Al Hasana', false),
-       ('سوهاج', 'Sohag', false),
-       ('سوهاج الجديدة', 'Sohag El Gedida', false),
-       ('أخميم', 'Akhmeem', false),
-       ('أخميم الجديدة', 'Akhmim El Gedida', false),
-       ('البلينا', 'Albalina', false),
-       ('المراغة', 'El Maragha', false),
-       ('المنشأة', 'almunshaa', false),
-       ('دار السلام', 'Dar AISalaam', false),
-       ('جرجا', 'Gerga', false),
-       ('جهينة الغربية', 'Jahina Al Gharbia', false),
-       ('ساقلته', 'Saqilatuh', false),
-       ('طما', 'Tama', false),
-       ('طهطا', 'Tahta', false),
-       ('الكوثر', 'Alkawthar', false);
\ No newline at end of file
+-- Egyptian governorates and their cities and districts, all cities are inactive until enabled by staff.
+-- 10th of Ramadan and Marsa Alam are listed only under their own governorates (Sharkia and Red Sea)
+-- names are stored in translations, so the rows are staged with their future ids to attach the names
+CREATE TEMPORARY TABLE seed_governorates (
+    "id"      bigint DEFAULT nextval(pg_get_serial_sequence('governorates', 'id')),
+    "name_ar" varchar(75),
+    "name_en" varchar(75)
+);
+
+CREATE TEMPORARY TABLE seed_cities (
+    "id"          bigint DEFAULT nextval(pg_get_serial_sequence('cities', 'id')),
+    "governorate" varchar(75),
+    "name_ar"     varchar(75),
+    "name_en"     varchar(75)
+);
+
+INSERT INTO seed_governorates (name_ar, name_en)
+VALUES ('القاهرة', 'Cairo'),
+       ('الجيزة', 'Giza'),
+       ('الإسكندرية', 'Alexandria'),
+       ('الدقهلية', 'Dakahlia'),
+       ('البحر الأحمر', 'Red Sea'),
+       ('البحيرة', 'Beheira'),
+       ('الفيوم', 'Fayoum'),
+       ('الغربية', 'Gharbia'),
+       ('الإسماعيلية', 'Ismailia'),
+       ('المنوفية', 'Menofia'),
+       ('المنيا', 'Minya'),
+       ('القليوبية', 'Qalyubia'),
+       ('الوادي الجديد', 'New Valley'),
+       ('السويس', 'Suez'),
+       ('أسوان', 'Aswan'),
+       ('أسيوط', 'Assiut'),
+       ('بني سويف', 'Beni Suef'),
+       ('بورسعيد', 'Port Said'),
+       ('دمياط', 'Damietta'),
+       ('الشرقية', 'Sharkia'),
+       ('جنوب سيناء', 'South Sinai'),
+       ('كفر الشيخ', 'Kafr El Sheikh'),
+       ('مطروح', 'Matrouh'),
+       ('الأقصر', 'Luxor'),
+       ('قنا', 'Qena'),
+       ('شمال سيناء', 'North Sinai'),
+       ('سوهاج', 'Sohag');
+
+-- Cairo
+INSERT INTO seed_cities (governorate, name_ar, name_en)
+SELECT 'Cairo', city.name_ar, city.name_en
+FROM (VALUES ('15 مايو', '15 May'),
+             ('الازبكية', 'Al Azbakeyah'),
+             ('البساتين', 'Al Basatin'),
+             ('التبين', 'Tebin'),
+             ('الخليفة', 'El-Khalifa'),
+             ('الدراسة', 'El darrasa'),
+             ('الدرب الاحمر', 'Aldarb Alahmar'),
+             ('الزاوية الحمراء', 'Zawya al-Hamra'),
+             ('الزيتون', 'El-Zaytoun'),
+             ('الساحل', 'Sahel'),
+             ('السلام', 'El Salam'),
+             ('السيدة زينب', 'Sayeda Zeinab'),
+             ('الشرابية', 'El Sharabeya'),
+             ('مدينة الشروق', 'Shorouk'),
+             ('الظاهر', 'El Daher'),
+             ('العتبة', 'Ataba'),
+             ('القاهرة الجديدة', 'New Cairo'),
+             ('المرج', 'El Marg'),
+             ('عزبة النخل', 'Ezbet el Nakhl'),
+             ('المطرية', 'Matareya'),
+             ('المعادى', 'Maadi'),
+             ('المعصرة', 'Maasara'),
+             ('المقطم', 'Mokattam'),
+             ('المنيل', 'Manyal'),
+             ('الموسكى', 'Mosky'),
+             ('النزهة', 'Nozha'),
+             ('الوايلى', 'Waily'),
+             ('باب الشعرية', 'Bab al-Shereia'),
+             ('بولاق', 'Bolaq'),
+             ('جاردن سيتى', 'Garden City'),
+             ('حدائق القبة', 'Hadayek El-Kobba'),
+             ('حلوان', 'Helwan'),
+             ('دار السلام', 'Dar Al Salam'),
+             ('شبرا', 'Shubra'),
+             ('طره', 'Tura'),
+             ('عابدين', 'Abdeen'),
+             ('عباسية', 'Abaseya'),
+             ('عين شمس', 'Ain Shams'),
+             ('مدينة نصر', 'Nasr City'),
+             ('مصر الجديدة', 'New Heliopolis'),
+             ('مصر القديمة', 'Masr Al Qadima'),
+             ('منشية ناصر', 'Mansheya Nasir'),
+             ('مدينة بدر', 'Badr City'),
+             ('مدينة العبور', 'Obour City'),
+             ('وسط البلد', 'Cairo Downtown'),
+             ('الزمالك', 'Zamalek'),
+             ('قصر النيل', 'Kasr El Nile'),
+             ('الرحاب', 'Rehab'),
+             ('القطامية', 'Katameya'),
+             ('مدينتي', 'Madinty'),
+             ('روض الفرج', 'Rod Alfarag'),
+             ('شيراتون', 'Sheraton'),
+             ('الجمالية', 'El-Gamaleya'),
+             ('الحلمية', 'Helmeyat Alzaytoun'),
+             ('النزهة الجديدة', 'New Nozha'),
+             ('العاصمة الإدارية', 'Capital New')) AS city (name_ar, name_en);
+
+-- Giza
+INSERT INTO seed_cities (governorate, name_ar, name_en)
+SELECT 'Giza', city.name_ar, city.name_en
+FROM (VALUES ('الجيزة', 'Giza'),
+             ('السادس من أكتوبر', 'Sixth of October'),
+             ('الشيخ زايد', 'Cheikh Zayed'),
+             ('الحوامدية', 'Hawamdiyah'),
+             ('البدرشين', 'Al Badrasheen'),
+             ('الصف', 'Saf'),
+             ('أطفيح', 'Atfih'),
+             ('العياط', 'Al Ayat'),
+             ('الباويطي', 'Al-Bawaiti'),
+             ('منشأة القناطر', 'ManshiyetAl Qanater'),
+             ('أوسيم', 'Oaseem'),
+             ('كرداسة', 'Kerdasa'),
+             ('أبو النمرس', 'Abu Nomros'),
+             ('كفر غطاطي', 'Kafr Ghati'),
+             ('منشأة البكاري', 'Manshiyet Al Bakari'),
+             ('الدقى', 'Dokki'),
+             ('العجوزة', 'Agouza'),
+             ('الهرم', 'Haram'),
+             ('الوراق', 'Warraq'),
+             ('امبابة', 'Imbaba'),
+             ('بولاق الدكرور', 'Boulaq Dakrour'),
+             ('الواحات البحرية', 'Al Wahat Al Baharia'),
+             ('العمرانية', 'Omraneya'),
+             ('المنيب', 'Moneeb'),
+             ('بين السرايات', 'Bin Alsarayat'),
+             ('الكيت كات', 'Kit Kat'),
+             ('المهندسين', 'Mohandessin'),
+             ('فيصل', 'Faisal'),
+             ('أبو رواش', 'Abu Rawash'),
+             ('حدائق الأهرام', 'Hadayek Alahram'),
+             ('الحرانية', 'Haraneya'),
+             ('حدائق اكتوبر', 'Hadayek October'),
+             ('صفط اللبن', 'Saft Allaban'),
+             ('القرية الذكية', 'Smart Village'),
+             ('ارض اللواء', 'Ard Ellwaa')) AS city (name_ar, name_en);
+
+-- Alexandria
+INSERT INTO seed_cities (governorate, name_ar, name_en)
+SELECT 'Alexandria', city.name_ar, city.name_en
+FROM (VALUES ('ابو قير', 'Abu Qir'),
+             ('الابراهيمية', 'Al Ibrahimeyah'),
+             ('الأزاريطة', 'Azarita'),
+             ('الانفوشى', 'Anfoushi'),
+             ('الدخيلة', 'Dekheila'),
+             ('السيوف', 'El Soyof'),
+             ('العامرية', 'Ameria'),
+             ('اللبان', 'El Labban'),
+             ('المفروزة', 'Al Mafrouza'),
+             ('المنتزه', 'El Montaza'),
+             ('المنشية', 'Mansheya'),
+             ('الناصرية', 'Naseria'),
+             ('امبروزو', 'Ambrozo'),
+             ('باب شرق', 'Bab Sharq'),
+             ('برج العرب', 'Bourj Alarab'),
+             ('ستانلى', 'Stanley'),
+             ('سموحة', 'Smouha'),
+             ('سيدى بشر', 'Sidi Bishr'),
+             ('شدس', 'Shads'),
+             ('غيط العنب', 'Gheet Alenab'),
+             ('فلمينج', 'Fleming'),
+             ('فيكتوريا', 'Victoria'),
+             ('كامب شيزار', 'Camp Shizar'),
+             ('كرموز', 'Karmooz'),
+             ('محطة الرمل', 'Mahta Alraml'),
+             ('مينا البصل', 'Mina El-Basal'),
+             ('العصافرة', 'Asafra'),
+             ('العجمي', 'Agamy'),
+             ('بكوس', 'Bakos'),
+             ('بولكلي', 'Boulkly'),
+             ('كليوباترا', 'Cleopatra'),
+             ('جليم', 'Glim'),
+             ('المعمورة', 'Al Mamurah'),
+             ('المندرة', 'Al Mandara'),
+             ('محرم بك', 'Moharam Bek'),
+             ('الشاطبي', 'Elshatby'),
+             ('سيدي جابر', 'Sidi Gaber'),
+             ('الساحل الشمالي', 'North Coast/sahel'),
+             ('الحضرة', 'Alhadra'),
+             ('العطارين', 'Alattarin'),
+             ('سيدي كرير', 'Sidi Kerir'),
+             ('الجمرك', 'Elgomrok'),
+             ('المكس', 'Al Max'),
+             ('مارينا', 'Marina')) AS city (name_ar, name_en);
+
+-- Dakahlia
+INSERT INTO seed_cities (governorate, name_ar, name_en)
+SELECT 'Dakahlia', city.name_ar, city.name_en
+FROM (VALUES ('المنصورة', 'Mansoura'),
+             ('طلخا', 'Talkha'),
+             ('ميت غمر', 'Mitt Ghamr'),
+             ('دكرنس', 'Dekernes'),
+             ('أجا', 'Aga'),
+             ('منية النصر', 'Menia El Nasr'),
+             ('السنبلاوين', 'Sinbillawin'),
+             ('الكردي', 'El Kurdi'),
+             ('بني عبيد', 'Bani Ubaid'),
+             ('المنزلة', 'Al Manzala'),
+             ('تمي الأمديد', 'tami alamdid'),
+             ('الجمالية', 'aljamalia'),
+             ('شربين', 'Sherbin'),
+             ('المطرية', 'Mataria'),
+             ('بلقاس', 'Belqas'),
+             ('ميت سلسيل', 'Meet Salsil'),
+             ('جمصة', 'Gamasa'),
+             ('محلة دمنة', 'Mahalat Damana'),
+             ('نبروه', 'Nabroh')) AS city (name_ar, name_en);
+
+-- Red Sea
+INSERT INTO seed_cities (governorate, name_ar, name_en)
+SELECT 'Red Sea', city.name_ar, city.name_en
+FROM (VALUES ('الغردقة', 'Hurghada'),
+             ('رأس غارب', 'Ras Ghareb'),
+             ('سفاجا', 'Safaga'),
+             ('القصير', 'El Qusiar'),
+             ('مرسى علم', 'Marsa Alam'),
+             ('الشلاتين', 'Shalatin'),
+             ('حلايب', 'Halaib'),
+             ('الدهار', 'Aldahar')) AS city (name_ar, name_en);
+
+-- Beheira
+INSERT INTO seed_cities (governorate, name_ar, name_en)
+SELECT 'Beheira', city.name_ar, city.name_en
+FROM (VALUES ('دمنهور', 'Damanhour'),
+             ('كفر الدوار', 'Kafr El Dawar'),
+             ('رشيد', 'Rashid'),
+             ('إدكو', 'Edco'),
+             ('أبو المطامير', 'Abu al-Matamir'),
+             ('أبو حمص', 'Abu Homs'),
+             ('الدلنجات', 'Delengat'),
+             ('المحمودية', 'Mahmoudiyah'),
+             ('الرحمانية', 'Rahmaniyah'),
+             ('إيتاي البارود', 'Itai Baroud'),
+             ('حوش عيسى', 'Housh Eissa'),
+             ('شبراخيت', 'Shubrakhit'),
+             ('كوم حمادة', 'Kom Hamada'),
+             ('بدر', 'Badr'),
+             ('وادي النطرون', 'Wadi Natrun'),
+             ('النوبارية الجديدة', 'New Nubaria'),
+             ('النوبارية', 'Alnoubareya')) AS city (name_ar, name_en);
+
+-- Fayoum
+INSERT INTO seed_cities (governorate, name_ar, name_en)
+SELECT 'Fayoum', city.name_ar, city.name_en
+FROM (VALUES ('الفيوم', 'Fayoum'),
+             ('الفيوم الجديدة', 'Fayoum El Gedida'),
+             ('طامية', 'Tamiya'),
+             ('سنورس', 'Snores'),
+             ('إطسا', 'Etsa'),
+             ('إبشواي', 'Epschway'),
+             ('يوسف الصديق', 'Yusuf El Sediaq'),
+             ('الحادقة', 'Hadqa'),
+             ('اطسا', 'Atsa'),
+             ('الجامعة', 'Algamaa'),
+             ('السيالة', 'Sayala')) AS city (name_ar, name_en);
+
+-- Gharbia
+INSERT INTO seed_cities (governorate, name_ar, name_en)
+SELECT 'Gharbia', city.name_ar, city.name_en
+FROM (VALUES ('طنطا', 'Tanta'),
+             ('المحلة الكبرى', 'Al Mahalla Al Kobra'),
+             ('كفر الزيات', 'Kafr El Zayat'),
+             ('زفتى', 'Zefta'),
+             ('السنطة', 'El Santa'),
+             ('قطور', 'Qutour'),
+             ('بسيون', 'Basion'),
+             ('سمنود', 'Samannoud')) AS city (name_ar, name_en);
+
+-- Ismailia
+INSERT INTO seed_cities (governorate, name_ar, name_en)
+SELECT 'Ismailia', city.name_ar, city.name_en
+FROM (VALUES ('الإسماعيلية', 'Ismailia'),
+             ('فايد', 'Fayed'),
+             ('القنطرة شرق', 'Qantara Sharq'),
+             ('القنطرة غرب', 'Qantara Gharb'),
+             ('التل الكبير', 'El Tal El Kabier'),
+             ('أبو صوير', 'Abu Sawir'),
+             ('القصاصين الجديدة', 'Kasasien El Gedida'),
+             ('نفيشة', 'Nefesha'),
+             ('الشيخ زايد', 'Sheikh Zayed')) AS city (name_ar, name_en);
+
+-- Menofia
+INSERT INTO seed_cities (governorate, name_ar, name_en)
+SELECT 'Menofia', city.name_ar, city.name_en
+FROM (VALUES ('شبين الكوم', 'Shbeen El Koom'),
+             ('مدينة السادات', 'Sadat City'),
+             ('منوف', 'Menouf'),
+             ('سرس الليان', 'Sars El-Layan'),
+             ('أشمون', 'Ashmon'),
+             ('الباجور', 'Al Bagor'),
+             ('قويسنا', 'Quesna'),
+             ('بركة السبع', 'Berkat El Saba'),
+             ('تلا', 'Tala'),
+             ('الشهداء', 'Al Shohada')) AS city (name_ar, name_en);
+
+-- Minya
+INSERT INTO seed_cities (governorate, name_ar, name_en)
+SELECT 'Minya', city.name_ar, city.name_en
+FROM (VALUES ('المنيا', 'Minya'),
+             ('المنيا الجديدة', 'Minya El Gedida'),
+             ('العدوة', 'El Adwa'),
+             ('مغاغة', 'Magagha'),
+             ('بني مزار', 'Bani Mazar'),
+             ('مطاي', 'Mattay'),
+             ('سمالوط', 'Samalut'),
+             ('المدينة الفكرية', 'Madinat El Fekria'),
+             ('ملوي', 'Meloy'),
+             ('دير مواس', 'Deir Mawas'),
+             ('ابو قرقاص', 'Abu Qurqas'),
+             ('ارض سلطان', 'Ard Sultan')) AS city (name_ar, name_en);
+
+-- Qalyubia
+INSERT INTO seed_cities (governorate, name_ar, name_en)
+SELECT 'Qalyubia', city.name_ar, city.name_en
+FROM (VALUES ('بنها', 'Banha'),
+             ('قليوب', 'Qalyub'),
+             ('شبرا الخيمة', 'Shubra Al Khaimah'),
+             ('القناطر الخيرية', 'Al Qanater Charity'),
+             ('الخانكة', 'Khanka'),
+             ('كفر شكر', 'Kafr Shukr'),
+             ('طوخ', 'Tukh'),
+             ('قها', 'Qaha'),
+             ('العبور', 'Obour'),
+             ('الخصوص', 'Khosous'),
+             ('شبين القناطر', 'Shibin Al Qanater'),
+             ('مسطرد', 'Mostorod')) AS city (name_ar, name_en);
+
+-- New Valley
+INSERT INTO seed_cities (governorate, name_ar, name_en)
+SELECT 'New Valley', city.name_ar, city.name_en
+FROM (VALUES ('الخارجة', 'El Kharga'),
+             ('باريس', 'Paris'),
+             ('موط', 'Mout'),
+             ('الفرافرة', 'Farafra'),
+             ('بلاط', 'Balat'),
+             ('الداخلة', 'Dakhla')) AS city (name_ar, name_en);
+
+-- Suez
+INSERT INTO seed_cities (governorate, name_ar, name_en)
+SELECT 'Suez', city.name_ar, city.name_en
+FROM (VALUES ('السويس', 'Suez'),
+             ('الجناين', 'Alganayen'),
+             ('عتاقة', 'Ataqah'),
+             ('العين السخنة', 'Ain Sokhna'),
+             ('فيصل', 'Faysal')) AS city (name_ar, name_en);
+
+-- Aswan
+INSERT INTO seed_cities (governorate, name_ar, name_en)
+SELECT 'Aswan', city.name_ar, city.name_en
+FROM (VALUES ('أسوان', 'Aswan'),
+             ('أسوان الجديدة', 'Aswan El Gedida'),
+             ('دراو', 'Drau'),
+             ('كوم أمبو', 'Kom Ombo'),
+             ('نصر النوبة', 'Nasr Al Nuba'),
+             ('كلابشة', 'Kalabsha'),
+             ('إدفو', 'Edfu'),
+             ('الرديسية', 'Al-Radisiyah'),
+             ('البصيلية', 'Al Basilia'),
+             ('السباعية', 'Al Sibaeia'),
+             ('ابوسمبل السياحية', 'Abo Simbl Al Siyahia')) AS city (name_ar, name_en);
+
+-- Assiut
+INSERT INTO seed_cities (governorate, name_ar, name_en)
+SELECT 'Assiut', city.name_ar, city.name_en
+FROM (VALUES ('أسيوط', 'Assiut'),
+             ('أسيوط الجديدة', 'Assiut El Gedida'),
+             ('ديروط', 'Dayrout'),
+             ('منفلوط', 'Manfalut'),
+             ('القوصية', 'Qusiya'),
+             ('أبنوب', 'Abnoub'),
+             ('أبو تيج', 'Abu Tig'),
+             ('الغنايم', 'El Ghanaim'),
+             ('ساحل سليم', 'Sahel Selim'),
+             ('البداري', 'El Badari'),
+             ('صدفا', 'Sidfa')) AS city (name_ar, name_en);
+
+-- Beni Suef
+INSERT INTO seed_cities (governorate, name_ar, name_en)
+SELECT 'Beni Suef', city.name_ar, city.name_en
+FROM (VALUES ('بني سويف', 'Bani Sweif'),
+             ('بني سويف الجديدة', 'Beni Suef El Gedida'),
+             ('الواسطى', 'Al Wasta'),
+             ('ناصر', 'Naser'),
+             ('إهناسيا', 'Ehnasia'),
+             ('ببا', 'beba'),
+             ('الفشن', 'Fashn'),
+             ('سمسطا', 'Somasta'),
+             ('الاباصيرى', 'Alabbaseri'),
+             ('مقبل', 'Mokbel')) AS city (name_ar, name_en);
+
+-- Port Said
+INSERT INTO seed_cities (governorate, name_ar, name_en)
+SELECT 'Port Said', city.name_ar, city.name_en
+FROM (VALUES ('بورسعيد', 'PorSaid'),
+             ('بورفؤاد', 'Port Fouad'),
+             ('العرب', 'Alarab'),
+             ('حى الزهور', 'Zohour'),
+             ('حى الشرق', 'Alsharq'),
+             ('حى الضواحى', 'Aldawahi'),
+             ('حى المناخ', 'Almanakh'),
+             ('حى مبارك', 'Mubarak')) AS city (name_ar, name_en);
+
+-- Damietta
+INSERT INTO seed_cities (governorate, name_ar, name_en)
+SELECT 'Damietta', city.name_ar, city.name_en
+FROM (VALUES ('دمياط', 'Damietta'),
+             ('دمياط الجديدة', 'New Damietta'),
+             ('رأس البر', 'Ras El Bar'),
+             ('فارسكور', 'Faraskour'),
+             ('الزرقا', 'Zarqa'),
+             ('السرو', 'alsaru'),
+             ('الروضة', 'alruwda'),
+             ('كفر البطيخ', 'Kafr El-Batikh'),
+             ('عزبة البرج', 'Azbet Al Burg'),
+             ('ميت أبو غالب', 'Meet Abou Ghalib'),
+             ('كفر سعد', 'Kafr Saad')) AS city (name_ar, name_en);
+
+-- Sharkia
+INSERT INTO seed_cities (governorate, name_ar, name_en)
+SELECT 'Sharkia', city.name_ar, city.name_en
+FROM (VALUES ('الزقازيق', 'Zagazig'),
+             ('العاشر من رمضان', 'Al Ashr Men Ramadan'),
+             ('منيا القمح', 'Minya Al Qamh'),
+             ('بلبيس', 'Belbeis'),
+             ('مشتول السوق', 'Mashtoul El Souq'),
+             ('القنايات', 'Qenaiat'),
+             ('أبو حماد', 'Abu Hammad'),
+             ('القرين', 'El Qurain'),
+             ('ههيا', 'Hehia'),
+             ('أبو كبير', 'Abu Kabir'),
+             ('فاقوس', 'Faccus'),
+             ('الصالحية الجديدة', 'El Salihia El Gedida'),
+             ('الإبراهيمية', 'Al Ibrahimiyah'),
+             ('ديرب نجم', 'Deirb Negm'),
+             ('كفر صقر', 'Kafr Saqr'),
+             ('أولاد صقر', 'Awlad Saqr'),
+             ('الحسينية', 'Husseiniya'),
+             ('صان الحجر القبلية', 'san alhajar alqablia'),
+             ('منشأة أبو عمر', 'Manshayat Abu Omar')) AS city (name_ar, name_en);
+
+-- South Sinai
+INSERT INTO seed_cities (governorate, name_ar, name_en)
+SELECT 'South Sinai', city.name_ar, city.name_en
+FROM (VALUES ('الطور', 'Al Toor'),
+             ('شرم الشيخ', 'Sharm El-Shaikh'),
+             ('دهب', 'Dahab'),
+             ('نويبع', 'Nuweiba'),
+             ('طابا', 'Taba'),
+             ('سانت كاترين', 'Saint Catherine'),
+             ('أبو رديس', 'Abu Redis'),
+             ('أبو زنيمة', 'Abu Zenaima'),
+             ('رأس سدر', 'Ras Sidr')) AS city (name_ar, name_en);
+
+-- Kafr El Sheikh
+INSERT INTO seed_cities (governorate, name_ar, name_en)
+SELECT 'Kafr El Sheikh', city.name_ar, city.name_en
+FROM (VALUES ('كفر الشيخ', 'Kafr El Sheikh'),
+             ('وسط البلد كفر الشيخ', 'Kafr El Sheikh Downtown'),
+             ('دسوق', 'Desouq'),
+             ('فوه', 'Fooh'),
+             ('مطوبس', 'Metobas'),
+             ('برج البرلس', 'Burg Al Burullus'),
+             ('بلطيم', 'Baltim'),
+             ('مصيف بلطيم', 'Masief Baltim'),
+             ('الحامول', 'Hamol'),
+             ('بيلا', 'Bella'),
+             ('الرياض', 'Riyadh'),
+             ('سيدي سالم', 'Sidi Salm'),
+             ('قلين', 'Qellen'),
+             ('سيدي غازي', 'Sidi Ghazi')) AS city (name_ar, name_en);
+
+-- Matrouh
+INSERT INTO seed_cities (governorate, name_ar, name_en)
+SELECT 'Matrouh', city.name_ar, city.name_en
+FROM (VALUES ('مرسى مطروح', 'Marsa Matrouh'),
+             ('الحمام', 'El Hamam'),
+             ('العلمين', 'Alamein'),
+             ('الضبعة', 'Dabaa'),
+             ('النجيلة', 'Al-Nagila'),
+             ('سيدي براني', 'Sidi Brani'),
+             ('السلوم', 'Salloum'),
+             ('سيوة', 'Siwa'),
+             ('مارينا', 'Marina'),
+             ('الساحل الشمالى', 'North Coast')) AS city (name_ar, name_en);
+
+-- Luxor
+INSERT INTO seed_cities (governorate, name_ar, name_en)
+SELECT 'Luxor', city.name_ar, city.name_en
+FROM (VALUES ('الأقصر', 'Luxor'),
+             ('الأقصر الجديدة', 'New Luxor'),
+             ('إسنا', 'Esna'),
+             ('طيبة الجديدة', 'New Tiba'),
+             ('الزينية', 'Al ziynia'),
+             ('البياضية', 'Al Bayadieh'),
+             ('القرنة', 'Al Qarna'),
+             ('أرمنت', 'Armant'),
+             ('الطود', 'Al Tud')) AS city (name_ar, name_en);
+
+-- Qena
+INSERT INTO seed_cities (governorate, name_ar, name_en)
+SELECT 'Qena', city.name_ar, city.name_en
+FROM (VALUES ('قنا', 'Qena'),
+             ('قنا الجديدة', 'New Qena'),
+             ('ابو طشت', 'Abu Tesht'),
+             ('نجع حمادي', 'Nag Hammadi'),
+             ('دشنا', 'Deshna'),
+             ('الوقف', 'Alwaqf'),
+             ('قفط', 'Qaft'),
+             ('نقادة', 'Naqada'),
+             ('فرشوط', 'Farshout'),
+             ('قوص', 'Quos')) AS city (name_ar, name_en);
+
+-- North Sinai
+INSERT INTO seed_cities (governorate, name_ar, name_en)
+SELECT 'North Sinai', city.name_ar, city.name_en
+FROM (VALUES ('العريش', 'Arish'),
+             ('الشيخ زويد', 'Sheikh Zowaid'),
+             ('نخل', 'Nakhl'),
+             ('رفح', 'Rafah'),
+             ('بئر العبد', 'Bir al-Abed'),
+             ('الحسنة', 'Al Hasana')) AS city (name_ar, name_en);
+
+-- Sohag
+INSERT INTO seed_cities (governorate, name_ar, name_en)
+SELECT 'Sohag', city.name_ar, city.name_en
+FROM (VALUES ('سوهاج', 'Sohag'),
+             ('سوهاج الجديدة', 'Sohag El Gedida'),
+             ('أخميم', 'Akhmeem'),
+             ('أخميم الجديدة', 'Akhmim El Gedida'),
+             ('البلينا', 'Albalina'),
+             ('المراغة', 'El Maragha'),
+             ('المنشأة', 'almunshaa'),
+             ('دار السلام', 'Dar AISalaam'),
+             ('جرجا', 'Gerga'),
+             ('جهينة الغربية', 'Jahina Al Gharbia'),
+             ('ساقلته', 'Saqilatuh'),
+             ('طما', 'Tama'),
+             ('طهطا', 'Tahta'),
+             ('الكوثر', 'Alkawthar')) AS city (name_ar, name_en);
+
+INSERT INTO governorates (id)
+SELECT id
+FROM seed_governorates
+ORDER BY id;
+
+INSERT INTO cities (id, governorate_id, is_active)
+SELECT seed_cities.id, seed_governorates.id, false
+FROM seed_cities
+         JOIN seed_governorates ON seed_governorates.name_en = seed_cities.governorate
+ORDER BY seed_cities.id;
+
+INSERT INTO translations (entity, entity_id, field, locale, value)
+SELECT 'governorate', id, 'name', 'en', name_en
+FROM seed_governorates
+UNION ALL
+SELECT 'governorate', id, 'name', 'ar', name_ar
+FROM seed_governorates
+UNION ALL
+SELECT 'city', id, 'name', 'en', name_en
+FROM seed_cities
+UNION ALL
+SELECT 'city', id, 'name', 'ar', name_ar
+FROM seed_cities;
+
+DROP TABLE seed_cities, seed_governorates;
@@ -0,0 +1,56 @@
+-- name: UserAddresses :many
+SELECT *
+FROM user_addresses
+WHERE user_id = @user_id
+ORDER BY is_default DESC, created_at;
+
+-- name: UserAddressesCount :one
+SELECT COUNT(*)
+FROM user_addresses
+WHERE user_id = @user_id;
+
+-- name: GetUserAddress :one
+SELECT *
+FROM user_addresses
+WHERE id = @id
+  AND user_id = @user_id;
+
+-- name: CreateUserAddress :one
+INSERT INTO user_addresses(user_id, city_id, label, street, building, floor, landmark, latitude, longitude, is_default)
+VALUES (@user_id, @city_id, @label, @street, @building, @floor, @landmark, @latitude, @longitude,
+        -- the first address is the default one
+        NOT EXISTS (SELECT 1 FROM user_addresses WHERE user_id = @user_id))
+RETURNING *;
+
+-- name: UpdateUserAddress :one
+UPDATE user_addresses
+SET city_id    = @city_id,
+    label      = @label,
+    street     = @street,
+    building   = @building,
+    floor      = @floor,
+    landmark   = @landmark,
+    latitude   = @latitude,
+    longitude  = @longitude,
+    updated_at = NOW()
+WHERE id = @id
+  AND user_id = @user_id
+RETURNING *;
+
+-- name: SetDefaultUserAddress :execrows
+UPDATE user_addresses
+SET is_default = (id = @id)
+WHERE user_id = @user_id
+  AND EXISTS (SELECT 1 FROM user_addresses WHERE id = @id AND user_id = @user_id);
+
+-- name: DeleteUserAddress :execrows
+DELETE
+FROM user_addresses
+WHERE id = @id
+  AND user_id = @user_id;
+
+-- name: EnsureDefaultUserAddress :exec
+UPDATE user_addresses
+SET is_default = TRUE
+WHERE id = (SELECT id FROM user_addresses WHERE user_addresses.user_id = @user_id ORDER BY created_at LIMIT 1)
+  AND NOT EXISTS (SELECT 1 FROM user_addresses WHERE user_addresses.user_id = @user_id AND is_default);
@@ -0,0 +1,31 @@
+-- name: CreateApiKey :one
+INSERT INTO api_keys(name, prefix, secret_hash, scopes, created_by, expires_at)
+VALUES (@name, @prefix, @secret_hash, @scopes, @created_by, @expires_at)
+RETURNING *;
+
+-- name: GetApiKeyByPrefix :one
+SELECT *
+FROM api_keys
+WHERE prefix = @prefix;
+
+-- name: AllApiKeys :many
+SELECT *
+FROM api_keys
+ORDER BY created_at DESC
+LIMIT $1 OFFSET $2;
+
+-- name: AllApiKeysCount :one
+SELECT COUNT(*)
+FROM api_keys;
+
+-- name: TouchApiKey :exec
+UPDATE api_keys
+SET last_used_at = NOW()
+WHERE id = @id
+  AND (last_used_at IS NULL OR last_used_at < NOW() - INTERVAL '1 minute');
+
+-- name: RevokeApiKey :execrows
+UPDATE api_keys
+SET revoked_at = NOW()
+WHERE id = @id
+  AND revoked_at IS NULL;
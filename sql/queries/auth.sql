@@ -0,0 +1,15 @@
+-- name: CreateAuthRequest :exec
+INSERT INTO auth_requests(state, provider, nonce, code_verifier, expires_at)
+VALUES (@state, @provider, @nonce, @code_verifier, @expires_at);
+
+-- name: ConsumeAuthRequest :one
+DELETE
+FROM auth_requests
+WHERE state = @state
+  AND expires_at > NOW()
+RETURNING *;
+
+-- name: DeleteExpiredAuthRequests :exec
+DELETE
+FROM auth_requests
+WHERE expires_at <= NOW();
@@ -11,37 +11,121 @@ FROM cities;
 -- name: FilterCities :many
 SELECT *
 FROM cities
-WHERE name_en ILIKE @query
-   or name_ar ILIKE @query
+WHERE EXISTS (SELECT 1
+              FROM translations
+              WHERE entity = 'city'
+                AND entity_id = cities.id
+                AND field = 'name'
+                AND value ILIKE @query)
 ORDER BY id
 LIMIT $1 OFFSET $2;
 
+-- name: FilterCitiesCount :one
+SELECT COUNT(*)
+FROM cities
+WHERE EXISTS (SELECT 1
+              FROM translations
+              WHERE entity = 'city'
+                AND entity_id = cities.id
+                AND field = 'name'
+                AND value ILIKE @query);
+
+-- name: CitiesAfter :many
+-- keyset page of cities, optionally filtered by name, following the given id
+SELECT *
+FROM cities
+WHERE (sqlc.narg(query)::text IS NULL
+    OR EXISTS (SELECT 1
+               FROM translations
+               WHERE entity = 'city'
+                 AND entity_id = cities.id
+                 AND field = 'name'
+                 AND value ILIKE sqlc.narg(query)))
+  AND id > @after_id
+ORDER BY id
+LIMIT @page_size;
+
+-- name: CitiesBefore :many
+-- keyset page of cities, optionally filtered by name, preceding the given id in reverse order
+SELECT *
+FROM cities
+WHERE (sqlc.narg(query)::text IS NULL
+    OR EXISTS (SELECT 1
+               FROM translations
+               WHERE entity = 'city'
+                 AND entity_id = cities.id
+                 AND field = 'name'
+                 AND value ILIKE sqlc.narg(query)))
+  AND id < @before_id
+ORDER BY id DESC
+LIMIT @page_size;
+
 -- name: ActiveCities :many
 SELECT *
 FROM cities
 WHERE is_active = TRUE
+  AND (sqlc.narg(governorate_id)::bigint IS NULL OR governorate_id = sqlc.narg(governorate_id))
 ORDER BY id
 LIMIT $1 OFFSET $2;
 
 -- name: ActiveCitiesCount :one
 SELECT COUNT(*)
 FROM cities
-WHERE is_active = TRUE;
+WHERE is_active = TRUE
+  AND (sqlc.narg(governorate_id)::bigint IS NULL OR governorate_id = sqlc.narg(governorate_id));
+
+-- name: AllActiveCities :many
+SELECT *
+FROM cities
+WHERE is_active = TRUE
+ORDER BY governorate_id, id;
+
+-- name: GetCity :one
+SELECT *
+FROM cities
+WHERE id = @id;
+
+-- name: GetActiveCity :one
+SELECT *
+FROM cities
+WHERE id = @id
+  AND is_active = TRUE;
+
+-- name: CitiesByIds :many
+SELECT *
+FROM cities
+WHERE id = ANY (@ids::bigint[]);
+
+-- name: NearestActiveCities :many
+-- active cities with location ordered by distance from the point (haversine formula, in meters)
+SELECT *
+FROM cities
+WHERE is_active = TRUE
+  AND latitude IS NOT NULL
+  AND longitude IS NOT NULL
+ORDER BY 6371008.8 * 2 * asin(least(1, sqrt(
+        power(sin(radians(latitude - @lat::float8) / 2), 2) +
+        cos(radians(@lat::float8)) * cos(radians(latitude)) * power(sin(radians(longitude - @lng::float8) / 2), 2))))
+LIMIT @max_results;
 
 -- name: CreateCity :one
-INSERT INTO cities(name_en, name_ar, is_active)
-VALUES (@name_en, @name_ar, @is_active)
+INSERT INTO cities(is_active, governorate_id, latitude, longitude, boundary)
+VALUES (@is_active, @governorate_id, @latitude, @longitude, @boundary)
 RETURNING id;
 
 -- name: UpdateCity :one
 UPDATE cities
-SET name_en=$1,
-    name_ar=$2,
-    is_active=$3
-WHERE id = $4
+SET is_active=$1,
+    governorate_id=$2,
+    latitude=$3,
+    longitude=$4,
+    boundary=$5
+WHERE id = $6
 RETURNING *;
 
 -- name: DeleteCity :exec
+WITH deleted_translations AS (
+    DELETE FROM translations WHERE entity = 'city' AND entity_id = $1)
 DELETE
 FROM cities
 WHERE id = $1;
@@ -0,0 +1,40 @@
+-- name: CityDeliveryZones :many
+SELECT *
+FROM delivery_zones
+WHERE city_id = @city_id
+ORDER BY id;
+
+-- name: ActiveDeliveryZonesByCities :many
+-- active zones of the cities, the cheapest zone first for overlapping areas
+SELECT *
+FROM delivery_zones
+WHERE city_id = ANY (@city_ids::bigint[])
+  AND is_active = TRUE
+ORDER BY fee, id;
+
+-- name: GetDeliveryZone :one
+SELECT *
+FROM delivery_zones
+WHERE id = @id;
+
+-- name: CreateDeliveryZone :one
+INSERT INTO delivery_zones(city_id, name, area, fee, min_order, eta_minutes, is_active)
+VALUES (@city_id, @name, @area, @fee, @min_order, @eta_minutes, @is_active)
+RETURNING *;
+
+-- name: UpdateDeliveryZone :one
+UPDATE delivery_zones
+SET name        = @name,
+    area        = @area,
+    fee         = @fee,
+    min_order   = @min_order,
+    eta_minutes = @eta_minutes,
+    is_active   = @is_active,
+    updated_at  = NOW()
+WHERE id = @id
+RETURNING *;
+
+-- name: DeleteDeliveryZone :execrows
+DELETE
+FROM delivery_zones
+WHERE id = @id;
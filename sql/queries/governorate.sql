@@ -0,0 +1,15 @@
+-- name: AllGovernorates :many
+SELECT *
+FROM governorates
+ORDER BY id;
+
+-- name: CreateGovernorate :one
+INSERT INTO governorates DEFAULT VALUES
+RETURNING *;
+
+-- name: DeleteGovernorate :execrows
+WITH deleted_translations AS (
+    DELETE FROM translations WHERE entity = 'governorate' AND entity_id = @id)
+DELETE
+FROM governorates
+WHERE id = @id;
@@ -0,0 +1,35 @@
+-- name: CreateImpersonation :one
+INSERT INTO impersonations(staff_id, user_id, reason, expires_at)
+VALUES (@staff_id, @user_id, @reason, @expires_at)
+RETURNING *;
+
+-- name: CreateImpersonationRequest :exec
+INSERT INTO impersonation_requests(impersonation_id, method, path, status)
+VALUES (@impersonation_id, @method, @path, @status);
+
+-- name: AllImpersonations :many
+SELECT *
+FROM impersonations
+ORDER BY created_at DESC
+LIMIT $1 OFFSET $2;
+
+-- name: AllImpersonationsCount :one
+SELECT COUNT(*)
+FROM impersonations;
+
+-- name: GetImpersonation :one
+SELECT *
+FROM impersonations
+WHERE id = @id;
+
+-- name: ImpersonationRequests :many
+SELECT *
+FROM impersonation_requests
+WHERE impersonation_id = @impersonation_id
+ORDER BY created_at;
+
+-- name: UserImpersonations :many
+SELECT *
+FROM impersonations
+WHERE user_id = @user_id
+ORDER BY created_at DESC;
@@ -0,0 +1,21 @@
+-- name: CreateLoginEvent :exec
+INSERT INTO login_events(user_id, email, method, success, failure_reason, ip_address, user_agent)
+VALUES (@user_id, @email, @method, @success, @failure_reason, @ip_address, @user_agent);
+
+-- name: UserLoginEvents :many
+SELECT *
+FROM login_events
+WHERE user_id = $1
+ORDER BY created_at DESC
+LIMIT $2 OFFSET $3;
+
+-- name: UserLoginEventsCount :one
+SELECT COUNT(*)
+FROM login_events
+WHERE user_id = @user_id;
+
+-- name: AllUserLoginEvents :many
+SELECT *
+FROM login_events
+WHERE user_id = @user_id
+ORDER BY created_at DESC;
@@ -0,0 +1,22 @@
+-- name: CreateMagicLink :exec
+INSERT INTO magic_links(token_hash, email, expires_at)
+VALUES (@token_hash, @email, @expires_at);
+
+-- name: MagicLinkCountSince :one
+SELECT COUNT(*)
+FROM magic_links
+WHERE email = @email
+  AND created_at > @since;
+
+-- name: ConsumeMagicLink :one
+UPDATE magic_links
+SET consumed_at = NOW()
+WHERE token_hash = @token_hash
+  AND consumed_at IS NULL
+  AND expires_at > NOW()
+RETURNING email;
+
+-- name: DeleteExpiredMagicLinks :exec
+DELETE
+FROM magic_links
+WHERE expires_at < NOW() - INTERVAL '1 day';
@@ -0,0 +1,82 @@
+-- name: GetUserMfa :one
+SELECT *
+FROM user_mfa
+WHERE user_id = @user_id;
+
+-- name: SetupUserMfa :one
+INSERT INTO user_mfa(user_id, secret)
+VALUES (@user_id, @secret)
+ON CONFLICT (user_id) DO UPDATE
+    SET secret          = EXCLUDED.secret,
+        last_counter    = 0,
+        failed_attempts = 0,
+        created_at      = NOW()
+WHERE user_mfa.enabled_at IS NULL
+RETURNING *;
+
+-- name: EnableUserMfa :exec
+UPDATE user_mfa
+SET enabled_at      = NOW(),
+    last_counter    = @last_counter,
+    failed_attempts = 0
+WHERE user_id = @user_id;
+
+-- name: UseMfaCounter :execrows
+UPDATE user_mfa
+SET last_counter    = @counter,
+    failed_attempts = 0
+WHERE user_id = @user_id
+  AND last_counter < @counter;
+
+-- name: IncrementMfaFailures :one
+-- count one verify attempt until the code is accepted, no row is returned when all attempts are used.
+-- attempts counted before the window start are forgotten, so the lock end after the window
+UPDATE user_mfa
+SET failed_attempts = CASE WHEN failed_at < @window_start THEN 1 ELSE failed_attempts + 1 END,
+    failed_at       = NOW()
+WHERE user_id = @user_id
+  AND (failed_attempts < @max_attempts OR failed_at < @window_start)
+RETURNING failed_attempts;
+
+-- name: ResetMfaFailures :exec
+UPDATE user_mfa
+SET failed_attempts = 0
+WHERE user_id = @user_id;
+
+-- name: DeleteUserMfa :exec
+DELETE
+FROM user_mfa
+WHERE user_id = @user_id;
+
+-- name: CreateRecoveryCodes :exec
+INSERT INTO mfa_recovery_codes(user_id, code_hash)
+SELECT @user_id::uuid, unnest(@code_hashes::varchar[]);
+
+-- name: UseRecoveryCode :execrows
+UPDATE mfa_recovery_codes
+SET used_at = NOW()
+WHERE user_id = @user_id
+  AND code_hash = @code_hash
+  AND used_at IS NULL;
+
+-- name: DeleteRecoveryCodes :exec
+DELETE
+FROM mfa_recovery_codes
+WHERE user_id = @user_id;
+
+-- name: AllSecurityPolicies :many
+SELECT *
+FROM security_policies
+ORDER BY key;
+
+-- name: GetSecurityPolicy :one
+SELECT enabled
+FROM security_policies
+WHERE key = @key;
+
+-- name: SetSecurityPolicy :one
+UPDATE security_policies
+SET enabled    = @enabled,
+    updated_at = NOW()
+WHERE key = @key
+RETURNING *;
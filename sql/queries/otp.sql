@@ -0,0 +1,40 @@
+-- name: CreatePhoneOtp :one
+INSERT INTO phone_otps(user_id, phone_number, code_hash, expires_at)
+VALUES (@user_id, @phone_number, @code_hash, @expires_at)
+RETURNING *;
+
+-- name: LastPhoneOtp :one
+SELECT *
+FROM phone_otps
+WHERE user_id = @user_id
+ORDER BY created_at DESC
+LIMIT 1;
+
+-- name: PhoneOtpCountSince :one
+SELECT COUNT(*)
+FROM phone_otps
+WHERE user_id = @user_id
+  AND created_at > @since;
+
+-- name: ActivePhoneOtp :one
+SELECT *
+FROM phone_otps
+WHERE user_id = @user_id
+  AND consumed_at IS NULL
+  AND expires_at > NOW()
+ORDER BY created_at DESC
+LIMIT 1;
+
+-- name: IncrementPhoneOtpAttempts :one
+-- use one verify attempt of the code, no row is returned when all attempts are used
+UPDATE phone_otps
+SET attempts = attempts + 1
+WHERE id = @id
+  AND attempts < @max_attempts
+RETURNING attempts;
+
+-- name: ConsumePhoneOtps :exec
+UPDATE phone_otps
+SET consumed_at = NOW()
+WHERE user_id = @user_id
+  AND consumed_at IS NULL;
@@ -0,0 +1,60 @@
+-- name: AllPermissions :many
+SELECT *
+FROM permissions
+ORDER BY code;
+
+-- name: AllRoles :many
+SELECT *
+FROM roles
+ORDER BY id;
+
+-- name: AllRolePermissions :many
+SELECT *
+FROM role_permissions
+ORDER BY role_id, permission;
+
+-- name: CreateRole :one
+INSERT INTO roles(name, description)
+VALUES (@name, @description)
+RETURNING *;
+
+-- name: UpdateRole :one
+UPDATE roles
+SET name        = $2,
+    description = $3
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteRole :exec
+DELETE
+FROM roles
+WHERE id = $1;
+
+-- name: SetRolePermissions :exec
+WITH removed AS (
+    DELETE
+    FROM role_permissions
+    WHERE role_id = @role_id
+      AND NOT (permission = ANY (@permissions::varchar[])))
+INSERT
+INTO role_permissions(role_id, permission)
+SELECT @role_id, unnest(@permissions::varchar[])
+ON CONFLICT DO NOTHING;
+
+-- name: SetUserRoles :exec
+WITH removed AS (
+    DELETE
+    FROM user_roles
+    WHERE user_id = @user_id
+      AND NOT (role_id = ANY (@role_ids::bigint[])))
+INSERT
+INTO user_roles(user_id, role_id)
+SELECT @user_id, unnest(@role_ids::bigint[])
+ON CONFLICT DO NOTHING;
+
+-- name: UserPermissions :many
+SELECT DISTINCT rp.permission
+FROM role_permissions rp
+         JOIN user_roles ur ON ur.role_id = rp.role_id
+WHERE ur.user_id = @user_id
+ORDER BY rp.permission;
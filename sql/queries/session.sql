@@ -0,0 +1,68 @@
+-- name: CreateSession :one
+INSERT INTO sessions(user_id, device_id, device_label, user_agent, ip_address, expires_at)
+VALUES (@user_id, @device_id, @device_label, @user_agent, @ip_address, @expires_at)
+RETURNING *;
+
+-- name: TouchSession :exec
+UPDATE sessions
+SET last_used_at = NOW(),
+    user_agent   = @user_agent,
+    ip_address   = @ip_address,
+    expires_at   = @expires_at
+WHERE id = @id;
+
+-- name: RevokeSession :exec
+UPDATE sessions
+SET revoked_at = NOW()
+WHERE id = @id
+  AND revoked_at IS NULL;
+
+-- name: RevokeDeviceSessions :exec
+UPDATE sessions
+SET revoked_at = NOW()
+WHERE user_id = @user_id
+  AND device_id = @device_id
+  AND revoked_at IS NULL;
+
+-- name: RevokeUserSessions :exec
+UPDATE sessions
+SET revoked_at = NOW()
+WHERE user_id = @user_id
+  AND revoked_at IS NULL;
+
+-- name: ActiveUserSessions :many
+SELECT *
+FROM sessions
+WHERE user_id = @user_id
+  AND revoked_at IS NULL
+  AND expires_at > NOW()
+ORDER BY last_used_at DESC;
+
+-- name: AllUserSessions :many
+SELECT *
+FROM sessions
+WHERE user_id = @user_id
+ORDER BY created_at DESC;
+
+-- name: RevokeUserSession :execrows
+UPDATE sessions
+SET revoked_at = NOW()
+WHERE id = @id
+  AND user_id = @user_id
+  AND revoked_at IS NULL;
+
+-- name: CreateRefreshToken :exec
+INSERT INTO refresh_tokens(token_hash, session_id)
+VALUES (@token_hash, @session_id);
+
+-- name: GetRefreshToken :one
+SELECT rt.session_id, rt.used_at, s.user_id, s.expires_at, s.revoked_at
+FROM refresh_tokens rt
+         JOIN sessions s ON s.id = rt.session_id
+WHERE rt.token_hash = @token_hash;
+
+-- name: UseRefreshToken :execrows
+UPDATE refresh_tokens
+SET used_at = NOW()
+WHERE token_hash = @token_hash
+  AND used_at IS NULL;
@@ -0,0 +1,38 @@
+-- name: LockedLoginThrottles :many
+SELECT *
+FROM login_throttles
+WHERE key = ANY (@keys::varchar[])
+  AND locked_until > NOW();
+
+-- name: RecordLoginFailure :one
+INSERT INTO login_throttles(key, failures, last_failure_at)
+VALUES (@key, 1, NOW())
+ON CONFLICT (key) DO UPDATE
+    SET failures        = CASE
+                              WHEN login_throttles.last_failure_at < NOW() - INTERVAL '1 hour' THEN 1
+                              ELSE login_throttles.failures + 1
+        END,
+        last_failure_at = NOW()
+RETURNING failures;
+
+-- name: LockLogin :exec
+UPDATE login_throttles
+SET locked_until = @locked_until
+WHERE key = @key;
+
+-- name: ClearLoginFailures :execrows
+DELETE
+FROM login_throttles
+WHERE key = @key;
+
+-- name: DeleteStaleLoginThrottles :exec
+DELETE
+FROM login_throttles
+WHERE last_failure_at < NOW() - INTERVAL '1 day'
+  AND (locked_until IS NULL OR locked_until < NOW());
+
+-- name: AllLockedLogins :many
+SELECT *
+FROM login_throttles
+WHERE locked_until > NOW()
+ORDER BY locked_until DESC;
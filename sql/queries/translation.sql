@@ -0,0 +1,47 @@
+-- name: EntityTranslations :many
+SELECT *
+FROM translations
+WHERE entity = @entity
+  AND field = @field
+  AND entity_id = ANY (@entity_ids::bigint[])
+ORDER BY entity_id, locale;
+
+-- name: FilterTranslations :many
+SELECT *
+FROM translations
+WHERE (sqlc.narg(entity)::varchar IS NULL OR entity = sqlc.narg(entity))
+  AND (sqlc.narg(locale)::varchar IS NULL OR locale = sqlc.narg(locale))
+ORDER BY entity, entity_id, field, locale;
+
+-- name: UpsertTranslations :exec
+-- rows are given as parallel arrays, so a bulk import is written by single statement
+INSERT INTO translations (entity, entity_id, field, locale, value)
+SELECT unnest(@entities::varchar[]),
+       unnest(@entity_ids::bigint[]),
+       unnest(@fields::varchar[]),
+       unnest(@locales::varchar[]),
+       unnest(@vals::text[])
+ON CONFLICT (entity, entity_id, field, locale) DO UPDATE
+    SET value      = excluded.value,
+        updated_at = NOW();
+
+-- name: DeleteOtherTranslations :exec
+-- remove the locales of the field missing from the given locales
+DELETE
+FROM translations
+WHERE entity = @entity
+  AND entity_id = @entity_id
+  AND field = @field
+  AND NOT (locale = ANY (@locales::varchar[]));
+
+-- name: ExistingEntityIds :many
+-- translations have no foreign keys, the ids are checked against the tables of translated entities
+SELECT id
+FROM cities
+WHERE @entity::varchar = 'city'
+  AND id = ANY (@ids::bigint[])
+UNION ALL
+SELECT id
+FROM governorates
+WHERE @entity::varchar = 'governorate'
+  AND id = ANY (@ids::bigint[]);
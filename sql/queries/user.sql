@@ -2,23 +2,44 @@
 SELECT *
 FROM users
 WHERE is_staff = TRUE
-ORDER BY join_date DESC
+ORDER BY join_date DESC, id DESC
 LIMIT $1 OFFSET $2;
 
+-- name: StaffAfter :many
+-- keyset page of staff following the row with the given join date and id
+SELECT *
+FROM users
+WHERE is_staff = TRUE
+  AND (join_date, id) < (@after_join_date::timestamptz, @after_id::uuid)
+ORDER BY join_date DESC, id DESC
+LIMIT @page_size;
+
+-- name: StaffBefore :many
+-- keyset page of staff preceding the row with the given join date and id, in reverse order
+SELECT *
+FROM users
+WHERE is_staff = TRUE
+  AND (join_date, id) > (@before_join_date::timestamptz, @before_id::uuid)
+ORDER BY join_date, id
+LIMIT @page_size;
+
 -- name: AllStaffCount :one
 SELECT COUNT(*)
 FROM users
 WHERE is_staff = TRUE;
 
 -- name: CreateUser :one
-INSERT INTO users(name, email, phone_number, avatar, is_staff, join_date, last_login)
-VALUES (@name, @email, @phone_number, @avatar, @is_staff, NOW(), NOW())
+INSERT INTO users(name, email, phone_number, avatar, is_staff, status, join_date, last_login)
+VALUES (@name, @email, @phone_number, @avatar, @is_staff, @status, NOW(), NOW())
 RETURNING *;
 
 -- name: GetUserByEmail :one
+-- emails are compared case-insensitively, accounts created before they were stored lowercase included
 Select *
 FROM users
-WHERE email = @email;
+WHERE lower(email) = lower(@email)
+ORDER BY join_date
+LIMIT 1;
 
 -- name: GetUSerById :one
 SELECT *
@@ -27,9 +48,169 @@ WHERE id = @id;
 
 -- name: UpdateUser :one
 UPDATE users
-SET name         = $2,
-    email        = $3,
-    phone_number = $4,
-    status       = $5
+SET name              = $2,
+    email             = $3,
+    phone_number      = $4,
+    phone_verified_at = CASE WHEN phone_number IS DISTINCT FROM $4 THEN NULL ELSE phone_verified_at END
+WHERE id = $1
+RETURNING *;
+
+-- name: UpdateUserProfile :one
+UPDATE users
+SET name              = $2,
+    phone_number      = $3,
+    phone_verified_at = CASE WHEN phone_number IS DISTINCT FROM $3 THEN NULL ELSE phone_verified_at END
+WHERE id = $1
+RETURNING *;
+
+-- name: GetCustomerById :one
+SELECT *
+FROM users
+WHERE id = @id
+  AND is_staff = FALSE;
+
+-- name: FilterCustomers :many
+SELECT *
+FROM users
+WHERE is_staff = FALSE
+  AND (sqlc.narg(status)::account_status IS NULL OR status = sqlc.narg(status))
+  AND (sqlc.narg(joined_from)::timestamptz IS NULL OR join_date >= sqlc.narg(joined_from))
+  AND (sqlc.narg(joined_to)::timestamptz IS NULL OR join_date < sqlc.narg(joined_to))
+  AND (sqlc.narg(query)::text IS NULL
+    OR name ILIKE sqlc.narg(query)
+    OR email ILIKE sqlc.narg(query)
+    OR phone_number ILIKE sqlc.narg(query))
+ORDER BY join_date DESC, id DESC
+LIMIT $1 OFFSET $2;
+
+-- name: FilterCustomersAfter :many
+-- keyset page of customers following the row with the given join date and id
+SELECT *
+FROM users
+WHERE is_staff = FALSE
+  AND (sqlc.narg(status)::account_status IS NULL OR status = sqlc.narg(status))
+  AND (sqlc.narg(joined_from)::timestamptz IS NULL OR join_date >= sqlc.narg(joined_from))
+  AND (sqlc.narg(joined_to)::timestamptz IS NULL OR join_date < sqlc.narg(joined_to))
+  AND (sqlc.narg(query)::text IS NULL
+    OR name ILIKE sqlc.narg(query)
+    OR email ILIKE sqlc.narg(query)
+    OR phone_number ILIKE sqlc.narg(query))
+  AND (join_date, id) < (@after_join_date::timestamptz, @after_id::uuid)
+ORDER BY join_date DESC, id DESC
+LIMIT @page_size;
+
+-- name: FilterCustomersBefore :many
+-- keyset page of customers preceding the row with the given join date and id, in reverse order
+SELECT *
+FROM users
+WHERE is_staff = FALSE
+  AND (sqlc.narg(status)::account_status IS NULL OR status = sqlc.narg(status))
+  AND (sqlc.narg(joined_from)::timestamptz IS NULL OR join_date >= sqlc.narg(joined_from))
+  AND (sqlc.narg(joined_to)::timestamptz IS NULL OR join_date < sqlc.narg(joined_to))
+  AND (sqlc.narg(query)::text IS NULL
+    OR name ILIKE sqlc.narg(query)
+    OR email ILIKE sqlc.narg(query)
+    OR phone_number ILIKE sqlc.narg(query))
+  AND (join_date, id) > (@before_join_date::timestamptz, @before_id::uuid)
+ORDER BY join_date, id
+LIMIT @page_size;
+
+-- name: FilterCustomersCount :one
+SELECT COUNT(*)
+FROM users
+WHERE is_staff = FALSE
+  AND (sqlc.narg(status)::account_status IS NULL OR status = sqlc.narg(status))
+  AND (sqlc.narg(joined_from)::timestamptz IS NULL OR join_date >= sqlc.narg(joined_from))
+  AND (sqlc.narg(joined_to)::timestamptz IS NULL OR join_date < sqlc.narg(joined_to))
+  AND (sqlc.narg(query)::text IS NULL
+    OR name ILIKE sqlc.narg(query)
+    OR email ILIKE sqlc.narg(query)
+    OR phone_number ILIKE sqlc.narg(query));
+
+-- name: UpdateCustomer :one
+UPDATE users
+SET name              = $2,
+    phone_number      = $3,
+    phone_verified_at = CASE WHEN phone_number IS DISTINCT FROM $3 THEN NULL ELSE phone_verified_at END
 WHERE id = $1
+  AND is_staff = FALSE
+RETURNING *;
+
+-- name: SetUserPhoneVerified :one
+UPDATE users
+SET phone_number      = @phone_number,
+    phone_verified_at = NOW()
+WHERE id = @id
+RETURNING *;
+
+-- name: RecordUserLogin :one
+UPDATE users
+SET last_login            = NOW(),
+    name                  = COALESCE(sqlc.narg('name'), name),
+    -- uploaded avatar takes precedence over the identity provider picture
+    avatar                = CASE WHEN avatar_key IS NULL THEN COALESCE(sqlc.narg('avatar'), avatar) ELSE avatar END,
+    -- login within the grace period cancel the requested deletion
+    deletion_scheduled_at = NULL
+WHERE id = @id
 RETURNING *;
+
+-- name: SetUserAvatar :one
+UPDATE users
+SET avatar     = @avatar,
+    avatar_key = @avatar_key
+WHERE id = @id
+RETURNING *;
+
+-- name: SetUserPreferredLanguage :one
+UPDATE users
+SET preferred_language = @preferred_language
+WHERE id = @id
+RETURNING *;
+
+-- name: LockUser :exec
+-- serialize the writes limited per user, held until the transaction ends
+SELECT id
+FROM users
+WHERE id = @id
+    FOR UPDATE;
+
+-- name: ScheduleUserDeletion :one
+UPDATE users
+SET deletion_scheduled_at = @deletion_scheduled_at
+WHERE id = @id
+RETURNING *;
+
+-- name: AnonymizeDueUsers :many
+WITH anonymized AS (
+    UPDATE users
+        SET name = 'Deleted user',
+            email = 'deleted+' || users.id || '@texorbit.invalid',
+            phone_number = NULL,
+            phone_verified_at = NULL,
+            avatar = NULL,
+            avatar_key = NULL,
+            status = 'deleted',
+            status_reason = 'deleted by account owner',
+            deletion_scheduled_at = NULL,
+            suspended_until = NULL
+        FROM users previous
+        WHERE previous.id = users.id
+          AND users.deletion_scheduled_at <= NOW()
+        RETURNING users.id, previous.status, previous.email, previous.avatar_key),
+     status_history AS (
+         INSERT INTO user_status_history (user_id, from_status, to_status, reason)
+             SELECT id, status, 'deleted', 'deleted by account owner'
+             FROM anonymized
+             WHERE status <> 'deleted'),
+     deleted_sessions AS (
+         DELETE FROM sessions WHERE user_id IN (SELECT id FROM anonymized)),
+     deleted_otps AS (
+         DELETE FROM phone_otps WHERE user_id IN (SELECT id FROM anonymized)),
+     deleted_login_events AS (
+         DELETE FROM login_events WHERE user_id IN (SELECT id FROM anonymized)),
+     deleted_addresses AS (
+         DELETE FROM user_addresses WHERE user_id IN (SELECT id FROM anonymized)),
+     deleted_throttles AS (
+         DELETE FROM login_throttles WHERE key IN (SELECT 'account:' || LOWER(email) FROM anonymized))
+SELECT id, avatar_key
+FROM anonymized;
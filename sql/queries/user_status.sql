@@ -0,0 +1,49 @@
+-- name: ChangeUserStatus :one
+WITH changed AS (
+    UPDATE users
+        SET status = @to_status,
+            status_reason = @reason,
+            suspended_until = @suspended_until
+        WHERE id = @id
+          AND status = @from_status
+        RETURNING *),
+     status_history AS (
+         INSERT INTO user_status_history (user_id, from_status, to_status, reason, changed_by)
+             SELECT id, @from_status, status, @reason, sqlc.narg(changed_by)::uuid
+             FROM changed)
+SELECT *
+FROM changed;
+
+-- name: UserStatusChanges :many
+SELECT *
+FROM user_status_history
+WHERE user_id = $1
+ORDER BY created_at DESC
+LIMIT $2 OFFSET $3;
+
+-- name: AllUserStatusChanges :many
+SELECT *
+FROM user_status_history
+WHERE user_id = @user_id
+ORDER BY created_at DESC;
+
+-- name: UserStatusChangesCount :one
+SELECT COUNT(*)
+FROM user_status_history
+WHERE user_id = @user_id;
+
+-- name: UnsuspendDueUsers :many
+WITH unsuspended AS (
+    UPDATE users
+        SET status = 'active',
+            status_reason = 'suspension ended',
+            suspended_until = NULL
+        WHERE status = 'suspended'
+          AND suspended_until <= NOW()
+        RETURNING id),
+     status_history AS (
+         INSERT INTO user_status_history (user_id, from_status, to_status, reason)
+             SELECT id, 'suspended', 'active', 'suspension ended'
+             FROM unsuspended)
+SELECT id
+FROM unsuspended;
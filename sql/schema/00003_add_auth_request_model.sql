@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE "auth_requests" (
+  "state" varchar(64) PRIMARY KEY,
+  "provider" varchar(32) NOT NULL,
+  "nonce" varchar(64) NOT NULL,
+  "code_verifier" varchar(128) NOT NULL,
+  "expires_at" timestamptz NOT NULL
+);
+
+CREATE INDEX ON "auth_requests" ("expires_at");
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS auth_requests;
+-- +goose StatementEnd
@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE "sessions" (
+  "id" uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+  "user_id" uuid NOT NULL REFERENCES "users" ("id") ON DELETE CASCADE,
+  "device_id" varchar(64) NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT NOW(),
+  "last_used_at" timestamptz NOT NULL DEFAULT NOW(),
+  "expires_at" timestamptz NOT NULL,
+  "revoked_at" timestamptz
+);
+
+CREATE INDEX ON "sessions" ("user_id", "device_id");
+
+CREATE TABLE "refresh_tokens" (
+  "token_hash" varchar(64) PRIMARY KEY,
+  "session_id" uuid NOT NULL REFERENCES "sessions" ("id") ON DELETE CASCADE,
+  "created_at" timestamptz NOT NULL DEFAULT NOW(),
+  "used_at" timestamptz
+);
+
+CREATE INDEX ON "refresh_tokens" ("session_id");
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS refresh_tokens;
+DROP TABLE IF EXISTS sessions;
+-- +goose StatementEnd
@@ -0,0 +1,60 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE "permissions" (
+  "code" varchar(50) PRIMARY KEY,
+  "description" varchar(255) NOT NULL
+);
+
+CREATE TABLE "roles" (
+  "id" BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  "name" varchar(50) UNIQUE NOT NULL,
+  "description" varchar(255) NOT NULL DEFAULT ''
+);
+
+CREATE TABLE "role_permissions" (
+  "role_id" bigint NOT NULL REFERENCES "roles" ("id") ON DELETE CASCADE,
+  "permission" varchar(50) NOT NULL REFERENCES "permissions" ("code") ON DELETE CASCADE,
+  PRIMARY KEY ("role_id", "permission")
+);
+
+CREATE TABLE "user_roles" (
+  "user_id" uuid NOT NULL REFERENCES "users" ("id") ON DELETE CASCADE,
+  "role_id" bigint NOT NULL REFERENCES "roles" ("id") ON DELETE CASCADE,
+  PRIMARY KEY ("user_id", "role_id")
+);
+
+INSERT INTO permissions (code, description)
+VALUES ('city:read', 'List all cities including inactive ones'),
+       ('city:write', 'Create and update cities'),
+       ('city:delete', 'Delete cities'),
+       ('customer:read', 'List and view customers'),
+       ('customer:write', 'Update customers data and status'),
+       ('staff:read', 'List staff members'),
+       ('staff:manage', 'Create and update staff members'),
+       ('role:manage', 'Manage roles and assign them to staff members');
+
+INSERT INTO roles (name, description)
+VALUES ('admin', 'Full access to all staff features');
+
+INSERT INTO role_permissions (role_id, permission)
+SELECT r.id, p.code
+FROM roles r
+         CROSS JOIN permissions p
+WHERE r.name = 'admin';
+
+-- existing staff members keep their full access
+INSERT INTO user_roles (user_id, role_id)
+SELECT u.id, r.id
+FROM users u
+         CROSS JOIN roles r
+WHERE u.is_staff = TRUE
+  AND r.name = 'admin';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS user_roles;
+DROP TABLE IF EXISTS role_permissions;
+DROP TABLE IF EXISTS roles;
+DROP TABLE IF EXISTS permissions;
+-- +goose StatementEnd
@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE "users"
+    ADD COLUMN "status_reason" varchar(255);
+
+CREATE INDEX ON "users" ("join_date");
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS users_join_date_idx;
+ALTER TABLE "users"
+    DROP COLUMN IF EXISTS "status_reason";
+-- +goose StatementEnd
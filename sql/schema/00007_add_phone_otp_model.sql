@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE "users"
+    ADD COLUMN "phone_verified_at" timestamptz;
+
+CREATE TABLE "phone_otps" (
+  "id" BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  "user_id" uuid NOT NULL REFERENCES "users" ("id") ON DELETE CASCADE,
+  "phone_number" varchar(15) NOT NULL,
+  "code_hash" varchar(64) NOT NULL,
+  "attempts" int NOT NULL DEFAULT 0,
+  "created_at" timestamptz NOT NULL DEFAULT NOW(),
+  "expires_at" timestamptz NOT NULL,
+  "consumed_at" timestamptz
+);
+
+CREATE INDEX ON "phone_otps" ("user_id", "created_at");
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS phone_otps;
+ALTER TABLE "users"
+    DROP COLUMN IF EXISTS "phone_verified_at";
+-- +goose StatementEnd
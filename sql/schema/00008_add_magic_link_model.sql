@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE "magic_links" (
+  "token_hash" varchar(64) PRIMARY KEY,
+  "email" varchar(255) NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT NOW(),
+  "expires_at" timestamptz NOT NULL,
+  "consumed_at" timestamptz
+);
+
+CREATE INDEX ON "magic_links" ("email", "created_at");
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS magic_links;
+-- +goose StatementEnd
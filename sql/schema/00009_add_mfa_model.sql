@@ -0,0 +1,45 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE "user_mfa" (
+  "user_id" uuid PRIMARY KEY REFERENCES "users" ("id") ON DELETE CASCADE,
+  "secret" varchar(64) NOT NULL,
+  "enabled_at" timestamptz,
+  "last_counter" bigint NOT NULL DEFAULT 0,
+  "failed_attempts" int NOT NULL DEFAULT 0,
+  "created_at" timestamptz NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE "mfa_recovery_codes" (
+  "id" BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  "user_id" uuid NOT NULL REFERENCES "users" ("id") ON DELETE CASCADE,
+  "code_hash" varchar(64) NOT NULL,
+  "used_at" timestamptz
+);
+
+CREATE INDEX ON "mfa_recovery_codes" ("user_id");
+
+CREATE TABLE "security_policies" (
+  "key" varchar(50) PRIMARY KEY,
+  "enabled" boolean NOT NULL DEFAULT FALSE,
+  "updated_at" timestamptz NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO security_policies (key, enabled)
+VALUES ('staff_mfa_required', FALSE);
+
+INSERT INTO permissions (code, description)
+VALUES ('security:manage', 'Manage security policies');
+
+INSERT INTO role_permissions (role_id, permission)
+SELECT r.id, 'security:manage'
+FROM roles r
+WHERE r.name = 'admin';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM permissions WHERE code = 'security:manage';
+DROP TABLE IF EXISTS security_policies;
+DROP TABLE IF EXISTS mfa_recovery_codes;
+DROP TABLE IF EXISTS user_mfa;
+-- +goose StatementEnd
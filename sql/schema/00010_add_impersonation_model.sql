@@ -0,0 +1,39 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE "impersonations" (
+  "id" uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+  "staff_id" uuid NOT NULL REFERENCES "users" ("id") ON DELETE CASCADE,
+  "user_id" uuid NOT NULL REFERENCES "users" ("id") ON DELETE CASCADE,
+  "reason" varchar(255) NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT NOW(),
+  "expires_at" timestamptz NOT NULL
+);
+
+CREATE INDEX ON "impersonations" ("created_at");
+
+CREATE TABLE "impersonation_requests" (
+  "id" BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  "impersonation_id" uuid NOT NULL REFERENCES "impersonations" ("id") ON DELETE CASCADE,
+  "method" varchar(10) NOT NULL,
+  "path" varchar(255) NOT NULL,
+  "status" int NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX ON "impersonation_requests" ("impersonation_id");
+
+INSERT INTO permissions (code, description)
+VALUES ('customer:impersonate', 'Act as a customer for support');
+
+INSERT INTO role_permissions (role_id, permission)
+SELECT r.id, 'customer:impersonate'
+FROM roles r
+WHERE r.name = 'admin';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM permissions WHERE code = 'customer:impersonate';
+DROP TABLE IF EXISTS impersonation_requests;
+DROP TABLE IF EXISTS impersonations;
+-- +goose StatementEnd
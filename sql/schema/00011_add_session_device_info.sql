@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE "sessions"
+    ADD COLUMN "device_label" varchar(100) NOT NULL DEFAULT '',
+    ADD COLUMN "user_agent" varchar(255) NOT NULL DEFAULT '',
+    ADD COLUMN "ip_address" varchar(45) NOT NULL DEFAULT '';
+
+CREATE INDEX ON "sessions" ("user_id");
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS sessions_user_id_idx;
+ALTER TABLE "sessions"
+    DROP COLUMN IF EXISTS "device_label",
+    DROP COLUMN IF EXISTS "user_agent",
+    DROP COLUMN IF EXISTS "ip_address";
+-- +goose StatementEnd
@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE "api_keys" (
+  "id" uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+  "name" varchar(100) NOT NULL,
+  "prefix" varchar(16) UNIQUE NOT NULL,
+  "secret_hash" varchar(64) NOT NULL,
+  "scopes" varchar(50)[] NOT NULL DEFAULT '{}',
+  "created_by" uuid REFERENCES "users" ("id") ON DELETE SET NULL,
+  "created_at" timestamptz NOT NULL DEFAULT NOW(),
+  "expires_at" timestamptz,
+  "last_used_at" timestamptz,
+  "revoked_at" timestamptz
+);
+
+INSERT INTO permissions (code, description)
+VALUES ('apikey:manage', 'Issue and revoke API keys of internal services');
+
+INSERT INTO role_permissions (role_id, permission)
+SELECT r.id, 'apikey:manage'
+FROM roles r
+WHERE r.name = 'admin';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM permissions WHERE code = 'apikey:manage';
+DROP TABLE IF EXISTS api_keys;
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE "login_events" (
+  "id" BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  "user_id" uuid REFERENCES "users" ("id") ON DELETE CASCADE,
+  "email" varchar(255) NOT NULL,
+  "method" varchar(50) NOT NULL,
+  "success" boolean NOT NULL,
+  "failure_reason" varchar(50) NOT NULL DEFAULT '',
+  "ip_address" varchar(45) NOT NULL DEFAULT '',
+  "user_agent" varchar(255) NOT NULL DEFAULT '',
+  "created_at" timestamptz NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX ON "login_events" ("user_id", "created_at");
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS login_events;
+-- +goose StatementEnd
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE "users"
+    ADD COLUMN "deletion_scheduled_at" timestamptz;
+
+CREATE INDEX ON "users" ("deletion_scheduled_at") WHERE "deletion_scheduled_at" IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE "users"
+    DROP COLUMN IF EXISTS "deletion_scheduled_at";
+-- +goose StatementEnd
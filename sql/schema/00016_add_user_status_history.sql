@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TYPE "account_status" ADD VALUE IF NOT EXISTS 'pending_verification';
+
+ALTER TABLE "users"
+    ADD COLUMN "suspended_until" timestamptz;
+
+CREATE INDEX ON "users" ("suspended_until") WHERE "suspended_until" IS NOT NULL;
+
+CREATE TABLE "user_status_history" (
+  "id" BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  "user_id" uuid NOT NULL REFERENCES "users" ("id") ON DELETE CASCADE,
+  "from_status" account_status NOT NULL,
+  "to_status" account_status NOT NULL,
+  "reason" varchar(255) NOT NULL,
+  "changed_by" uuid REFERENCES "users" ("id") ON DELETE SET NULL,
+  "created_at" timestamptz NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX ON "user_status_history" ("user_id", "created_at");
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS user_status_history;
+ALTER TABLE "users"
+    DROP COLUMN IF EXISTS "suspended_until";
+-- enum values can not be dropped, pending accounts fall back to active
+UPDATE users SET status = 'active' WHERE status = 'pending_verification';
+-- +goose StatementEnd
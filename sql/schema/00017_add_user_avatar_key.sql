@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE "users"
+    ADD COLUMN "avatar_key" varchar(255);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE "users"
+    DROP COLUMN IF EXISTS "avatar_key";
+-- +goose StatementEnd
@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE "user_addresses" (
+  "id" BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  "user_id" uuid NOT NULL REFERENCES "users" ("id") ON DELETE CASCADE,
+  "city_id" BIGINT NOT NULL REFERENCES "cities" ("id") ON DELETE RESTRICT,
+  "label" varchar(50) NOT NULL,
+  "street" varchar(255) NOT NULL,
+  "building" varchar(50) NOT NULL DEFAULT '',
+  "floor" varchar(20) NOT NULL DEFAULT '',
+  "landmark" varchar(255) NOT NULL DEFAULT '',
+  "latitude" double precision,
+  "longitude" double precision,
+  "is_default" boolean NOT NULL DEFAULT false,
+  "created_at" timestamptz NOT NULL DEFAULT NOW(),
+  "updated_at" timestamptz NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX ON "user_addresses" ("user_id");
+CREATE INDEX ON "user_addresses" ("city_id");
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS user_addresses;
+-- +goose StatementEnd
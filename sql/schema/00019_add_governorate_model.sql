@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE "governorates" (
+  "id" BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  "name_en" varchar(75) NOT NULL,
+  "name_ar" varchar(75) NOT NULL
+);
+
+-- existing cities stay without governorate until assigned
+ALTER TABLE "cities"
+    ADD COLUMN "governorate_id" BIGINT REFERENCES "governorates" ("id") ON DELETE RESTRICT;
+
+CREATE INDEX ON "cities" ("governorate_id");
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE "cities"
+    DROP COLUMN IF EXISTS "governorate_id";
+DROP TABLE IF EXISTS governorates;
+-- +goose StatementEnd
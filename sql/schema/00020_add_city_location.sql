@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE "cities"
+    ADD COLUMN "latitude"  double precision,
+    ADD COLUMN "longitude" double precision,
+    -- GeoJSON Polygon or MultiPolygon geometry of the city area
+    ADD COLUMN "boundary"  jsonb;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE "cities"
+    DROP COLUMN IF EXISTS "latitude",
+    DROP COLUMN IF EXISTS "longitude",
+    DROP COLUMN IF EXISTS "boundary";
+-- +goose StatementEnd
@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE "delivery_zones" (
+  "id" BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  "city_id" BIGINT NOT NULL REFERENCES "cities" ("id") ON DELETE CASCADE,
+  "name" varchar(75) NOT NULL,
+  -- GeoJSON Polygon or MultiPolygon geometry of the covered area
+  "area" jsonb NOT NULL,
+  -- amounts in piasters
+  "fee" integer NOT NULL DEFAULT 0,
+  "min_order" integer NOT NULL DEFAULT 0,
+  "eta_minutes" integer NOT NULL,
+  "is_active" boolean NOT NULL DEFAULT true,
+  "created_at" timestamptz NOT NULL DEFAULT NOW(),
+  "updated_at" timestamptz NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX ON "delivery_zones" ("city_id");
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS delivery_zones;
+-- +goose StatementEnd
@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE "users"
+    -- language of responses, emails and SMS, Accept-Language is used when not set
+    ADD COLUMN "preferred_language" varchar(5) CHECK ("preferred_language" IN ('en', 'ar'));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE "users"
+    DROP COLUMN IF EXISTS "preferred_language";
+-- +goose StatementEnd
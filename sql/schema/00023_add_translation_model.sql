@@ -0,0 +1,83 @@
+-- +goose Up
+-- +goose StatementBegin
+-- translated text fields of any entity (ex: city name), rows are removed with their entity by the delete queries
+CREATE TABLE "translations" (
+  "entity" varchar(50) NOT NULL,
+  "entity_id" bigint NOT NULL,
+  "field" varchar(50) NOT NULL,
+  -- BCP 47 language tag (ex: en, ar, fr)
+  "locale" varchar(35) NOT NULL,
+  "value" text NOT NULL,
+  "updated_at" timestamptz NOT NULL DEFAULT NOW(),
+  PRIMARY KEY ("entity", "entity_id", "field", "locale")
+);
+
+CREATE INDEX ON "translations" ("locale");
+
+INSERT INTO translations (entity, entity_id, field, locale, value)
+SELECT 'city', id, 'name', 'en', name_en
+FROM cities
+UNION ALL
+SELECT 'city', id, 'name', 'ar', name_ar
+FROM cities
+UNION ALL
+SELECT 'governorate', id, 'name', 'en', name_en
+FROM governorates
+UNION ALL
+SELECT 'governorate', id, 'name', 'ar', name_ar
+FROM governorates;
+
+ALTER TABLE "cities"
+    DROP COLUMN "name_en",
+    DROP COLUMN "name_ar";
+
+ALTER TABLE "governorates"
+    DROP COLUMN "name_en",
+    DROP COLUMN "name_ar";
+
+INSERT INTO permissions (code, description)
+VALUES ('translation:manage', 'Import and export translations');
+
+INSERT INTO role_permissions (role_id, permission)
+SELECT r.id, 'translation:manage'
+FROM roles r
+WHERE r.name = 'admin';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM permissions WHERE code = 'translation:manage';
+
+-- names without english or arabic translation fall back to each other
+ALTER TABLE "cities"
+    ADD COLUMN "name_en" varchar(75),
+    ADD COLUMN "name_ar" varchar(75);
+
+UPDATE cities
+SET name_en = (SELECT value FROM translations WHERE entity = 'city' AND entity_id = cities.id AND field = 'name' AND locale = 'en'),
+    name_ar = (SELECT value FROM translations WHERE entity = 'city' AND entity_id = cities.id AND field = 'name' AND locale = 'ar');
+UPDATE cities
+SET name_en = COALESCE(name_en, name_ar, ''),
+    name_ar = COALESCE(name_ar, name_en, '');
+
+ALTER TABLE "cities"
+    ALTER COLUMN "name_en" SET NOT NULL,
+    ALTER COLUMN "name_ar" SET NOT NULL;
+
+ALTER TABLE "governorates"
+    ADD COLUMN "name_en" varchar(75),
+    ADD COLUMN "name_ar" varchar(75);
+
+UPDATE governorates
+SET name_en = (SELECT value FROM translations WHERE entity = 'governorate' AND entity_id = governorates.id AND field = 'name' AND locale = 'en'),
+    name_ar = (SELECT value FROM translations WHERE entity = 'governorate' AND entity_id = governorates.id AND field = 'name' AND locale = 'ar');
+UPDATE governorates
+SET name_en = COALESCE(name_en, name_ar, ''),
+    name_ar = COALESCE(name_ar, name_en, '');
+
+ALTER TABLE "governorates"
+    ALTER COLUMN "name_en" SET NOT NULL,
+    ALTER COLUMN "name_ar" SET NOT NULL;
+
+DROP TABLE IF EXISTS translations;
+-- +goose StatementEnd
@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- keyset pages of staff and customers are ordered by join date then id
+DROP INDEX IF EXISTS users_join_date_idx;
+CREATE INDEX ON "users" ("join_date", "id");
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS users_join_date_id_idx;
+CREATE INDEX ON "users" ("join_date");
+-- +goose StatementEnd
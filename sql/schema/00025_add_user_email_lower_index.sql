@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- users are found by email case-insensitively, new emails are stored lowercase
+CREATE INDEX ON "users" (lower("email"));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS users_lower_idx;
+-- +goose StatementEnd
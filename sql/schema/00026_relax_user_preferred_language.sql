@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+-- supported locales are configured with LOCALES environment variable, so any BCP 47 tag is accepted
+ALTER TABLE "users"
+    DROP CONSTRAINT IF EXISTS "users_preferred_language_check",
+    ALTER COLUMN "preferred_language" TYPE varchar(35);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+UPDATE users
+SET preferred_language = NULL
+WHERE preferred_language NOT IN ('en', 'ar');
+
+ALTER TABLE "users"
+    ALTER COLUMN "preferred_language" TYPE varchar(5),
+    ADD CONSTRAINT "users_preferred_language_check" CHECK ("preferred_language" IN ('en', 'ar'));
+-- +goose StatementEnd
@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- time of the last counted verify attempt, failed attempts are forgotten after the mfa lockout
+ALTER TABLE "user_mfa"
+    ADD COLUMN "failed_at" timestamptz;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE "user_mfa"
+    DROP COLUMN IF EXISTS "failed_at";
+-- +goose StatementEnd
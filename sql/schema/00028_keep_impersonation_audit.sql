@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+-- deleting users must not erase the impersonation audit trail, accounts are anonymized instead of deleted
+ALTER TABLE "impersonations"
+    DROP CONSTRAINT IF EXISTS "impersonations_staff_id_fkey",
+    DROP CONSTRAINT IF EXISTS "impersonations_user_id_fkey",
+    ADD CONSTRAINT "impersonations_staff_id_fkey" FOREIGN KEY ("staff_id") REFERENCES "users" ("id") ON DELETE RESTRICT,
+    ADD CONSTRAINT "impersonations_user_id_fkey" FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE RESTRICT;
+
+-- long URLs must not fail the audit insert
+ALTER TABLE "impersonation_requests"
+    ALTER COLUMN "path" TYPE text;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+UPDATE impersonation_requests
+SET path = LEFT(path, 255)
+WHERE LENGTH(path) > 255;
+
+ALTER TABLE "impersonation_requests"
+    ALTER COLUMN "path" TYPE varchar(255);
+
+ALTER TABLE "impersonations"
+    DROP CONSTRAINT IF EXISTS "impersonations_staff_id_fkey",
+    DROP CONSTRAINT IF EXISTS "impersonations_user_id_fkey",
+    ADD CONSTRAINT "impersonations_staff_id_fkey" FOREIGN KEY ("staff_id") REFERENCES "users" ("id") ON DELETE CASCADE,
+    ADD CONSTRAINT "impersonations_user_id_fkey" FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE CASCADE;
+-- +goose StatementEnd